@@ -0,0 +1,149 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField describes the allowed values of a field of a cron schedule
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 6, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+var cronDescriptors = map[string]struct{}{
+	"@yearly":   {},
+	"@annually": {},
+	"@monthly":  {},
+	"@weekly":   {},
+	"@daily":    {},
+	"@midnight": {},
+	"@hourly":   {},
+}
+
+// validateCronSchedule checks that the schedule has the standard cron format
+// accepted by DataImportCrons: five fields, a descriptor like @daily, or @every <duration>.
+func validateCronSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	// The time zone of the schedule is checked by CDI
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		i := strings.Index(schedule, " ")
+		if i < 0 {
+			return fmt.Errorf("missing schedule after time zone")
+		}
+		schedule = strings.TrimSpace(schedule[i:])
+	}
+	if schedule == "" {
+		return fmt.Errorf("schedule is empty")
+	}
+
+	if strings.HasPrefix(schedule, "@") {
+		if strings.HasPrefix(schedule, "@every ") {
+			duration, err := time.ParseDuration(strings.TrimPrefix(schedule, "@every "))
+			if err != nil {
+				return fmt.Errorf("invalid duration: %v", err)
+			}
+			if duration <= 0 {
+				return fmt.Errorf("duration must be positive: %s", duration)
+			}
+			return nil
+		}
+		if _, ok := cronDescriptors[schedule]; !ok {
+			return fmt.Errorf("unknown descriptor: %s", schedule)
+		}
+		return nil
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected %d fields, found %d: %s", len(cronFields), len(fields), schedule)
+	}
+	for i, field := range fields {
+		err := cronFields[i].validate(field)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", cronFields[i].name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks a comma-separated list of values, ranges and steps, e.g. "1,5-10,*/15"
+func (f *cronField) validate(value string) error {
+	for _, term := range strings.Split(value, ",") {
+		rangePart := term
+		if i := strings.Index(term, "/"); i >= 0 {
+			rangePart = term[:i]
+			step, err := strconv.Atoi(term[i+1:])
+			if err != nil || step <= 0 {
+				return fmt.Errorf("step must be a positive number: %s", term)
+			}
+		}
+
+		if rangePart == "*" || rangePart == "?" {
+			continue
+		}
+		bounds := strings.Split(rangePart, "-")
+		if len(bounds) > 2 {
+			return fmt.Errorf("invalid range: %s", term)
+		}
+		values := make([]int, 0, len(bounds))
+		for _, bound := range bounds {
+			number, err := f.parseValue(bound)
+			if err != nil {
+				return err
+			}
+			values = append(values, number)
+		}
+		if len(values) == 2 && values[0] > values[1] {
+			return fmt.Errorf("range start is greater than its end: %s", term)
+		}
+	}
+	return nil
+}
+
+func (f *cronField) parseValue(value string) (int, error) {
+	if number, ok := f.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %s", value)
+	}
+	if number < f.min || number > f.max {
+		return 0, fmt.Errorf("%d is out of range [%d, %d]", number, f.min, f.max)
+	}
+	return number, nil
+}
@@ -0,0 +1,108 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+)
+
+// webhookConfigurationKinds are the kinds of resources with a CA bundle managed by the operator
+var webhookConfigurationKinds = map[string]struct{}{
+	"ValidatingWebhookConfiguration": {},
+	"MutatingWebhookConfiguration":   {},
+}
+
+// validateCustomizePatch checks that the patch is a JSON patch with supported operations,
+// that does not modify fields that break the reconciliation of the patched resource.
+func validateCustomizePatch(patch *CustomizePatch) error {
+	operations, err := jsonpatch.DecodePatch([]byte(patch.Patch))
+	if err != nil {
+		return fmt.Errorf("is not a valid JSON patch: %v", err)
+	}
+	if len(operations) == 0 {
+		return fmt.Errorf("must contain at least one operation")
+	}
+
+	for i, operation := range operations {
+		op := operation.Kind()
+		switch op {
+		case "add", "remove", "replace", "move", "copy", "test":
+		default:
+			return fmt.Errorf("operation %d has unsupported op: %s", i, op)
+		}
+
+		path, err := operation.Path()
+		if err != nil {
+			return fmt.Errorf("operation %d is not valid: %v", i, err)
+		}
+		if op == "test" {
+			// The test operation does not modify the resource
+			continue
+		}
+		err = validatePatchTarget(patch.Kind, path)
+		if err != nil {
+			return fmt.Errorf("operation %d is not allowed: %v", i, err)
+		}
+
+		if op == "move" || op == "copy" {
+			from, err := operation.From()
+			if err != nil {
+				return fmt.Errorf("operation %d is not valid: %v", i, err)
+			}
+			// Moving a field removes it from its original location
+			if op == "move" {
+				err = validatePatchTarget(patch.Kind, from)
+				if err != nil {
+					return fmt.Errorf("operation %d is not allowed: %v", i, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validatePatchTarget checks that the path does not point to the identity of the resource,
+// to its status or owner references, or to a webhook CA bundle set by the operator
+func validatePatchTarget(kind string, path string) error {
+	fields, err := ParseFieldPath(path)
+	if err != nil {
+		return err
+	}
+	switch fields[0] {
+	case "status":
+		return fmt.Errorf("path %q must not point to the status of the resource", path)
+	case "metadata":
+		if fields[1] == "ownerReferences" {
+			return fmt.Errorf("path %q must not point to the owner references of the resource", path)
+		}
+	case "webhooks":
+		if _, ok := webhookConfigurationKinds[kind]; !ok {
+			break
+		}
+		// The CA bundle is also modified by replacing or removing any of its parents
+		caBundlePath := []string{"webhooks", "", "clientConfig", "caBundle"}
+		for i := 1; i < len(fields) && i < len(caBundlePath); i++ {
+			if caBundlePath[i] != "" && fields[i] != caBundlePath[i] {
+				return nil
+			}
+		}
+		return fmt.Errorf("path %q must not modify the CA bundle of the webhook", path)
+	}
+	return nil
+}
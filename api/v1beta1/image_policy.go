@@ -0,0 +1,81 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImagePolicy restricts the images that can be set as overrides in the SSP CR
+// +kubebuilder:object:generate=false
+type ImagePolicy struct {
+	// RequireDigest rejects images that are not referenced by a digest
+	RequireDigest bool
+
+	// AllowedRegistries are registries or repository prefixes, e.g. quay.io or quay.io/kubevirt,
+	// the images must be pulled from. Images from any registry are allowed if it is empty.
+	AllowedRegistries []string
+}
+
+// defaultRegistry is used by container runtimes for images without a registry
+const defaultRegistry = "docker.io"
+
+var imagePolicy ImagePolicy
+
+// SetImagePolicy sets the policy of image overrides checked by the webhook
+func SetImagePolicy(policy ImagePolicy) {
+	imagePolicy = policy
+}
+
+// validate checks that the image reference, already known to be valid, is allowed by the policy
+func (p *ImagePolicy) validate(field, image string) error {
+	if p.RequireDigest && !strings.Contains(image, "@") {
+		return fmt.Errorf("%s must be referenced by a digest: %s", field, image)
+	}
+	if len(p.AllowedRegistries) == 0 {
+		return nil
+	}
+	repository := imageRepository(image)
+	for _, allowed := range p.AllowedRegistries {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if repository == allowed || strings.HasPrefix(repository, allowed+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not from an allowed registry (%s): %s", field, strings.Join(p.AllowedRegistries, ", "), image)
+}
+
+// imageRepository returns the repository of the image reference, including its registry
+func imageRepository(image string) string {
+	repository := image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	// The tag follows the last colon after the last slash, a colon before it separates the registry port
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+
+	// The first component is a registry, if it looks like a host name
+	firstComponent := strings.SplitN(repository, "/", 2)[0]
+	if !strings.Contains(repository, "/") ||
+		(!strings.ContainsAny(firstComponent, ".:") && firstComponent != "localhost") {
+		return defaultRegistry + "/" + repository
+	}
+	return repository
+}
@@ -17,61 +17,775 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	ocpv1 "github.com/openshift/api/config/v1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 )
 
 const (
 	OperatorPausedAnnotation = "kubevirt.io/operator.paused"
+
+	// DryRunAnnotation makes the operator compute the changes it would do to the resources
+	// of the SSP CR using a server-side dry-run, without modifying them. The changes are
+	// listed in the "<SSP CR name>-dry-run" ConfigMap in the namespace of the SSP CR.
+	DryRunAnnotation = "ssp.kubevirt.io/dry-run"
+
+	// RollbackAnnotation makes the operator restore the spec of the SSP CR that was last
+	// deployed successfully, e.g. after a failed upgrade. The templates bundle version
+	// is pinned to the last deployed one, if it is still shipped with the operator.
+	// The annotation is removed when the spec is restored.
+	RollbackAnnotation = "ssp.kubevirt.io/rollback"
+
+	// MigrateTemplatesNamespaceAnnotation allows changing spec.commonTemplates.namespace.
+	// The operator deploys the templates to the new namespace and removes them
+	// from the previous one, and then removes the annotation. Without it, the change is rejected.
+	MigrateTemplatesNamespaceAnnotation = "ssp.kubevirt.io/migrate-templates-namespace"
+
+	// AllowTemplateModificationAnnotation allows modifying or deleting a common template,
+	// when the template protection webhook is enabled. The operator still restores
+	// the template, unless it is excluded in the SSP CR.
+	AllowTemplateModificationAnnotation = "ssp.kubevirt.io/allow-template-modification"
+
+	// ForceDeletionAnnotation allows deleting the SSP CR while DataImportCrons owned by it
+	// are importing boot sources. The interrupted imports may leave partially imported volumes.
+	ForceDeletionAnnotation = "ssp.kubevirt.io/force-deletion"
+
+	// ConditionPaused is true when the reconciliation of the SSP CR is paused
+	ConditionPaused conditionsv1.ConditionType = "Paused"
+
+	// ConditionDeleting is true when the resources of the SSP CR are being removed
+	ConditionDeleting conditionsv1.ConditionType = "Deleting"
+
+	// PhasePaused is the phase of the SSP CR when its reconciliation is paused
+	PhasePaused lifecycleapi.Phase = "Paused"
+
+	// ConditionRejected is true when the SSP CR is not reconciled, because another SSP CR is active
+	ConditionRejected conditionsv1.ConditionType = "Rejected"
+
+	// ConditionSupersededSettings is true when settings of the SSP CR have no effect,
+	// because other settings take precedence. It does not make the SSP CR degraded.
+	ConditionSupersededSettings conditionsv1.ConditionType = "SupersededSettings"
+
+	// PhaseRejected is the phase of the SSP CR when it is not reconciled, because another SSP CR is active
+	PhaseRejected lifecycleapi.Phase = "Rejected"
+
+	// TemplateArchitectureAnnotation is the architecture of the VMs created from a template.
+	// Templates without this annotation are for the default architecture configured
+	// in the KubeVirt CR, or for DefaultArchitecture if none is configured.
+	TemplateArchitectureAnnotation = "template.kubevirt.io/architecture"
+
+	// DataImportCronArchitecturesAnnotation is a comma-separated list of architectures
+	// supported by the image imported by a DataImportCron template.
+	// DataImportCron templates without this annotation support all architectures.
+	DataImportCronArchitecturesAnnotation = "ssp.kubevirt.io/dict.architectures"
+
+	// TemplateRequiredFeatureGatesAnnotation is a comma-separated list of KubeVirt feature gates
+	// needed by the VMs created from a template. Templates are not deployed
+	// while any of the feature gates is disabled in the KubeVirt CR.
+	TemplateRequiredFeatureGatesAnnotation = "template.kubevirt.io/required-feature-gates"
+
+	DefaultArchitecture = ArchitectureAMD64
+
+	// Defaults of the fields of the SSP CR. The number of template validator replicas
+	// is chosen by the operator, the other defaults are also set by the defaulting webhook.
+	DefaultTemplateValidatorReplicas     = 2
+	DefaultTemplateValidatorLogVerbosity = 2
+	DefaultOperatorLogVerbosity          = 1
+	DefaultBootSourceNamespace           = "kubevirt-os-images"
 )
 
 type TemplateValidator struct {
-	// Replicas is the number of replicas of the template validator pod
+	// Enabled determines if the template validator is deployed. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Replicas is the number of replicas of the template validator pod.
+	// Defaults to 2, or to 1 on single node clusters.
 	//+kubebuilder:validation:Minimum=0
-	//+kubebuilder:default=2
 	Replicas *int32 `json:"replicas,omitempty"`
 
 	// Placement describes the node scheduling configuration
 	Placement *lifecycleapi.NodePlacement `json:"placement,omitempty"`
+
+	// Resources are the compute resource requirements of the template validator containers
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// PriorityClassName is the name of the priority class used by the template validator pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Image overrides the template validator container image
+	Image string `json:"image,omitempty"`
+
+	// ImagePullSecrets are references to secrets used to pull the template validator image
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// TopologySpreadConstraints describes how the template validator pods are spread
+	// across topology domains. If not set, the pods are spread across nodes when possible.
+	TopologySpreadConstraints []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// RollingUpdate configures the rolling update of the template validator deployment.
+	// If not set, the Kubernetes defaults are used.
+	RollingUpdate *appsv1.RollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+
+	// Autoscaling makes the number of template validator replicas managed by a HorizontalPodAutoscaler.
+	// Replicas is ignored if it is set.
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+
+	// PodDisruptionBudget configures the pod disruption budget of the template validator.
+	// If not set, a budget with minAvailable=1 is created when more than one replica is requested,
+	// except on single node clusters.
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+
+	// ServiceMetadata defines additional labels and annotations of the template validator Service.
+	// Labels and annotations managed by the operator take precedence.
+	ServiceMetadata *ServiceMetadata `json:"serviceMetadata,omitempty"`
+
+	// WebhookFailurePolicy defines how unrecognized errors and timeout errors
+	// from the validator admission webhook are handled. Defaults to Fail.
+	//+kubebuilder:validation:Enum=Fail;Ignore
+	WebhookFailurePolicy *admissionv1.FailurePolicyType `json:"webhookFailurePolicy,omitempty"`
+
+	// WebhookTimeoutSeconds is the timeout of calls to the validator admission webhook.
+	// The API server default of 10 seconds is used if not set.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=30
+	WebhookTimeoutSeconds *int32 `json:"webhookTimeoutSeconds,omitempty"`
+
+	// WebhookNamespaceSelector restricts the namespaces validated by the validator admission webhook,
+	// in addition to spec.namespaces. The kube-system namespace is never validated.
+	WebhookNamespaceSelector *metav1.LabelSelector `json:"webhookNamespaceSelector,omitempty"`
+
+	// WebhookObjectSelector restricts the virtual machines validated by the validator admission webhook,
+	// based on their labels.
+	WebhookObjectSelector *metav1.LabelSelector `json:"webhookObjectSelector,omitempty"`
+
+	// LogVerbosity is the verbosity of the template validator logs. Defaults to 2.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=10
+	LogVerbosity *int32 `json:"logVerbosity,omitempty"`
+
+	// PodSecurityContext is the security context of the template validator pods
+	PodSecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// SecurityContext overrides fields of the security context of the template validator container.
+	// Fields that are not set keep the defaults of the operator.
+	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+}
+
+// Autoscaling configures a HorizontalPodAutoscaler of an operand
+type Autoscaling struct {
+	// MinReplicas is the lower limit of the number of replicas. Defaults to 1.
+	//+kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of the number of replicas
+	//+kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization of the pods.
+	// Defaults to 80 if no Metrics are set.
+	//+kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// Metrics are additional metrics used to compute the number of replicas,
+	// e.g. a custom metric of the admission request rate
+	Metrics []autoscalingv2beta2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// PodDisruptionBudget configures a pod disruption budget of an operand.
+// Only one of MinAvailable and MaxUnavailable can be set. The budget must allow evicting
+// a pod, and on single node clusters it must allow evicting all pods, so node drains are not blocked.
+type PodDisruptionBudget struct {
+	// Enabled determines if the pod disruption budget is created. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MinAvailable is the number or percentage of pods that must stay available during an eviction
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that can be unavailable during an eviction
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// ServiceMetadata defines additional metadata of an operand Service
+type ServiceMetadata struct {
+	// Labels added to the Service
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations added to the Service
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type CommonTemplates struct {
-	// Namespace is the k8s namespace where CommonTemplates should be installed
+	// Enabled determines if the common templates are deployed. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Namespace is the k8s namespace where CommonTemplates should be installed.
+	// It can only be changed if the ssp.kubevirt.io/migrate-templates-namespace annotation is set,
+	// the templates are then moved to the new namespace.
 	//+kubebuilder:validation:MaxLength=63
 	//+kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
 	Namespace string `json:"namespace"`
+
+	// AdditionalNamespaces is a list of extra k8s namespaces where CommonTemplates
+	// should be installed as well. Templates are removed from a namespace
+	// once it is dropped from this list.
+	AdditionalNamespaces []string `json:"additionalNamespaces,omitempty"`
+
+	// DataImportCronTemplates defines a list of DataImportCrons managed by the SSP
+	// Operator. This is intended for custom boot sources of the common templates.
+	DataImportCronTemplates []DataImportCronTemplate `json:"dataImportCronTemplates,omitempty"`
+
+	// BootSourceNamespace is the k8s namespace where the boot sources of the common templates
+	// are imported. DataImportCrons without a namespace are created in it. When it is changed,
+	// the DataImportCrons and roles are removed from the previous namespace,
+	// already imported boot sources are kept. Defaults to kubevirt-os-images.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	BootSourceNamespace string `json:"bootSourceNamespace,omitempty"`
+
+	// BootSourceStorage defines the storage used by the DataVolumes created by the DataImportCrons.
+	// These are defaults, values set in a DataImportCron template take precedence.
+	BootSourceStorage *BootSourceStorage `json:"bootSourceStorage,omitempty"`
+
+	// EnableCommonBootImageImport determines if the DataImportCrons from DataImportCronTemplates
+	// are deployed. Disabling it removes the DataImportCrons, already imported boot sources
+	// are kept. Defaults to true.
+	EnableCommonBootImageImport *bool `json:"enableCommonBootImageImport,omitempty"`
+
+	// Exclude selects templates of the bundle that should not be deployed.
+	// Excluded templates that were already deployed are removed.
+	Exclude *TemplatesExclusion `json:"exclude,omitempty"`
+
+	// BundleRef references a custom templates bundle that is deployed
+	// instead of the templates bundle shipped with the operator.
+	BundleRef *TemplatesBundleReference `json:"bundleRef,omitempty"`
+
+	// Version pins the version of the templates bundle shipped with the operator, e.g. v0.13.0.
+	// If not set, the latest shipped version is used. It is ignored if BundleRef is set.
+	//+kubebuilder:validation:Pattern=^v[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.]+)?$
+	Version string `json:"version,omitempty"`
+
+	// DeprecatedTemplatesRetention defines if the templates of previous bundle versions
+	// are kept after an upgrade. If not set, they are kept forever.
+	DeprecatedTemplatesRetention *DeprecatedTemplatesRetention `json:"deprecatedTemplatesRetention,omitempty"`
+
+	// Provider overrides the provider annotations of the deployed templates
+	Provider *TemplatesProvider `json:"provider,omitempty"`
+}
+
+// DeprecatedTemplatesRetentionPolicy defines how the templates of previous bundle versions are retained
+// +kubebuilder:validation:Enum=Delete;KeepVersions;Keep
+type DeprecatedTemplatesRetentionPolicy string
+
+const (
+	// DeprecatedTemplatesRetentionDelete deletes the templates of previous versions
+	DeprecatedTemplatesRetentionDelete DeprecatedTemplatesRetentionPolicy = "Delete"
+	// DeprecatedTemplatesRetentionKeepVersions keeps the templates of the latest previous versions
+	DeprecatedTemplatesRetentionKeepVersions DeprecatedTemplatesRetentionPolicy = "KeepVersions"
+	// DeprecatedTemplatesRetentionKeep keeps the templates of all previous versions
+	DeprecatedTemplatesRetentionKeep DeprecatedTemplatesRetentionPolicy = "Keep"
+
+	DefaultDeprecatedTemplatesRetentionVersions int32 = 1
+)
+
+// DeprecatedTemplatesRetention defines the retention of the templates of previous bundle versions
+type DeprecatedTemplatesRetention struct {
+	// Policy is the retention policy. Defaults to Keep.
+	Policy DeprecatedTemplatesRetentionPolicy `json:"policy,omitempty"`
+
+	// Versions is the number of previous versions whose templates are kept
+	// with the KeepVersions policy. Defaults to 1.
+	//+kubebuilder:validation:Minimum=1
+	Versions *int32 `json:"versions,omitempty"`
+}
+
+// GetPolicy returns the retention policy, or the default policy if it is not set
+func (r *DeprecatedTemplatesRetention) GetPolicy() DeprecatedTemplatesRetentionPolicy {
+	if r == nil || r.Policy == "" {
+		return DeprecatedTemplatesRetentionKeep
+	}
+	return r.Policy
+}
+
+// GetVersions returns the number of kept previous versions, or the default if it is not set
+func (r *DeprecatedTemplatesRetention) GetVersions() int32 {
+	if r == nil || r.Versions == nil {
+		return DefaultDeprecatedTemplatesRetentionVersions
+	}
+	return *r.Versions
+}
+
+// TemplatesProvider describes the provider of the common templates.
+// Fields that are not set keep the annotations of the templates bundle.
+type TemplatesProvider struct {
+	// Name is set as the template.kubevirt.io/provider annotation
+	Name string `json:"name,omitempty"`
+
+	// URL is set as the template.kubevirt.io/provider-url annotation
+	URL string `json:"url,omitempty"`
+
+	// SupportLevel is set as the template.kubevirt.io/provider-support-level annotation
+	SupportLevel string `json:"supportLevel,omitempty"`
+}
+
+// BootSourceStorage defines the storage of imported boot sources
+type BootSourceStorage struct {
+	// StorageClassName is the name of the storage class of the imported volumes.
+	// If not set, the default storage class of the cluster is used.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// VolumeMode is the volume mode of the imported volumes
+	//+kubebuilder:validation:Enum=Block;Filesystem
+	VolumeMode *v1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+
+	// Size is the requested size of the imported volumes
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// AccessModes are the access modes of the imported volumes.
+	// If not set, the access modes are chosen by CDI. Access modes set in
+	// the spec of a DataImportCron template take precedence.
+	AccessModes []v1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// Budget is the maximum storage requested by all boot sources. Every version kept by
+	// a DataImportCron is counted, up to its importsToKeep, or 3 if not set. If the budget is set,
+	// the size of every boot source must be known and its old versions must be garbage collected.
+	Budget *resource.Quantity `json:"budget,omitempty"`
+}
+
+// TemplatesBundleReference references a custom templates bundle
+type TemplatesBundleReference struct {
+	// ConfigMapName is the name of a ConfigMap in the namespace of the SSP CR.
+	// Every value of the ConfigMap is parsed as a multi-document YAML of templates.
+	//+kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+}
+
+// TemplatesExclusion selects common templates by name or by labels.
+// A template is excluded if it matches any of the criteria.
+type TemplatesExclusion struct {
+	// Names is a list of names of excluded templates
+	Names []string `json:"names,omitempty"`
+
+	// Selector excludes templates matching the label selector
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// DataImportCronTemplate defines the template type for DataImportCrons.
+// It requires metadata.name to be specified while leaving namespace as optional.
+// If the namespace is not set, the DataImportCron is created in the boot source namespace.
+type DataImportCronTemplate struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the spec of the DataImportCron, as defined by the CDI API
+	//+kubebuilder:pruning:PreserveUnknownFields
+	Spec runtime.RawExtension `json:"spec"`
 }
 
 type NodeLabeller struct {
+	// Enabled determines if the node-labeller is deployed. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
 	// Placement describes the node scheduling configuration
 	Placement *lifecycleapi.NodePlacement `json:"placement,omitempty"`
+
+	// Resources are the compute resource requirements of the node-labeller containers
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// PriorityClassName is the name of the priority class used by the node-labeller pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Images overrides the container images of the node-labeller
+	Images *NodeLabellerImages `json:"images,omitempty"`
+
+	// ImagePullSecrets are references to secrets used to pull the node-labeller images
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// NodeLabellerImages defines the container images used by the node-labeller.
+// Images that are not set use the default of the operator.
+type NodeLabellerImages struct {
+	// NodeLabeller is the image of the node-labeller container
+	NodeLabeller string `json:"nodeLabeller,omitempty"`
+
+	// KvmInfoNfdPlugin is the image of the kvm-info-nfd-plugin init container
+	KvmInfoNfdPlugin string `json:"kvmInfoNfdPlugin,omitempty"`
+
+	// CpuNfdPlugin is the image of the kubevirt-cpu-nfd-plugin init container
+	CpuNfdPlugin string `json:"cpuNfdPlugin,omitempty"`
+
+	// VirtLauncher is the image of the libvirt init container
+	VirtLauncher string `json:"virtLauncher,omitempty"`
+}
+
+// ComponentConfig defines the scheduling configuration of a group of operands
+type ComponentConfig struct {
+	// NodePlacement describes the node scheduling configuration
+	NodePlacement *lifecycleapi.NodePlacement `json:"nodePlacement,omitempty"`
+}
+
+// Architecture is a CPU architecture, as used by the kubernetes.io/arch node label
+// +kubebuilder:validation:Enum=amd64;arm64;s390x
+type Architecture string
+
+const (
+	ArchitectureAMD64 Architecture = "amd64"
+	ArchitectureARM64 Architecture = "arm64"
+	ArchitectureS390X Architecture = "s390x"
+)
+
+// Cluster describes the nodes of the cluster
+type Cluster struct {
+	// WorkloadArchitectures are the architectures of the nodes that run virtual machines.
+	// Templates and boot sources are only deployed for these architectures.
+	// If empty, all templates and boot sources are deployed.
+	WorkloadArchitectures []Architecture `json:"workloadArchitectures,omitempty"`
 }
 
 // SSPSpec defines the desired state of SSP
 type SSPSpec struct {
+	// Paused stops the reconciliation of the managed resources while it is set.
+	// It has the same effect as the kubevirt.io/operator.paused annotation.
+	Paused bool `json:"paused,omitempty"`
+
 	// TemplateValidator is configuration of the template validator operand
 	TemplateValidator TemplateValidator `json:"templateValidator,omitempty"`
 
 	// CommonTemplates is the configuration of the common templates operand
 	CommonTemplates CommonTemplates `json:"commonTemplates"`
 
+	// Cluster describes the nodes of the cluster, so operands can be deployed accordingly
+	Cluster *Cluster `json:"cluster,omitempty"`
+
+	// Namespaces restricts the namespaces the operands act on. If set, the template validator
+	// only validates virtual machines in these namespaces, and the namespaces of the common
+	// templates and boot sources must be listed. The template validator selects the namespaces
+	// by the kubernetes.io/metadata.name label, that the operator sets on Kubernetes older than 1.21.
+	Namespaces []string `json:"namespaces,omitempty"`
+
 	// NodeLabeller is configuration of the node-labeller operand
 	NodeLabeller NodeLabeller `json:"nodeLabeller,omitempty"`
+
+	// Infra is the scheduling configuration of infrastructure operands, like the template validator.
+	// It is used for operands that do not define their own placement.
+	Infra ComponentConfig `json:"infra,omitempty"`
+
+	// Workloads is the scheduling configuration of operands that run on workload nodes, like the node-labeller.
+	// It is used for operands that do not define their own placement.
+	Workloads ComponentConfig `json:"workloads,omitempty"`
+
+	// CommonLabels are added to all resources created by the operator.
+	// Removing a label from this map does not remove it from existing resources.
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// CommonAnnotations are added to all resources created by the operator.
+	// Removing an annotation from this map does not remove it from existing resources.
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// CustomizePatches are JSON patches applied to the resources created by the operator.
+	// The patched fields are kept by the operator instead of being reverted.
+	CustomizePatches []CustomizePatch `json:"customizePatches,omitempty"`
+
+	// IgnoredFields are fields of the resources created by the operator, that the operator
+	// does not set, so they can be managed by other components, e.g. replicas set by an autoscaler.
+	IgnoredFields []IgnoredFields `json:"ignoredFields,omitempty"`
+
+	// TLSSecurityProfile is a configuration for the TLS servers of the operands.
+	// The template validator uses it from version v0.10.0, older versions
+	// do not support it and mark the template validator as degraded.
+	// If not set, the Intermediate profile is used.
+	TLSSecurityProfile *ocpv1.TLSSecurityProfile `json:"tlsSecurityProfile,omitempty"`
+
+	// OperatorLogVerbosity is the verbosity of the operator logs. Defaults to 1.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=10
+	OperatorLogVerbosity *int32 `json:"operatorLogVerbosity,omitempty"`
+
+	// DNSPolicy is the DNS policy of the operand pods. Defaults to ClusterFirst.
+	//+kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	DNSPolicy v1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig defines DNS parameters of the operand pods, in addition to the ones generated from DNSPolicy.
+	// It must contain at least one nameserver if DNSPolicy is None.
+	DNSConfig *v1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// RuntimeClassName is the name of the RuntimeClass used to run the operand pods.
+	// If not set, the default container runtime is used.
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// Proxy configures the HTTP proxy environment variables of the operand pods.
+	// If not set, the proxy of the OpenShift cluster is used, if it is configured.
+	// It is also set as the cluster-wide import proxy of CDI, while boot sources are imported.
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// TrustedCABundle references a ConfigMap with CA certificates that are trusted
+	// by the operand pods, for example the CA of an internal registry.
+	TrustedCABundle *TrustedCABundle `json:"trustedCABundle,omitempty"`
+
+	// CertConfig configures rotation of the certificates of the operand webhooks.
+	// If set, the operator issues and rotates the certificates itself,
+	// otherwise they are provided by the OpenShift service CA operator.
+	// Intervals that are not set are taken from the certificate rotation
+	// strategy of the KubeVirt CR, if it configures them.
+	CertConfig *CertConfig `json:"certConfig,omitempty"`
+
+	// AdoptionPolicy defines how existing resources that are not owned by the SSP CR,
+	// for example resources of a manual installation, are handled. Defaults to Adopt.
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// AdmissionPolicies configures ValidatingAdmissionPolicies, that enforce some of the rules
+	// of the SSP webhooks in the API server, so they do not depend on the availability of the operator.
+	AdmissionPolicies *AdmissionPolicies `json:"admissionPolicies,omitempty"`
+}
+
+// AdmissionPolicies configures the ValidatingAdmissionPolicies created by the operator.
+// They require the admissionregistration.k8s.io/v1 ValidatingAdmissionPolicy API.
+type AdmissionPolicies struct {
+	// Enabled determines if the ValidatingAdmissionPolicies are created. Defaults to false.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// AdoptionPolicy defines how existing resources that are not owned by the SSP CR are handled
+// +kubebuilder:validation:Enum=Adopt;Refuse
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyAdopt takes ownership of the existing resources and updates them
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicyRefuse keeps the existing resources unchanged and reports the SSP CR as degraded
+	AdoptionPolicyRefuse AdoptionPolicy = "Refuse"
+)
+
+// CustomizePatch is a JSON patch applied to a resource created by the operator
+type CustomizePatch struct {
+	// Kind of the patched resource, for example Deployment
+	//+kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name of the patched resource
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the patched resource. If empty, the patch is applied to resources in any namespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Patch is a JSON patch (RFC 6902), for example:
+	// [{"op": "add", "path": "/spec/template/metadata/labels/example", "value": "true"}]
+	// The patch must not modify the kind, name, namespace, owner references or status
+	// of the resource, nor the CA bundles of webhook configurations.
+	//+kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+}
+
+// IgnoredFields are fields of a resource created by the operator, that the operator does not set
+type IgnoredFields struct {
+	// Kind of the resource, for example Deployment
+	//+kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name of the resource
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the resource. If empty, the fields are ignored on resources in any namespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Paths are JSON pointers (RFC 6901) to the ignored fields, for example /spec/replicas
+	// or /metadata/annotations/example.com~1key. Elements of lists and required fields,
+	// like /spec/maxReplicas of a HorizontalPodAutoscaler, cannot be ignored.
+	//+kubebuilder:validation:MinItems=1
+	Paths []string `json:"paths"`
+}
+
+// ParseFieldPath splits a JSON pointer (RFC 6901) to the names of the nested fields.
+// Fields identifying the resource cannot be ignored.
+func ParseFieldPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return nil, fmt.Errorf("path %q must be a JSON pointer to a field, for example /spec/replicas", path)
+	}
+	fields := strings.Split(path[1:], "/")
+	for i, field := range fields {
+		if field == "" {
+			return nil, fmt.Errorf("path %q contains an empty field name", path)
+		}
+		fields[i] = strings.ReplaceAll(strings.ReplaceAll(field, "~1", "/"), "~0", "~")
+	}
+	switch fields[0] {
+	case "apiVersion", "kind":
+		return nil, fmt.Errorf("path %q must not point to %s", path, fields[0])
+	case "metadata":
+		if len(fields) == 1 || fields[1] == "name" || fields[1] == "namespace" {
+			return nil, fmt.Errorf("path %q must not point to the name or namespace of the resource", path)
+		}
+	}
+	return fields, nil
+}
+
+// Proxy defines the HTTP proxy settings of the operand pods
+type Proxy struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domains and CIDRs for which the proxy is not used
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// TrustedCABundle references a ConfigMap in the namespace of the SSP CR that contains
+// a PEM encoded CA bundle. The bundle replaces the system trust store of the operand pods,
+// so it should also contain the public CAs that the operands have to trust.
+type TrustedCABundle struct {
+	// Name of the ConfigMap
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key of the CA bundle in the ConfigMap. Defaults to "ca-bundle.crt".
+	Key string `json:"key,omitempty"`
+}
+
+const DefaultTrustedCABundleKey = "ca-bundle.crt"
+
+// GetKey returns the key of the CA bundle in the ConfigMap
+func (t *TrustedCABundle) GetKey() string {
+	if t.Key == "" {
+		return DefaultTrustedCABundleKey
+	}
+	return t.Key
+}
+
+// CertConfig defines the rotation intervals of the certificates managed by the operator
+type CertConfig struct {
+	// CARotateInterval is the validity of the CA certificate. Defaults to 168h.
+	CARotateInterval *metav1.Duration `json:"caRotateInterval,omitempty"`
+
+	// CertRotateInterval is the validity of the serving certificates. A serving certificate
+	// is renewed when less than 20% of its validity remains. Defaults to 24h.
+	CertRotateInterval *metav1.Duration `json:"certRotateInterval,omitempty"`
+
+	// CAOverlapInterval is how long before its expiration the CA certificate is renewed.
+	// The previous CA stays trusted until it expires. Defaults to 24h.
+	CAOverlapInterval *metav1.Duration `json:"caOverlapInterval,omitempty"`
+}
+
+const (
+	DefaultCARotateInterval   = 168 * time.Hour
+	DefaultCertRotateInterval = 24 * time.Hour
+	DefaultCAOverlapInterval  = 24 * time.Hour
+)
+
+// GetCARotateInterval returns the CA rotate interval, or the default if it is not set
+func (c *CertConfig) GetCARotateInterval() time.Duration {
+	return durationOrDefault(c.CARotateInterval, DefaultCARotateInterval)
+}
+
+// GetCertRotateInterval returns the certificate rotate interval, or the default if it is not set
+func (c *CertConfig) GetCertRotateInterval() time.Duration {
+	return durationOrDefault(c.CertRotateInterval, DefaultCertRotateInterval)
+}
+
+// GetCAOverlapInterval returns the CA overlap interval, or the default if it is not set
+func (c *CertConfig) GetCAOverlapInterval() time.Duration {
+	return durationOrDefault(c.CAOverlapInterval, DefaultCAOverlapInterval)
+}
+
+func durationOrDefault(duration *metav1.Duration, defaultDuration time.Duration) time.Duration {
+	if duration == nil {
+		return defaultDuration
+	}
+	return duration.Duration
 }
 
 // SSPStatus defines the observed state of SSP
 type SSPStatus struct {
 	lifecycleapi.Status `json:",inline"`
 
-	// Paused is true when the operator notices paused annotation.
+	// Paused is true when the operator notices spec.paused or the paused annotation.
 	Paused bool `json:"paused,omitempty"`
 
 	// ObservedGeneration is the latest generation observed by the operator.
+	// It is updated when all operands were reconciled for the generation.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Operands are the statuses of the individual operands
+	Operands []OperandStatus `json:"operands,omitempty"`
+
+	// OperandsReady is the number of ready operands out of the enabled operands, for example 3/4.
+	// An operand is ready when it is available, and it is neither progressing nor degraded.
+	OperandsReady string `json:"operandsReady,omitempty"`
+
+	// RelatedObjects are references to the resources managed by the operator
+	RelatedObjects []v1.ObjectReference `json:"relatedObjects,omitempty"`
+}
+
+// OperandStatus is the status of an operand of the SSP CR
+type OperandStatus struct {
+	// Name is the name of the operand
+	Name string `json:"name"`
+
+	// Conditions are the Available, Progressing and Degraded conditions of the operand resources
+	Conditions []conditionsv1.Condition `json:"conditions,omitempty"`
+
+	// ResourcesReady is the number of ready resources out of the resources of the operand,
+	// for example 12/15. It is empty if the resources of the operand are not known.
+	ResourcesReady string `json:"resourcesReady,omitempty"`
+
+	// Version is the version of the operand that is deployed, for example the templates bundle
+	// version, or the image ID of the running containers. It is updated when all resources of the operand are ready,
+	// so it can be used to verify that an upgrade is completed.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// LastError is the most recent error of the operand reconciliation.
+	// It is kept after the operand is reconciled successfully, the conditions show the current state.
+	// +optional
+	LastError *OperandError `json:"lastError,omitempty"`
+
+	// ConsecutiveFailures is the number of failed reconciliations of the operand since it was
+	// last reconciled successfully. The operand is degraded once it reaches the failure threshold
+	// of the operator, so transient errors do not change the Available and Degraded conditions.
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// FailingSince is the time of the first of the consecutive failures
+	// +optional
+	FailingSince *metav1.Time `json:"failingSince,omitempty"`
+}
+
+// OperandError is an error that happened during the reconciliation of an operand
+type OperandError struct {
+	// Message is the error message
+	Message string `json:"message"`
+
+	// Time is when the error happened
+	Time metav1.Time `json:"time"`
+
+	// Object is the resource whose reconciliation failed.
+	// It is empty if the error is not related to a single resource.
+	// +optional
+	Object *v1.ObjectReference `json:"object,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=kvssp
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Observed Version",type=string,JSONPath=`.status.observedVersion`
+// +kubebuilder:printcolumn:name="Paused",type=boolean,JSONPath=`.status.paused`
+// +kubebuilder:printcolumn:name="Operands Ready",type=string,JSONPath=`.status.operandsReady`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // SSP is the Schema for the ssps API
 type SSP struct {
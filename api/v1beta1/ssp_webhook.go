@@ -18,28 +18,121 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
+	libhandler "github.com/operator-framework/operator-lib/handler"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+
+	"kubevirt.io/ssp-operator/internal/topology"
 )
 
+const maxTemplateValidatorReplicas = 10
+
+// dataImportCronGVK is the GroupVersionKind of the CDI DataImportCron
+var dataImportCronGVK = schema.GroupVersionKind{
+	Group:   "cdi.kubevirt.io",
+	Version: "v1beta1",
+	Kind:    "DataImportCron",
+}
+
+// imageReferenceRegexp matches image references in the form [registry/]name[:tag][@digest]
+var imageReferenceRegexp = regexp.MustCompile(
+	`^(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-*)[a-z0-9]+)*)*` +
+		`(?::[\w][\w.-]{0,127})?` +
+		`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9A-Fa-f]{32,})?$`)
+
 // log is for logging in this package.
 var ssplog = logf.Log.WithName("ssp-resource")
-var clt client.Client
+
+// clt is only used to read from the cluster. The webhooks are registered without
+// side effects, so they are also called for dry-run requests and must not modify anything.
+// It reads directly from the API server, so the webhooks do not start informers of the manager.
+var clt client.Reader
 
 func (r *SSP) SetupWebhookWithManager(mgr ctrl.Manager) error {
-	clt = mgr.GetClient()
+	clt = mgr.GetAPIReader()
+	// The builder skips paths that are already registered, so it does not replace
+	// the validating webhook returning warnings
+	mgr.GetWebhookServer().Register(validatingWebhookPath, newWarningWebhook(r))
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
 
-// +kubebuilder:webhook:verbs=create;update,path=/validate-ssp-kubevirt-io-v1beta1-ssp,mutating=false,failurePolicy=fail,groups=ssp.kubevirt.io,resources=ssps,versions=v1beta1,name=vssp.kb.io,webhookVersions=v1beta1,sideEffects=None
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-ssp-kubevirt-io-v1beta1-ssp,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=ssp.kubevirt.io,resources=ssps,versions=v1beta1,name=mssp.kb.io,webhookVersions=v1beta1,sideEffects=None
+
+var _ webhook.Defaulter = &SSP{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// It sets the defaults the operator uses for unset fields, so they are visible in the SSP CR.
+func (r *SSP) Default() {
+	ssplog.Info("default", "name", r.Name)
+
+	spec := &r.Spec
+	if spec.OperatorLogVerbosity == nil {
+		spec.OperatorLogVerbosity = pointer.Int32Ptr(DefaultOperatorLogVerbosity)
+	}
+
+	validator := &spec.TemplateValidator
+	if validator.Enabled == nil {
+		validator.Enabled = pointer.BoolPtr(true)
+	}
+	if validator.LogVerbosity == nil {
+		validator.LogVerbosity = pointer.Int32Ptr(DefaultTemplateValidatorLogVerbosity)
+	}
+	// The number of replicas depends on the cluster topology, which can change,
+	// so it is not defaulted. The operator chooses it when it is not set.
+
+	templates := &spec.CommonTemplates
+	if templates.Enabled == nil {
+		templates.Enabled = pointer.BoolPtr(true)
+	}
+	if templates.EnableCommonBootImageImport == nil {
+		templates.EnableCommonBootImageImport = pointer.BoolPtr(true)
+	}
+	if templates.BootSourceNamespace == "" && isAllowedNamespace(spec, DefaultBootSourceNamespace) {
+		templates.BootSourceNamespace = DefaultBootSourceNamespace
+	}
+
+	if spec.NodeLabeller.Enabled == nil {
+		spec.NodeLabeller.Enabled = pointer.BoolPtr(true)
+	}
+}
+
+// isAllowedNamespace returns true if the namespace can be used by the operands
+func isAllowedNamespace(spec *SSPSpec, namespace string) bool {
+	if len(spec.Namespaces) == 0 {
+		return true
+	}
+	for _, allowed := range spec.Namespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-ssp-kubevirt-io-v1beta1-ssp,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=ssp.kubevirt.io,resources=ssps,versions=v1beta1,name=vssp.kb.io,webhookVersions=v1beta1,sideEffects=None
 
 var _ webhook.Validator = &SSP{}
 
@@ -54,7 +147,13 @@ func (r *SSP) ValidateCreate() error {
 		return fmt.Errorf("could not list SSPs for validation, please try again: %v", err)
 	}
 	if len(ssps.Items) > 0 {
-		return fmt.Errorf("creation failed, an SSP CR already exists in namespace %v: %v", ssps.Items[0].ObjectMeta.Namespace, ssps.Items[0].ObjectMeta.Name)
+		existing := &ssps.Items[0]
+		hint := ""
+		if existing.DeletionTimestamp != nil {
+			hint = ", wait until the existing SSP CR is removed"
+		}
+		return fmt.Errorf("creation failed, an SSP CR already exists in namespace %v: %v. Only one SSP CR is supported in the cluster%s",
+			existing.Namespace, existing.Name, hint)
 	}
 
 	// Check if the common templates namespace exists
@@ -65,7 +164,17 @@ func (r *SSP) ValidateCreate() error {
 		return fmt.Errorf("creation failed, the configured namespace for common templates does not exist: %v", namespaceName)
 	}
 
-	return nil
+	err = validateImagePolicy(&r.Spec, nil)
+	if err != nil {
+		return err
+	}
+
+	err = validateSpec(&r.Spec)
+	if err != nil {
+		return err
+	}
+
+	return validateDataImportCronOwnership(r, nil)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -74,20 +183,805 @@ func (r *SSP) ValidateUpdate(old runtime.Object) error {
 
 	oldSsp := old.(*SSP)
 	if r.Spec.CommonTemplates.Namespace != oldSsp.Spec.CommonTemplates.Namespace {
-		return fmt.Errorf("commonTemplates.namespace cannot be changed. Attempting to change from: %v to %v",
-			oldSsp.Spec.CommonTemplates.Namespace,
-			r.Spec.CommonTemplates.Namespace)
+		if !isTemplatesNamespaceMigrationAllowed(r) {
+			return fmt.Errorf("commonTemplates.namespace cannot be changed. Attempting to change from: %v to %v. "+
+				"Set the %s annotation to \"true\" to move the templates to the new namespace",
+				oldSsp.Spec.CommonTemplates.Namespace,
+				r.Spec.CommonTemplates.Namespace,
+				MigrateTemplatesNamespaceAnnotation)
+		}
+
+		// The new namespace must exist, as on creation
+		var namespace v1.Namespace
+		err := clt.Get(context.TODO(), client.ObjectKey{Name: r.Spec.CommonTemplates.Namespace}, &namespace)
+		if err != nil {
+			return fmt.Errorf("the configured namespace for common templates does not exist: %v", r.Spec.CommonTemplates.Namespace)
+		}
+	}
+
+	err := validateImagePolicy(&r.Spec, &oldSsp.Spec)
+	if err != nil {
+		return err
+	}
+
+	err = validateSpec(&r.Spec)
+	if err != nil {
+		return err
+	}
+
+	return validateDataImportCronOwnership(r, &oldSsp.Spec)
+}
+
+// validateDisruptionBudget rejects a pod disruption budget of the template validator
+// that would block node drains, because it never allows evicting a validator pod.
+// On single node clusters, evicted pods cannot be started on another node,
+// so the budget must allow evicting all validator pods.
+func validateDisruptionBudget(spec *SSPSpec) error {
+	pdb := spec.TemplateValidator.PodDisruptionBudget
+	if pdb == nil || !pointer.BoolPtrDerefOr(pdb.Enabled, true) || (pdb.MinAvailable == nil && pdb.MaxUnavailable == nil) {
+		return nil
+	}
+	replicas, ok := templateValidatorReplicas(spec)
+	if !ok || replicas == 0 {
+		return nil
+	}
+
+	var allowedDisruptions int
+	if pdb.MinAvailable != nil {
+		minAvailable, err := intstr.GetValueFromIntOrPercent(pdb.MinAvailable, replicas, true)
+		if err != nil {
+			return fmt.Errorf("templateValidator.podDisruptionBudget.minAvailable is invalid: %v", err)
+		}
+		allowedDisruptions = replicas - minAvailable
+	} else {
+		maxUnavailable, err := intstr.GetValueFromIntOrPercent(pdb.MaxUnavailable, replicas, true)
+		if err != nil {
+			return fmt.Errorf("templateValidator.podDisruptionBudget.maxUnavailable is invalid: %v", err)
+		}
+		allowedDisruptions = maxUnavailable
+	}
+
+	if allowedDisruptions <= 0 {
+		return fmt.Errorf("templateValidator.podDisruptionBudget never allows evicting a validator pod with %d replicas, "+
+			"it would block node drains", replicas)
+	}
+	if allowedDisruptions >= replicas {
+		return nil
+	}
+
+	singleNode, err := topology.IsSingleNodeCluster(context.TODO(), clt)
+	if err != nil {
+		ssplog.Error(err, "could not determine the cluster topology, not validating templateValidator.podDisruptionBudget")
+		return nil
+	}
+	if singleNode {
+		return fmt.Errorf("templateValidator.podDisruptionBudget must allow evicting all %d validator pods "+
+			"on a single node cluster, otherwise it would block node drains", replicas)
+	}
+	return nil
+}
+
+// templateValidatorReplicas returns the minimal number of validator replicas,
+// or false if it is not set in the spec
+func templateValidatorReplicas(spec *SSPSpec) (int, bool) {
+	if autoscaling := spec.TemplateValidator.Autoscaling; autoscaling != nil {
+		return int(pointer.Int32PtrDerefOr(autoscaling.MinReplicas, 1)), true
+	}
+	if replicas := spec.TemplateValidator.Replicas; replicas != nil {
+		return int(*replicas), true
+	}
+	return 0, false
+}
+
+func isTemplatesNamespaceMigrationAllowed(sspObj *SSP) bool {
+	migrate, err := strconv.ParseBool(sspObj.GetAnnotations()[MigrateTemplatesNamespaceAnnotation])
+	return err == nil && migrate
+}
+
+func validateSpec(spec *SSPSpec) error {
+	replicas := spec.TemplateValidator.Replicas
+	if replicas != nil && (*replicas < 0 || *replicas > maxTemplateValidatorReplicas) {
+		return fmt.Errorf("templateValidator.replicas must be between 0 and %d, got: %d",
+			maxTemplateValidatorReplicas, *replicas)
+	}
+
+	if autoscaling := spec.TemplateValidator.Autoscaling; autoscaling != nil && autoscaling.MinReplicas != nil &&
+		*autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		return fmt.Errorf("templateValidator.autoscaling.minReplicas (%d) must not be greater than maxReplicas (%d)",
+			*autoscaling.MinReplicas, autoscaling.MaxReplicas)
+	}
+
+	if pdb := spec.TemplateValidator.PodDisruptionBudget; pdb != nil && pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		return fmt.Errorf("templateValidator.podDisruptionBudget cannot set both minAvailable and maxUnavailable")
+	}
+
+	err := validateDisruptionBudget(spec)
+	if err != nil {
+		return err
+	}
+
+	if selector := spec.TemplateValidator.WebhookNamespaceSelector; selector != nil {
+		_, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return fmt.Errorf("templateValidator.webhookNamespaceSelector is invalid: %v", err)
+		}
+	}
+
+	if selector := spec.TemplateValidator.WebhookObjectSelector; selector != nil {
+		_, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return fmt.Errorf("templateValidator.webhookObjectSelector is invalid: %v", err)
+		}
+	}
+
+	if exclude := spec.CommonTemplates.Exclude; exclude != nil && exclude.Selector != nil {
+		_, err := metav1.LabelSelectorAsSelector(exclude.Selector)
+		if err != nil {
+			return fmt.Errorf("commonTemplates.exclude.selector is invalid: %v", err)
+		}
+	}
+
+	if storage := spec.CommonTemplates.BootSourceStorage; storage != nil {
+		for _, accessMode := range storage.AccessModes {
+			switch accessMode {
+			case v1.ReadWriteOnce, v1.ReadOnlyMany, v1.ReadWriteMany:
+			default:
+				return fmt.Errorf("commonTemplates.bootSourceStorage.accessModes contains unsupported access mode: %s", accessMode)
+			}
+		}
+	}
+
+	err = validateImages(spec)
+	if err != nil {
+		return err
+	}
+
+	err = validatePlacements(spec)
+	if err != nil {
+		return err
+	}
+
+	err = validateTLSSecurityProfile(spec.TLSSecurityProfile)
+	if err != nil {
+		return err
+	}
+
+	if spec.DNSPolicy == v1.DNSNone && (spec.DNSConfig == nil || len(spec.DNSConfig.Nameservers) == 0) {
+		return fmt.Errorf("dnsConfig.nameservers must be set when dnsPolicy is %s", v1.DNSNone)
+	}
+
+	for i := range spec.CustomizePatches {
+		err = validateCustomizePatch(&spec.CustomizePatches[i])
+		if err != nil {
+			return fmt.Errorf("customizePatches[%d].patch %v", i, err)
+		}
+	}
+
+	for i, ignored := range spec.IgnoredFields {
+		for j, path := range ignored.Paths {
+			_, err = ParseFieldPath(path)
+			if err != nil {
+				return fmt.Errorf("ignoredFields[%d].paths[%d] is not valid: %v", i, j, err)
+			}
+		}
+	}
+
+	err = validateNamespaces(spec)
+	if err != nil {
+		return err
+	}
+
+	err = validateDataImportCronTemplates(spec)
+	if err != nil {
+		return err
+	}
+
+	err = validateBootSourceStorageBudget(spec)
+	if err != nil {
+		return err
+	}
+
+	err = validateCertConfig(spec.CertConfig)
+	if err != nil {
+		return err
+	}
+
+	// Check if the additional common templates namespaces exist
+	for _, namespaceName := range spec.CommonTemplates.AdditionalNamespaces {
+		var namespace v1.Namespace
+		err = clt.Get(context.TODO(), client.ObjectKey{Name: namespaceName}, &namespace)
+		if err != nil {
+			return fmt.Errorf("the configured additional namespace for common templates does not exist: %v", namespaceName)
+		}
+	}
+	return nil
+}
+
+// validateNamespaces checks that the namespaces used by the operands
+// are allowed by spec.namespaces
+func validateNamespaces(spec *SSPSpec) error {
+	if len(spec.Namespaces) == 0 {
+		return nil
+	}
+	allowed := make(map[string]struct{}, len(spec.Namespaces))
+	for _, namespace := range spec.Namespaces {
+		allowed[namespace] = struct{}{}
+	}
+
+	checkNamespace := func(field, namespace string) error {
+		if namespace == "" {
+			return nil
+		}
+		if _, ok := allowed[namespace]; !ok {
+			return fmt.Errorf("%s must be one of spec.namespaces, got: %s", field, namespace)
+		}
+		return nil
+	}
+
+	if err := checkNamespace("commonTemplates.namespace", spec.CommonTemplates.Namespace); err != nil {
+		return err
+	}
+	for i, namespace := range spec.CommonTemplates.AdditionalNamespaces {
+		if err := checkNamespace(fmt.Sprintf("commonTemplates.additionalNamespaces[%d]", i), namespace); err != nil {
+			return err
+		}
+	}
+	if err := checkNamespace("commonTemplates.bootSourceNamespace", spec.CommonTemplates.BootSourceNamespace); err != nil {
+		return err
+	}
+	for i, cronTemplate := range spec.CommonTemplates.DataImportCronTemplates {
+		field := fmt.Sprintf("commonTemplates.dataImportCronTemplates[%d].metadata.namespace", i)
+		if err := checkNamespace(field, cronTemplate.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDataImportCronTemplates checks the fields of the DataImportCron templates
+// that would make the DataImportCrons fail to be created or to import boot sources
+func validateDataImportCronTemplates(spec *SSPSpec) error {
+	names := make(map[string]int, len(spec.CommonTemplates.DataImportCronTemplates))
+	for i, cronTemplate := range spec.CommonTemplates.DataImportCronTemplates {
+		field := fmt.Sprintf("commonTemplates.dataImportCronTemplates[%d]", i)
+		if cronTemplate.Name == "" {
+			return fmt.Errorf("%s.metadata.name must be set", field)
+		}
+		key := dataImportCronKey(spec, &cronTemplate).String()
+		if previous, ok := names[key]; ok {
+			return fmt.Errorf("%s has the same name as commonTemplates.dataImportCronTemplates[%d]: %s", field, previous, key)
+		}
+		names[key] = i
+
+		err := validateDataImportCronSpec(cronTemplate.Spec)
+		if err != nil {
+			return fmt.Errorf("%s.spec is invalid: %v", field, err)
+		}
+	}
+	return nil
+}
+
+// dataImportCronKey returns the key of the DataImportCron created from the template
+func dataImportCronKey(spec *SSPSpec, cronTemplate *DataImportCronTemplate) client.ObjectKey {
+	namespace := cronTemplate.Namespace
+	if namespace == "" {
+		namespace = spec.CommonTemplates.BootSourceNamespace
+	}
+	if namespace == "" {
+		namespace = DefaultBootSourceNamespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: cronTemplate.Name}
+}
+
+// validateDataImportCronOwnership rejects dataImportCronTemplates whose DataImportCron
+// already exists and cannot be taken over by the SSP CR, because it is controlled
+// by another object than the SSP CR, owned by another SSP CR, or the adoption policy is Refuse.
+// On update, only templates of DataImportCrons that were not in the old spec are checked.
+func validateDataImportCronOwnership(sspObj *SSP, oldSpec *SSPSpec) error {
+	spec := &sspObj.Spec
+	if !pointer.BoolPtrDerefOr(spec.CommonTemplates.EnableCommonBootImageImport, true) {
+		return nil
 	}
 
+	oldCrons := map[client.ObjectKey]struct{}{}
+	if oldSpec != nil && pointer.BoolPtrDerefOr(oldSpec.CommonTemplates.EnableCommonBootImageImport, true) {
+		for i := range oldSpec.CommonTemplates.DataImportCronTemplates {
+			oldCrons[dataImportCronKey(oldSpec, &oldSpec.CommonTemplates.DataImportCronTemplates[i])] = struct{}{}
+		}
+	}
+
+	owner := sspObj.Namespace + "/" + sspObj.Name
+	sspGroupKind := GroupVersion.WithKind("SSP").GroupKind()
+	for i := range spec.CommonTemplates.DataImportCronTemplates {
+		key := dataImportCronKey(spec, &spec.CommonTemplates.DataImportCronTemplates[i])
+		if _, ok := oldCrons[key]; ok {
+			continue
+		}
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(dataImportCronGVK)
+		err := clt.Get(context.TODO(), key, existing)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			// The conflict is still reported by the operator, when it reconciles the DataImportCron
+			ssplog.Error(err, "could not check existing DataImportCron", "dataImportCron", key.String())
+			continue
+		}
+
+		field := fmt.Sprintf("commonTemplates.dataImportCronTemplates[%d]", i)
+		if controller := metav1.GetControllerOf(existing); controller != nil {
+			if sspObj.UID != "" && controller.UID == sspObj.UID {
+				continue
+			}
+			return fmt.Errorf("%s conflicts with existing DataImportCron %s, which is controlled by %s %s",
+				field, key, controller.Kind, controller.Name)
+		}
+		existingOwner, owned := existing.GetAnnotations()[libhandler.NamespacedNameAnnotation]
+		if owned && existingOwner == owner {
+			continue
+		}
+		if owned && existing.GetAnnotations()[libhandler.TypeAnnotation] == sspGroupKind.String() {
+			return fmt.Errorf("%s conflicts with existing DataImportCron %s, which is owned by SSP CR %s",
+				field, key, existingOwner)
+		}
+		if spec.AdoptionPolicy == AdoptionPolicyRefuse {
+			return fmt.Errorf("%s conflicts with existing DataImportCron %s, which is not owned by the SSP CR "+
+				"and the adoption policy is %s", field, key, AdoptionPolicyRefuse)
+		}
+	}
 	return nil
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func validateDataImportCronSpec(rawSpec runtime.RawExtension) error {
+	if len(rawSpec.Raw) == 0 {
+		return fmt.Errorf("spec must be set")
+	}
+	spec := map[string]interface{}{}
+	err := json.Unmarshal(rawSpec.Raw, &spec)
+	if err != nil {
+		return fmt.Errorf("not a valid object: %v", err)
+	}
+
+	schedule, _, err := unstructured.NestedString(spec, "schedule")
+	if err != nil {
+		return err
+	}
+	err = validateCronSchedule(schedule)
+	if err != nil {
+		return fmt.Errorf("schedule is not valid: %v", err)
+	}
+
+	managedDataSource, _, err := unstructured.NestedString(spec, "managedDataSource")
+	if err != nil {
+		return err
+	}
+	if managedDataSource == "" {
+		return fmt.Errorf("managedDataSource must be set")
+	}
+
+	registry, found, err := unstructured.NestedMap(spec, "template", "spec", "source", "registry")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("template.spec.source.registry must be set")
+	}
+	url, _, _ := unstructured.NestedString(registry, "url")
+	imageStream, _, _ := unstructured.NestedString(registry, "imageStream")
+	if (url == "") == (imageStream == "") {
+		return fmt.Errorf("exactly one of template.spec.source.registry.url and imageStream must be set")
+	}
+
+	garbageCollect, _, err := unstructured.NestedString(spec, "garbageCollect")
+	if err != nil {
+		return err
+	}
+	switch garbageCollect {
+	case "", "Outdated", "Never":
+	default:
+		return fmt.Errorf("garbageCollect must be Outdated or Never, got: %s", garbageCollect)
+	}
+
+	importsToKeep, found, err := unstructured.NestedFieldNoCopy(spec, "importsToKeep")
+	if err != nil {
+		return err
+	}
+	if found {
+		number, ok := importsToKeep.(float64)
+		if !ok || number < 0 || number != float64(int32(number)) {
+			return fmt.Errorf("importsToKeep must be a non-negative integer, got: %v", importsToKeep)
+		}
+	}
+
+	retentionPolicy, _, err := unstructured.NestedString(spec, "retentionPolicy")
+	if err != nil {
+		return err
+	}
+	switch retentionPolicy {
+	case "", "RetainAll", "None":
+	default:
+		return fmt.Errorf("retentionPolicy must be RetainAll or None, got: %s", retentionPolicy)
+	}
+	return nil
+}
+
+// validatePlacements checks the node placements of the operands,
+// so they do not fail only when the operand resources are created
+func validatePlacements(spec *SSPSpec) error {
+	placements := []struct {
+		field     string
+		placement *lifecycleapi.NodePlacement
+	}{
+		{"templateValidator.placement", spec.TemplateValidator.Placement},
+		{"nodeLabeller.placement", spec.NodeLabeller.Placement},
+		{"infra.nodePlacement", spec.Infra.NodePlacement},
+		{"workloads.nodePlacement", spec.Workloads.NodePlacement},
+	}
+	for _, p := range placements {
+		if p.placement == nil {
+			continue
+		}
+		err := validatePlacement(p.placement)
+		if err != nil {
+			return fmt.Errorf("%s is invalid: %v", p.field, err)
+		}
+	}
+	return nil
+}
+
+func validatePlacement(placement *lifecycleapi.NodePlacement) error {
+	for key, value := range placement.NodeSelector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("nodeSelector key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("nodeSelector value %q: %s", value, strings.Join(errs, "; "))
+		}
+	}
+
+	for i := range placement.Tolerations {
+		err := validateToleration(&placement.Tolerations[i])
+		if err != nil {
+			return fmt.Errorf("tolerations[%d]: %v", i, err)
+		}
+	}
+
+	affinity := placement.Affinity
+	if affinity == nil {
+		return nil
+	}
+	if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+		if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			if len(required.NodeSelectorTerms) == 0 {
+				return fmt.Errorf("affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms must not be empty")
+			}
+			for i := range required.NodeSelectorTerms {
+				err := validateNodeSelectorTerm(&required.NodeSelectorTerms[i])
+				if err != nil {
+					return fmt.Errorf("affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms[%d]: %v", i, err)
+				}
+			}
+		}
+		for i, preferred := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			field := fmt.Sprintf("affinity.nodeAffinity.preferredDuringSchedulingIgnoredDuringExecution[%d]", i)
+			if err := validateSchedulingWeight(preferred.Weight); err != nil {
+				return fmt.Errorf("%s: %v", field, err)
+			}
+			if err := validateNodeSelectorTerm(&preferred.Preference); err != nil {
+				return fmt.Errorf("%s.preference: %v", field, err)
+			}
+		}
+	}
+
+	if podAffinity := affinity.PodAffinity; podAffinity != nil {
+		err := validatePodAffinityTerms("affinity.podAffinity",
+			podAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			podAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+		if err != nil {
+			return err
+		}
+	}
+	if podAntiAffinity := affinity.PodAntiAffinity; podAntiAffinity != nil {
+		err := validatePodAffinityTerms("affinity.podAntiAffinity",
+			podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePodAffinityTerms(field string, required []v1.PodAffinityTerm, preferred []v1.WeightedPodAffinityTerm) error {
+	for i := range required {
+		if err := validatePodAffinityTerm(&required[i]); err != nil {
+			return fmt.Errorf("%s.requiredDuringSchedulingIgnoredDuringExecution[%d]: %v", field, i, err)
+		}
+	}
+	for i := range preferred {
+		termField := fmt.Sprintf("%s.preferredDuringSchedulingIgnoredDuringExecution[%d]", field, i)
+		if err := validateSchedulingWeight(preferred[i].Weight); err != nil {
+			return fmt.Errorf("%s: %v", termField, err)
+		}
+		if err := validatePodAffinityTerm(&preferred[i].PodAffinityTerm); err != nil {
+			return fmt.Errorf("%s.podAffinityTerm: %v", termField, err)
+		}
+	}
+	return nil
+}
+
+func validateToleration(toleration *v1.Toleration) error {
+	if toleration.Key != "" {
+		if errs := validation.IsQualifiedName(toleration.Key); len(errs) > 0 {
+			return fmt.Errorf("key %q: %s", toleration.Key, strings.Join(errs, "; "))
+		}
+	}
+	switch toleration.Operator {
+	case v1.TolerationOpEqual, "":
+		if toleration.Key == "" {
+			return fmt.Errorf("operator must be Exists when key is empty")
+		}
+		if errs := validation.IsValidLabelValue(toleration.Value); len(errs) > 0 {
+			return fmt.Errorf("value %q: %s", toleration.Value, strings.Join(errs, "; "))
+		}
+	case v1.TolerationOpExists:
+		if toleration.Value != "" {
+			return fmt.Errorf("value must be empty when operator is Exists")
+		}
+	default:
+		return fmt.Errorf("unsupported operator: %s", toleration.Operator)
+	}
+	switch toleration.Effect {
+	case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute, "":
+	default:
+		return fmt.Errorf("unsupported effect: %s", toleration.Effect)
+	}
+	if toleration.TolerationSeconds != nil && toleration.Effect != v1.TaintEffectNoExecute {
+		return fmt.Errorf("tolerationSeconds can only be set when effect is NoExecute")
+	}
+	return nil
+}
+
+func validateNodeSelectorTerm(term *v1.NodeSelectorTerm) error {
+	for i := range term.MatchExpressions {
+		if err := validateNodeSelectorRequirement(&term.MatchExpressions[i]); err != nil {
+			return fmt.Errorf("matchExpressions[%d]: %v", i, err)
+		}
+	}
+	for i, requirement := range term.MatchFields {
+		if requirement.Key != "metadata.name" {
+			return fmt.Errorf("matchFields[%d]: the only supported key is metadata.name, got: %s", i, requirement.Key)
+		}
+		if requirement.Operator != v1.NodeSelectorOpIn && requirement.Operator != v1.NodeSelectorOpNotIn {
+			return fmt.Errorf("matchFields[%d]: operator must be In or NotIn, got: %s", i, requirement.Operator)
+		}
+		if len(requirement.Values) != 1 {
+			return fmt.Errorf("matchFields[%d]: exactly one value must be set", i)
+		}
+	}
+	return nil
+}
+
+func validateNodeSelectorRequirement(requirement *v1.NodeSelectorRequirement) error {
+	if errs := validation.IsQualifiedName(requirement.Key); len(errs) > 0 {
+		return fmt.Errorf("key %q: %s", requirement.Key, strings.Join(errs, "; "))
+	}
+	switch requirement.Operator {
+	case v1.NodeSelectorOpIn, v1.NodeSelectorOpNotIn:
+		if len(requirement.Values) == 0 {
+			return fmt.Errorf("values must be set when operator is %s", requirement.Operator)
+		}
+	case v1.NodeSelectorOpExists, v1.NodeSelectorOpDoesNotExist:
+		if len(requirement.Values) > 0 {
+			return fmt.Errorf("values must be empty when operator is %s", requirement.Operator)
+		}
+	case v1.NodeSelectorOpGt, v1.NodeSelectorOpLt:
+		if len(requirement.Values) != 1 {
+			return fmt.Errorf("exactly one value must be set when operator is %s", requirement.Operator)
+		}
+		if _, err := strconv.ParseInt(requirement.Values[0], 10, 64); err != nil {
+			return fmt.Errorf("value must be an integer when operator is %s, got: %s", requirement.Operator, requirement.Values[0])
+		}
+	default:
+		return fmt.Errorf("unsupported operator: %s", requirement.Operator)
+	}
+	return nil
+}
+
+func validatePodAffinityTerm(term *v1.PodAffinityTerm) error {
+	if term.TopologyKey == "" {
+		return fmt.Errorf("topologyKey must be set")
+	}
+	if errs := validation.IsQualifiedName(term.TopologyKey); len(errs) > 0 {
+		return fmt.Errorf("topologyKey %q: %s", term.TopologyKey, strings.Join(errs, "; "))
+	}
+	if term.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(term.LabelSelector); err != nil {
+			return fmt.Errorf("labelSelector is invalid: %v", err)
+		}
+	}
+	for _, namespace := range term.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("namespace %q: %s", namespace, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+func validateSchedulingWeight(weight int32) error {
+	if weight < 1 || weight > 100 {
+		return fmt.Errorf("weight must be between 1 and 100, got: %d", weight)
+	}
+	return nil
+}
+
+func validateCertConfig(config *CertConfig) error {
+	if config == nil {
+		return nil
+	}
+	caRotateInterval := config.GetCARotateInterval()
+	certRotateInterval := config.GetCertRotateInterval()
+	caOverlapInterval := config.GetCAOverlapInterval()
+	if caRotateInterval <= 0 || certRotateInterval <= 0 || caOverlapInterval <= 0 {
+		return fmt.Errorf("certConfig intervals must be positive")
+	}
+	if caOverlapInterval >= caRotateInterval {
+		return fmt.Errorf("certConfig.caOverlapInterval (%s) must be shorter than certConfig.caRotateInterval (%s)",
+			caOverlapInterval, caRotateInterval)
+	}
+	if certRotateInterval > caRotateInterval {
+		return fmt.Errorf("certConfig.certRotateInterval (%s) must not be longer than certConfig.caRotateInterval (%s)",
+			certRotateInterval, caRotateInterval)
+	}
+	return nil
+}
+
+func validateImages(spec *SSPSpec) error {
+	for field, image := range imageOverrides(spec) {
+		if image != "" && !imageReferenceRegexp.MatchString(image) {
+			return fmt.Errorf("%s is not a valid image reference: %s", field, image)
+		}
+	}
+
+	pullSecrets := map[string][]v1.LocalObjectReference{
+		"templateValidator.imagePullSecrets": spec.TemplateValidator.ImagePullSecrets,
+		"nodeLabeller.imagePullSecrets":      spec.NodeLabeller.ImagePullSecrets,
+	}
+	for field, secrets := range pullSecrets {
+		for _, secret := range secrets {
+			if secret.Name == "" {
+				return fmt.Errorf("%s must not contain an empty secret name", field)
+			}
+		}
+	}
+	return nil
+}
+
+// imageOverrides returns the images set in the spec, by their field
+func imageOverrides(spec *SSPSpec) map[string]string {
+	images := map[string]string{
+		"templateValidator.image": spec.TemplateValidator.Image,
+	}
+	if nodeLabellerImages := spec.NodeLabeller.Images; nodeLabellerImages != nil {
+		images["nodeLabeller.images.nodeLabeller"] = nodeLabellerImages.NodeLabeller
+		images["nodeLabeller.images.kvmInfoNfdPlugin"] = nodeLabellerImages.KvmInfoNfdPlugin
+		images["nodeLabeller.images.cpuNfdPlugin"] = nodeLabellerImages.CpuNfdPlugin
+		images["nodeLabeller.images.virtLauncher"] = nodeLabellerImages.VirtLauncher
+	}
+	return images
+}
+
+// validateImagePolicy checks that the images set in the spec are allowed by the image policy.
+// On update, only changed images are checked, so the SSP CR can still be updated
+// after the policy changes.
+func validateImagePolicy(spec, oldSpec *SSPSpec) error {
+	var oldImages map[string]string
+	if oldSpec != nil {
+		oldImages = imageOverrides(oldSpec)
+	}
+	for field, image := range imageOverrides(spec) {
+		if image == "" || image == oldImages[field] {
+			continue
+		}
+		if err := imagePolicy.validate(field, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+// It rejects the deletion while DataImportCrons owned by the SSP CR are importing boot sources,
+// because the removed DataImportCrons would leave partially imported volumes.
 func (r *SSP) ValidateDelete() error {
+	ssplog.Info("validate delete", "name", r.Name)
+
+	if IsForceDeletionRequested(r) {
+		return nil
+	}
+
+	importing, err := ImportingDataImportCrons(context.TODO(), clt, r)
+	if err != nil {
+		// The operator still waits for the imports, before removing the DataImportCrons
+		ssplog.Error(err, "could not check DataImportCrons of the SSP CR")
+		return nil
+	}
+	if len(importing) > 0 {
+		return fmt.Errorf("deletion failed, DataImportCrons are importing boot sources: %s. "+
+			"Wait until the imports finish, or set the %s annotation to \"true\" to delete the SSP CR anyway",
+			strings.Join(importing, ", "), ForceDeletionAnnotation)
+	}
 	return nil
 }
 
+// IsForceDeletionRequested returns true if the force deletion annotation is set to a true value
+func IsForceDeletionRequested(sspObj *SSP) bool {
+	force, err := strconv.ParseBool(sspObj.GetAnnotations()[ForceDeletionAnnotation])
+	return err == nil && force
+}
+
+// ImportingDataImportCrons returns the keys of the DataImportCrons owned by the SSP CR,
+// that are importing a boot source
+func ImportingDataImportCrons(ctx context.Context, reader client.Reader, sspObj *SSP) ([]string, error) {
+	ownedCrons, err := ListOwnedDataImportCrons(ctx, reader, sspObj)
+	if err != nil {
+		return nil, err
+	}
+	var importing []string
+	for i := range ownedCrons {
+		if IsDataImportCronImporting(&ownedCrons[i]) {
+			importing = append(importing, ownedCrons[i].GetNamespace()+"/"+ownedCrons[i].GetName())
+		}
+	}
+	return importing, nil
+}
+
+// ListOwnedDataImportCrons returns the DataImportCrons that match the list options
+// and have the owner annotations of the SSP CR.
+// No DataImportCrons are returned if CDI is not installed.
+func ListOwnedDataImportCrons(ctx context.Context, reader client.Reader, sspObj *SSP, opts ...client.ListOption) ([]unstructured.Unstructured, error) {
+	crons := &unstructured.UnstructuredList{}
+	crons.SetGroupVersionKind(dataImportCronGVK.GroupVersion().WithKind(dataImportCronGVK.Kind + "List"))
+	err := reader.List(ctx, crons, opts...)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	owner := sspObj.Namespace + "/" + sspObj.Name
+	sspGroupKind := GroupVersion.WithKind("SSP").GroupKind()
+	var ownedCrons []unstructured.Unstructured
+	for i := range crons.Items {
+		annotations := crons.Items[i].GetAnnotations()
+		if annotations[libhandler.NamespacedNameAnnotation] == owner &&
+			annotations[libhandler.TypeAnnotation] == sspGroupKind.String() {
+			ownedCrons = append(ownedCrons, crons.Items[i])
+		}
+	}
+	return ownedCrons, nil
+}
+
+// IsDataImportCronImporting returns true if the Progressing condition of the DataImportCron is true,
+// which CDI sets while a new version of the boot source is imported
+func IsDataImportCronImporting(cron *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(cron.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if conditionMap["type"] == "Progressing" && conditionMap["status"] == string(v1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
+
 // Forces the value of clt, to be used in unit tests
-func setClientForWebhook(c client.Client) {
+func setClientForWebhook(c client.Reader) {
 	clt = c
 }
@@ -17,16 +17,32 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	ocpv1 "github.com/openshift/api/config/v1"
+	libhandler "github.com/operator-framework/operator-lib/handler"
 
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 )
 
 var _ = Describe("SSP Validation", func() {
@@ -41,6 +57,9 @@ var _ = Describe("SSP Validation", func() {
 		SchemeBuilder.AddToScheme(scheme)
 		// add more schemes
 		v1.AddToScheme(scheme)
+		// CDI types are not vendored, so DataImportCrons are handled as unstructured objects
+		scheme.AddKnownTypeWithName(dataImportCronGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(dataImportCronGVK.GroupVersion().WithKind("DataImportCronList"), &unstructured.UnstructuredList{})
 
 		client = fake.NewFakeClientWithScheme(scheme, objects...)
 		setClientForWebhook(client)
@@ -95,6 +114,29 @@ var _ = Describe("SSP Validation", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("creation failed, an SSP CR already exists in namespace test-ns: test-ssp"))
 			})
+
+			It("should ask to wait if the existing SSP CR is being deleted", func() {
+				existing := &SSP{}
+				Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "test-ns", Name: "test-ssp"}, existing)).To(Succeed())
+				now := metav1.Now()
+				existing.DeletionTimestamp = &now
+				Expect(client.Update(context.TODO(), existing)).To(Succeed())
+
+				ssp := &SSP{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-ssp2",
+						Namespace: "test-ns2",
+					},
+					Spec: SSPSpec{
+						CommonTemplates: CommonTemplates{
+							Namespace: templatesNamespace,
+						},
+					},
+				}
+				err := ssp.ValidateCreate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Only one SSP CR is supported in the cluster, wait until the existing SSP CR is removed"))
+			})
 		})
 
 		It("should fail if template namespace does not exist", func() {
@@ -114,6 +156,1261 @@ var _ = Describe("SSP Validation", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("creation failed, the configured namespace for common templates does not exist: " + nonexistingNamespace))
 		})
+
+		It("should fail if additional template namespace does not exist", func() {
+			const nonexistingNamespace = "nonexisting-namespace"
+			ssp := &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace:            templatesNamespace,
+						AdditionalNamespaces: []string{nonexistingNamespace},
+					},
+				},
+			}
+			err := ssp.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the configured additional namespace for common templates does not exist: " + nonexistingNamespace))
+		})
+	})
+
+	Context("validating template validator replicas", func() {
+		var ssp *SSP
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+				},
+			}
+		})
+
+		It("should accept replicas within bounds", func() {
+			ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(maxTemplateValidatorReplicas)
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).ToNot(HaveOccurred())
+		})
+
+		It("should reject negative replicas", func() {
+			ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(-1)
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.replicas must be between"))
+		})
+
+		It("should reject pod disruption budget with both minAvailable and maxUnavailable", func() {
+			minAvailable := intstr.FromInt(1)
+			maxUnavailable := intstr.FromInt(1)
+			ssp.Spec.TemplateValidator.PodDisruptionBudget = &PodDisruptionBudget{
+				MinAvailable:   &minAvailable,
+				MaxUnavailable: &maxUnavailable,
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot set both minAvailable and maxUnavailable"))
+		})
+
+		It("should reject autoscaling with minReplicas greater than maxReplicas", func() {
+			ssp.Spec.TemplateValidator.Autoscaling = &Autoscaling{
+				MinReplicas: pointer.Int32Ptr(3),
+				MaxReplicas: 2,
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.autoscaling.minReplicas (3) must not be greater than maxReplicas (2)"))
+		})
+
+		It("should reject too many replicas", func() {
+			ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(maxTemplateValidatorReplicas + 1)
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.replicas must be between"))
+		})
+
+		It("should reject pod disruption budget that never allows eviction", func() {
+			minAvailable := intstr.FromString("100%")
+			ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(2)
+			ssp.Spec.TemplateValidator.PodDisruptionBudget = &PodDisruptionBudget{
+				MinAvailable: &minAvailable,
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.podDisruptionBudget never allows evicting a validator pod with 2 replicas"))
+		})
+
+		It("should reject pod disruption budget with zero maxUnavailable for autoscaled validator", func() {
+			maxUnavailable := intstr.FromInt(0)
+			ssp.Spec.TemplateValidator.Autoscaling = &Autoscaling{MaxReplicas: 3}
+			ssp.Spec.TemplateValidator.PodDisruptionBudget = &PodDisruptionBudget{
+				MaxUnavailable: &maxUnavailable,
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("never allows evicting a validator pod"))
+		})
+
+		It("should accept disabled pod disruption budget that never allows eviction", func() {
+			minAvailable := intstr.FromInt(2)
+			ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(2)
+			ssp.Spec.TemplateValidator.PodDisruptionBudget = &PodDisruptionBudget{
+				Enabled:      pointer.BoolPtr(false),
+				MinAvailable: &minAvailable,
+			}
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).ToNot(HaveOccurred())
+		})
+
+		Context("with pod disruption budget", func() {
+			newNode := func(name string) *v1.Node {
+				return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			}
+
+			BeforeEach(func() {
+				minAvailable := intstr.FromInt(1)
+				ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(2)
+				ssp.Spec.TemplateValidator.PodDisruptionBudget = &PodDisruptionBudget{
+					MinAvailable: &minAvailable,
+				}
+			})
+
+			AfterEach(func() {
+				objects = make([]runtime.Object, 0)
+			})
+
+			Context("on multi node cluster", func() {
+				BeforeEach(func() {
+					objects = append(objects, newNode("node-1"), newNode("node-2"))
+				})
+
+				It("should accept pod disruption budget", func() {
+					Expect(ssp.ValidateUpdate(ssp.DeepCopy())).ToNot(HaveOccurred())
+				})
+			})
+
+			Context("on single node cluster", func() {
+				BeforeEach(func() {
+					objects = append(objects, newNode("node-1"))
+				})
+
+				It("should reject pod disruption budget that blocks drains", func() {
+					err := ssp.ValidateUpdate(ssp.DeepCopy())
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("templateValidator.podDisruptionBudget must allow evicting all 2 validator pods on a single node cluster"))
+				})
+
+				It("should accept pod disruption budget that allows evicting all pods", func() {
+					maxUnavailable := intstr.FromString("100%")
+					ssp.Spec.TemplateValidator.PodDisruptionBudget = &PodDisruptionBudget{
+						MaxUnavailable: &maxUnavailable,
+					}
+					Expect(ssp.ValidateUpdate(ssp.DeepCopy())).ToNot(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Context("validating images", func() {
+		var ssp *SSP
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+				},
+			}
+		})
+
+		It("should accept valid image references", func() {
+			ssp.Spec.TemplateValidator.Image = "mirror.example.com:5000/kubevirt/template-validator:v0.7.0"
+			ssp.Spec.NodeLabeller.Images = &NodeLabellerImages{
+				NodeLabeller: "mirror.example.com/kubevirt/node-labeller@sha256:45391da5e8ecdd393830650061f8d68248a3b2961d60f42a04e4e0c58a7d3a3b",
+				VirtLauncher: "virt-launcher",
+			}
+			ssp.Spec.TemplateValidator.ImagePullSecrets = []v1.LocalObjectReference{{Name: "pull-secret"}}
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).ToNot(HaveOccurred())
+		})
+
+		It("should reject invalid template validator image", func() {
+			ssp.Spec.TemplateValidator.Image = "Mirror.example.com/Invalid Image"
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.image is not a valid image reference"))
+		})
+
+		It("should reject invalid node-labeller image", func() {
+			ssp.Spec.NodeLabeller.Images = &NodeLabellerImages{
+				CpuNfdPlugin: "mirror.example.com/cpu-nfd-plugin:",
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nodeLabeller.images.cpuNfdPlugin is not a valid image reference"))
+		})
+
+		It("should reject empty pull secret name", func() {
+			ssp.Spec.NodeLabeller.ImagePullSecrets = []v1.LocalObjectReference{{Name: ""}}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nodeLabeller.imagePullSecrets must not contain an empty secret name"))
+		})
+
+		Context("with image policy", func() {
+			const digest = "@sha256:45391da5e8ecdd393830650061f8d68248a3b2961d60f42a04e4e0c58a7d3a3b"
+
+			BeforeEach(func() {
+				objects = append(objects, &v1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: ssp.Spec.CommonTemplates.Namespace,
+					},
+				})
+				SetImagePolicy(ImagePolicy{
+					RequireDigest:     true,
+					AllowedRegistries: []string{"quay.io/kubevirt", "mirror.example.com:5000"},
+				})
+			})
+
+			AfterEach(func() {
+				objects = make([]runtime.Object, 0)
+				SetImagePolicy(ImagePolicy{})
+			})
+
+			It("should accept allowed images", func() {
+				ssp.Spec.TemplateValidator.Image = "quay.io/kubevirt/template-validator" + digest
+				ssp.Spec.NodeLabeller.Images = &NodeLabellerImages{
+					NodeLabeller: "mirror.example.com:5000/node-labeller:v0.2.0" + digest,
+				}
+				Expect(ssp.ValidateCreate()).ToNot(HaveOccurred())
+			})
+
+			It("should reject image without digest", func() {
+				ssp.Spec.TemplateValidator.Image = "quay.io/kubevirt/template-validator:v0.7.0"
+				err := ssp.ValidateCreate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("templateValidator.image must be referenced by a digest"))
+			})
+
+			It("should reject image from not allowed registry", func() {
+				ssp.Spec.NodeLabeller.Images = &NodeLabellerImages{
+					VirtLauncher: "quay.io/kubevirt-fork/virt-launcher" + digest,
+				}
+				err := ssp.ValidateCreate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("nodeLabeller.images.virtLauncher is not from an allowed registry"))
+			})
+
+			It("should reject image from default registry", func() {
+				ssp.Spec.TemplateValidator.Image = "kubevirt/template-validator" + digest
+				err := ssp.ValidateCreate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("templateValidator.image is not from an allowed registry"))
+			})
+
+			It("should accept unchanged image on update", func() {
+				ssp.Spec.TemplateValidator.Image = "docker.io/kubevirt/template-validator:v0.7.0"
+				oldSsp := ssp.DeepCopy()
+				ssp.Spec.TemplateValidator.LogVerbosity = pointer.Int32Ptr(5)
+				Expect(ssp.ValidateUpdate(oldSsp)).ToNot(HaveOccurred())
+			})
+
+			It("should reject changed image on update", func() {
+				oldSsp := ssp.DeepCopy()
+				ssp.Spec.TemplateValidator.Image = "docker.io/kubevirt/template-validator:v0.7.0"
+				Expect(ssp.ValidateUpdate(oldSsp)).To(HaveOccurred())
+			})
+		})
+	})
+
+	It("should reject invalid template exclusion selector", func() {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+					Exclude: &TemplatesExclusion{
+						Selector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{{
+								Key:      "os.template.kubevirt.io/win10",
+								Operator: "Unknown",
+							}},
+						},
+					},
+				},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("commonTemplates.exclude.selector is invalid"))
+	})
+
+	It("should reject invalid template validator webhook selectors", func() {
+		invalidSelector := &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "skip-validation",
+				Operator: "Unknown",
+			}},
+		}
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				TemplateValidator: TemplateValidator{
+					WebhookNamespaceSelector: invalidSelector,
+				},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("templateValidator.webhookNamespaceSelector is invalid"))
+
+		ssp.Spec.TemplateValidator.WebhookNamespaceSelector = nil
+		ssp.Spec.TemplateValidator.WebhookObjectSelector = invalidSelector
+		err = ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("templateValidator.webhookObjectSelector is invalid"))
+	})
+
+	It("should reject custom TLS profile without custom settings", func() {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				TLSSecurityProfile: &ocpv1.TLSSecurityProfile{
+					Type: ocpv1.TLSProfileCustomType,
+				},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("tlsSecurityProfile.custom must be set"))
+	})
+
+	table.DescribeTable("validating custom TLS profile ciphers", func(minTLSVersion ocpv1.TLSProtocolVersion, ciphers []string, expectedErr string) {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				TLSSecurityProfile: &ocpv1.TLSSecurityProfile{
+					Type: ocpv1.TLSProfileCustomType,
+					Custom: &ocpv1.CustomTLSProfile{
+						TLSProfileSpec: ocpv1.TLSProfileSpec{
+							Ciphers:       ciphers,
+							MinTLSVersion: minTLSVersion,
+						},
+					},
+				},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		if expectedErr == "" {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErr))
+		}
+	},
+		table.Entry("should accept TLS 1.3 ciphers with TLS 1.3", ocpv1.VersionTLS13,
+			[]string{"TLS_AES_128_GCM_SHA256", "TLS_CHACHA20_POLY1305_SHA256"}, ""),
+		table.Entry("should accept TLS 1.3 without ciphers", ocpv1.VersionTLS13, nil, ""),
+		table.Entry("should accept mixed ciphers with TLS 1.2", ocpv1.VersionTLS12,
+			[]string{"TLS_AES_128_GCM_SHA256", "ECDHE-RSA-AES128-GCM-SHA256"}, ""),
+		table.Entry("should reject TLS 1.2 ciphers with TLS 1.3", ocpv1.VersionTLS13,
+			[]string{"TLS_AES_128_GCM_SHA256", "ECDHE-RSA-AES128-GCM-SHA256"},
+			"tlsSecurityProfile.custom.ciphers cannot be used with minTLSVersion VersionTLS13: ECDHE-RSA-AES128-GCM-SHA256"),
+		table.Entry("should reject only TLS 1.3 ciphers with TLS 1.2", ocpv1.VersionTLS12,
+			[]string{"TLS_AES_256_GCM_SHA384"},
+			"tlsSecurityProfile.custom.ciphers must contain a cipher for TLS versions lower than 1.3"),
+	)
+
+	Context("validating namespaces", func() {
+		var ssp *SSP
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+					Namespaces: []string{"test-templates-ns"},
+				},
+			}
+		})
+
+		It("should accept common templates namespace in the list", func() {
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).To(Succeed())
+		})
+
+		It("should reject common templates namespace not in the list", func() {
+			ssp.Spec.Namespaces = []string{"team-a"}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("commonTemplates.namespace must be one of spec.namespaces"))
+		})
+
+		It("should reject boot source namespace not in the list", func() {
+			ssp.Spec.CommonTemplates.BootSourceNamespace = "boot-sources"
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("commonTemplates.bootSourceNamespace must be one of spec.namespaces"))
+		})
+	})
+
+	It("should reject unsupported boot source access mode", func() {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+					BootSourceStorage: &BootSourceStorage{
+						AccessModes: []v1.PersistentVolumeAccessMode{"ReadWriteSometimes"},
+					},
+				},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unsupported access mode"))
+	})
+
+	It("should reject invalid customize patch", func() {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				CustomizePatches: []CustomizePatch{{
+					Kind:  "Deployment",
+					Name:  "virt-template-validator",
+					Patch: `{"op": "add"}`,
+				}},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("customizePatches[0].patch is not a valid JSON patch"))
+	})
+
+	table.DescribeTable("should validate customize patch operations", func(kind string, patch string, expectedError string) {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				CustomizePatches: []CustomizePatch{{
+					Kind:  kind,
+					Name:  "virt-template-validator",
+					Patch: patch,
+				}},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		if expectedError == "" {
+			Expect(err).ToNot(HaveOccurred())
+			return
+		}
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedError))
+	},
+		table.Entry("valid patch", "Deployment",
+			`[{"op": "add", "path": "/spec/template/metadata/labels/example", "value": "true"}]`, ""),
+		table.Entry("test operation of the name", "Deployment",
+			`[{"op": "test", "path": "/metadata/name", "value": "virt-template-validator"}]`, ""),
+		table.Entry("webhook rules", "ValidatingWebhookConfiguration",
+			`[{"op": "replace", "path": "/webhooks/0/timeoutSeconds", "value": 5}]`, ""),
+		table.Entry("empty patch", "Deployment", `[]`,
+			"customizePatches[0].patch must contain at least one operation"),
+		table.Entry("unsupported op", "Deployment", `[{"op": "merge", "path": "/spec/replicas", "value": 1}]`,
+			"customizePatches[0].patch operation 0 has unsupported op: merge"),
+		table.Entry("missing path", "Deployment", `[{"op": "remove"}]`,
+			"customizePatches[0].patch operation 0 is not valid"),
+		table.Entry("whole resource", "Deployment", `[{"op": "replace", "path": "", "value": {}}]`,
+			"must be a JSON pointer to a field"),
+		table.Entry("resource name", "Deployment",
+			`[{"op": "replace", "path": "/metadata/name", "value": "other"}]`,
+			"must not point to the name or namespace of the resource"),
+		table.Entry("status", "Deployment", `[{"op": "remove", "path": "/status"}]`,
+			"must not point to the status of the resource"),
+		table.Entry("owner references", "Deployment", `[{"op": "remove", "path": "/metadata/ownerReferences"}]`,
+			"must not point to the owner references of the resource"),
+		table.Entry("moved kind", "Deployment", `[{"op": "move", "from": "/kind", "path": "/metadata/labels/kind"}]`,
+			"must not point to kind"),
+		table.Entry("webhook CA bundle", "ValidatingWebhookConfiguration",
+			`[{"op": "replace", "path": "/webhooks/0/clientConfig/caBundle", "value": "Zm9v"}]`,
+			"must not modify the CA bundle of the webhook"),
+		table.Entry("parent of webhook CA bundle", "ValidatingWebhookConfiguration",
+			`[{"op": "replace", "path": "/webhooks/0/clientConfig", "value": {}}]`,
+			"must not modify the CA bundle of the webhook"),
+		table.Entry("all webhooks", "ValidatingWebhookConfiguration", `[{"op": "remove", "path": "/webhooks"}]`,
+			"must not modify the CA bundle of the webhook"),
+	)
+
+	It("should reject ignored fields pointing to the resource name", func() {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				IgnoredFields: []IgnoredFields{{
+					Kind:  "Deployment",
+					Name:  "virt-template-validator",
+					Paths: []string{"/spec/replicas", "/metadata/name"},
+				}},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("ignoredFields[0].paths[1] is not valid"))
+	})
+
+	It("should reject dnsPolicy None without nameservers", func() {
+		ssp := &SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-ssp",
+				Namespace: "test-ns",
+			},
+			Spec: SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Namespace: "test-templates-ns",
+				},
+				DNSPolicy: v1.DNSNone,
+				DNSConfig: &v1.PodDNSConfig{
+					Searches: []string{"example.com"},
+				},
+			},
+		}
+		err := ssp.ValidateUpdate(ssp.DeepCopy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("dnsConfig.nameservers must be set"))
+	})
+
+	Context("validating certificate rotation", func() {
+		var ssp *SSP
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+					CertConfig: &CertConfig{},
+				},
+			}
+		})
+
+		It("should accept default intervals", func() {
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).ToNot(HaveOccurred())
+		})
+
+		It("should reject CA overlap interval not shorter than CA rotate interval", func() {
+			ssp.Spec.CertConfig.CARotateInterval = &metav1.Duration{Duration: 24 * time.Hour}
+			ssp.Spec.CertConfig.CertRotateInterval = &metav1.Duration{Duration: 12 * time.Hour}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("certConfig.caOverlapInterval (24h0m0s) must be shorter"))
+		})
+
+		It("should reject certificate rotate interval longer than CA rotate interval", func() {
+			ssp.Spec.CertConfig.CertRotateInterval = &metav1.Duration{Duration: 200 * time.Hour}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("certConfig.certRotateInterval (200h0m0s) must not be longer"))
+		})
+
+		It("should reject negative interval", func() {
+			ssp.Spec.CertConfig.CAOverlapInterval = &metav1.Duration{Duration: -time.Hour}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("certConfig intervals must be positive"))
+		})
+	})
+
+	Context("validating placement", func() {
+		var ssp *SSP
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+				},
+			}
+		})
+
+		It("should accept valid placement", func() {
+			ssp.Spec.TemplateValidator.Placement = &lifecycleapi.NodePlacement{
+				NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+				Tolerations: []v1.Toleration{{
+					Key:      "node-role.kubernetes.io/master",
+					Operator: v1.TolerationOpExists,
+					Effect:   v1.TaintEffectNoSchedule,
+				}},
+				Affinity: &v1.Affinity{
+					NodeAffinity: &v1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+							NodeSelectorTerms: []v1.NodeSelectorTerm{{
+								MatchExpressions: []v1.NodeSelectorRequirement{{
+									Key:      "kubernetes.io/arch",
+									Operator: v1.NodeSelectorOpIn,
+									Values:   []string{"amd64"},
+								}},
+							}},
+						},
+					},
+					PodAntiAffinity: &v1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{{
+							Weight: 100,
+							PodAffinityTerm: v1.PodAffinityTerm{
+								TopologyKey: "kubernetes.io/hostname",
+							},
+						}},
+					},
+				},
+			}
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).To(Succeed())
+		})
+
+		It("should reject invalid node selector key", func() {
+			ssp.Spec.NodeLabeller.Placement = &lifecycleapi.NodePlacement{
+				NodeSelector: map[string]string{"invalid key": "value"},
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`nodeLabeller.placement is invalid: nodeSelector key "invalid key"`))
+		})
+
+		It("should reject toleration with value and Exists operator", func() {
+			ssp.Spec.Workloads.NodePlacement = &lifecycleapi.NodePlacement{
+				Tolerations: []v1.Toleration{{
+					Key:      "key",
+					Operator: v1.TolerationOpExists,
+					Value:    "value",
+				}},
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("workloads.nodePlacement is invalid: tolerations[0]: value must be empty when operator is Exists"))
+		})
+
+		It("should reject node selector requirement without values", func() {
+			ssp.Spec.Infra.NodePlacement = &lifecycleapi.NodePlacement{
+				Affinity: &v1.Affinity{
+					NodeAffinity: &v1.NodeAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+							Weight: 1,
+							Preference: v1.NodeSelectorTerm{
+								MatchExpressions: []v1.NodeSelectorRequirement{{
+									Key:      "kubernetes.io/arch",
+									Operator: v1.NodeSelectorOpIn,
+								}},
+							},
+						}},
+					},
+				},
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("matchExpressions[0]: values must be set when operator is In"))
+		})
+
+		It("should reject pod affinity term without topology key", func() {
+			ssp.Spec.TemplateValidator.Placement = &lifecycleapi.NodePlacement{
+				Affinity: &v1.Affinity{
+					PodAffinity: &v1.PodAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{}},
+					},
+				},
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("affinity.podAffinity.requiredDuringSchedulingIgnoredDuringExecution[0]: topologyKey must be set"))
+		})
+
+		It("should reject weight out of range", func() {
+			ssp.Spec.TemplateValidator.Placement = &lifecycleapi.NodePlacement{
+				Affinity: &v1.Affinity{
+					PodAntiAffinity: &v1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{{
+							Weight: 101,
+							PodAffinityTerm: v1.PodAffinityTerm{
+								TopologyKey: "kubernetes.io/hostname",
+							},
+						}},
+					},
+				},
+			}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("weight must be between 1 and 100, got: 101"))
+		})
+	})
+
+	Context("validating dataImportCronTemplates", func() {
+		var ssp *SSP
+
+		const validSpec = `{
+			"schedule": "0 */12 * * *",
+			"managedDataSource": "fedora",
+			"garbageCollect": "Outdated",
+			"importsToKeep": 2,
+			"template": {"spec": {"source": {"registry": {"url": "docker://quay.io/containerdisks/fedora"}}}}
+		}`
+
+		newCronTemplate := func(name, spec string) DataImportCronTemplate {
+			return DataImportCronTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       runtime.RawExtension{Raw: []byte(spec)},
+			}
+		}
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+				},
+			}
+		})
+
+		It("should accept valid templates", func() {
+			ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+				newCronTemplate("fedora", validSpec),
+				newCronTemplate("centos", strings.Replace(validSpec, `"0 */12 * * *"`, `"@daily"`, 1)),
+			}
+			Expect(ssp.ValidateUpdate(ssp.DeepCopy())).To(Succeed())
+		})
+
+		It("should reject duplicate names", func() {
+			fedora := newCronTemplate("fedora", validSpec)
+			sameNamespace := newCronTemplate("fedora", validSpec)
+			sameNamespace.Namespace = DefaultBootSourceNamespace
+			ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{fedora, sameNamespace}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("dataImportCronTemplates[1] has the same name as commonTemplates.dataImportCronTemplates[0]"))
+		})
+
+		It("should reject template without name", func() {
+			ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{newCronTemplate("", validSpec)}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("dataImportCronTemplates[0].metadata.name must be set"))
+		})
+
+		Context("with storage budget", func() {
+			// The valid spec keeps 2 imports
+			sizedSpec := strings.Replace(validSpec, `"spec": {"source"`,
+				`"spec": {"storage": {"resources": {"requests": {"storage": "10Gi"}}}, "source"`, 1)
+
+			BeforeEach(func() {
+				ssp.Spec.CommonTemplates.BootSourceStorage = &BootSourceStorage{
+					Budget: resource.NewScaledQuantity(50, resource.Giga),
+				}
+			})
+
+			It("should accept templates within the budget", func() {
+				budget := resource.MustParse("40Gi")
+				ssp.Spec.CommonTemplates.BootSourceStorage.Budget = &budget
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", sizedSpec),
+					newCronTemplate("centos", sizedSpec),
+				}
+				Expect(ssp.ValidateUpdate(ssp.DeepCopy())).To(Succeed())
+			})
+
+			It("should reject templates exceeding the budget", func() {
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", sizedSpec),
+					newCronTemplate("centos", sizedSpec),
+					newCronTemplate("rhel", sizedSpec),
+				}
+				err := ssp.ValidateUpdate(ssp.DeepCopy())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("request 60Gi of storage for all kept imports, " +
+					"which exceeds commonTemplates.bootSourceStorage.budget: 50G"))
+			})
+
+			It("should count the default number of kept imports", func() {
+				budget := resource.MustParse("25Gi")
+				ssp.Spec.CommonTemplates.BootSourceStorage.Budget = &budget
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", strings.Replace(sizedSpec, `"importsToKeep": 2,`, "", 1)),
+				}
+				err := ssp.ValidateUpdate(ssp.DeepCopy())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("request 30Gi of storage"))
+			})
+
+			It("should use the default size of boot sources", func() {
+				size := resource.MustParse("30Gi")
+				ssp.Spec.CommonTemplates.BootSourceStorage.Size = &size
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", validSpec),
+				}
+				err := ssp.ValidateUpdate(ssp.DeepCopy())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("request 60Gi of storage"))
+			})
+
+			It("should reject templates without size", func() {
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", validSpec),
+				}
+				err := ssp.ValidateUpdate(ssp.DeepCopy())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("dataImportCronTemplates[0] cannot be counted against " +
+					"commonTemplates.bootSourceStorage.budget: storage size is not set"))
+			})
+
+			It("should reject templates that are not garbage collected", func() {
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", strings.Replace(sizedSpec, `"Outdated"`, `"Never"`, 1)),
+				}
+				err := ssp.ValidateUpdate(ssp.DeepCopy())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("all imported versions are kept with garbageCollect: Never"))
+			})
+
+			It("should ignore the budget when boot image import is disabled", func() {
+				ssp.Spec.CommonTemplates.EnableCommonBootImageImport = pointer.BoolPtr(false)
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{
+					newCronTemplate("fedora", validSpec),
+				}
+				Expect(ssp.ValidateUpdate(ssp.DeepCopy())).To(Succeed())
+			})
+		})
+
+		table.DescribeTable("should reject invalid spec", func(from, to, message string) {
+			spec := strings.Replace(validSpec, from, to, 1)
+			ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{newCronTemplate("fedora", spec)}
+			err := ssp.ValidateUpdate(ssp.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+			table.Entry("with too few schedule fields", `"0 */12 * * *"`, `"0 */12 * *"`, "expected 5 fields"),
+			table.Entry("with schedule value out of range", `"0 */12 * * *"`, `"0 24 * * *"`, "invalid hour"),
+			table.Entry("with invalid schedule step", `"0 */12 * * *"`, `"0 */0 * * *"`, "step must be a positive number"),
+			table.Entry("with unknown descriptor", `"0 */12 * * *"`, `"@sometimes"`, "unknown descriptor"),
+			table.Entry("without managedDataSource", `"managedDataSource": "fedora",`, ``, "managedDataSource must be set"),
+			table.Entry("without registry source", `"registry"`, `"http"`, "template.spec.source.registry must be set"),
+			table.Entry("with both url and imageStream",
+				`"url": "docker://quay.io/containerdisks/fedora"`,
+				`"url": "docker://quay.io/containerdisks/fedora", "imageStream": "fedora"`,
+				"exactly one of template.spec.source.registry.url and imageStream must be set"),
+			table.Entry("with unknown garbageCollect", `"Outdated"`, `"Always"`, "garbageCollect must be Outdated or Never"),
+			table.Entry("with negative importsToKeep", `"importsToKeep": 2`, `"importsToKeep": -1`, "importsToKeep must be a non-negative integer"),
+		)
+		Context("with existing DataImportCron", func() {
+			var existing *unstructured.Unstructured
+
+			withoutCronTemplates := func(sspObj *SSP) *SSP {
+				old := sspObj.DeepCopy()
+				old.Spec.CommonTemplates.DataImportCronTemplates = nil
+				return old
+			}
+
+			BeforeEach(func() {
+				existing = &unstructured.Unstructured{}
+				existing.SetGroupVersionKind(dataImportCronGVK)
+				existing.SetName("fedora")
+				existing.SetNamespace(DefaultBootSourceNamespace)
+				ssp.Spec.CommonTemplates.DataImportCronTemplates = []DataImportCronTemplate{newCronTemplate("fedora", validSpec)}
+			})
+
+			createExisting := func() {
+				Expect(client.Create(context.TODO(), existing)).To(Succeed())
+			}
+
+			It("should reject DataImportCron controlled by another object", func() {
+				existing.SetOwnerReferences([]metav1.OwnerReference{{
+					APIVersion: "hco.kubevirt.io/v1beta1",
+					Kind:       "HyperConverged",
+					Name:       "kubevirt-hyperconverged",
+					UID:        "test-uid",
+					Controller: pointer.BoolPtr(true),
+				}})
+				createExisting()
+				err := ssp.ValidateUpdate(withoutCronTemplates(ssp))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("commonTemplates.dataImportCronTemplates[0] conflicts with existing DataImportCron " +
+					DefaultBootSourceNamespace + "/fedora, which is controlled by HyperConverged kubevirt-hyperconverged"))
+			})
+
+			It("should accept DataImportCron controlled by the SSP CR", func() {
+				ssp.UID = "ssp-uid"
+				existing.SetOwnerReferences([]metav1.OwnerReference{{
+					APIVersion: GroupVersion.String(),
+					Kind:       "SSP",
+					Name:       ssp.Name,
+					UID:        ssp.UID,
+					Controller: pointer.BoolPtr(true),
+				}})
+				ssp.Spec.AdoptionPolicy = AdoptionPolicyRefuse
+				createExisting()
+				Expect(ssp.ValidateUpdate(withoutCronTemplates(ssp))).To(Succeed())
+			})
+
+			It("should reject DataImportCron owned by another SSP CR", func() {
+				existing.SetAnnotations(map[string]string{
+					libhandler.NamespacedNameAnnotation: "other-ns/other-ssp",
+					libhandler.TypeAnnotation:           "SSP.ssp.kubevirt.io",
+				})
+				createExisting()
+				err := ssp.ValidateUpdate(withoutCronTemplates(ssp))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("which is owned by SSP CR other-ns/other-ssp"))
+			})
+
+			It("should reject unowned DataImportCron when adoption is refused", func() {
+				ssp.Spec.AdoptionPolicy = AdoptionPolicyRefuse
+				createExisting()
+				err := ssp.ValidateUpdate(withoutCronTemplates(ssp))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("which is not owned by the SSP CR and the adoption policy is Refuse"))
+			})
+
+			It("should accept unowned DataImportCron that can be adopted", func() {
+				createExisting()
+				Expect(ssp.ValidateUpdate(withoutCronTemplates(ssp))).To(Succeed())
+			})
+
+			It("should accept DataImportCron owned by the SSP CR", func() {
+				existing.SetAnnotations(map[string]string{
+					libhandler.NamespacedNameAnnotation: ssp.Namespace + "/" + ssp.Name,
+				})
+				ssp.Spec.AdoptionPolicy = AdoptionPolicyRefuse
+				createExisting()
+				Expect(ssp.ValidateUpdate(withoutCronTemplates(ssp))).To(Succeed())
+			})
+
+			It("should accept DataImportCron already in the old spec", func() {
+				ssp.Spec.AdoptionPolicy = AdoptionPolicyRefuse
+				createExisting()
+				Expect(ssp.ValidateUpdate(ssp.DeepCopy())).To(Succeed())
+			})
+		})
+	})
+
+	Context("validating deletion", func() {
+		var ssp *SSP
+
+		BeforeEach(func() {
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+			}
+		})
+
+		createCron := func(name, owner, progressing string) {
+			cron := &unstructured.Unstructured{}
+			cron.SetGroupVersionKind(dataImportCronGVK)
+			cron.SetName(name)
+			cron.SetNamespace(DefaultBootSourceNamespace)
+			cron.SetAnnotations(map[string]string{
+				libhandler.NamespacedNameAnnotation: owner,
+				libhandler.TypeAnnotation:           "SSP.ssp.kubevirt.io",
+			})
+			Expect(unstructured.SetNestedSlice(cron.Object, []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": progressing},
+			}, "status", "conditions")).To(Succeed())
+			Expect(client.Create(context.TODO(), cron)).To(Succeed())
+		}
+
+		It("should allow deletion without importing DataImportCrons", func() {
+			createCron("fedora", "test-ns/test-ssp", "False")
+			createCron("centos", "other-ns/other-ssp", "True")
+			Expect(ssp.ValidateDelete()).To(Succeed())
+		})
+
+		It("should reject deletion while DataImportCrons are importing", func() {
+			createCron("fedora", "test-ns/test-ssp", "True")
+			err := ssp.ValidateDelete()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("DataImportCrons are importing boot sources: " +
+				DefaultBootSourceNamespace + "/fedora"))
+		})
+
+		It("should allow deletion with the force annotation", func() {
+			createCron("fedora", "test-ns/test-ssp", "True")
+			ssp.Annotations = map[string]string{ForceDeletionAnnotation: "true"}
+			Expect(ssp.ValidateDelete()).To(Succeed())
+		})
+	})
+
+	Context("defaulting", func() {
+		var ssp *SSP
+
+		newNode := func(name string) *v1.Node {
+			return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		}
+
+		BeforeEach(func() {
+			objects = append(objects, newNode("node-1"), newNode("node-2"))
+			ssp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			objects = make([]runtime.Object, 0)
+		})
+
+		It("should set defaults of unset fields", func() {
+			ssp.Default()
+			Expect(ssp.Spec.OperatorLogVerbosity).To(Equal(pointer.Int32Ptr(DefaultOperatorLogVerbosity)))
+			Expect(ssp.Spec.TemplateValidator.Enabled).To(Equal(pointer.BoolPtr(true)))
+			Expect(ssp.Spec.TemplateValidator.LogVerbosity).To(Equal(pointer.Int32Ptr(DefaultTemplateValidatorLogVerbosity)))
+			Expect(ssp.Spec.CommonTemplates.Enabled).To(Equal(pointer.BoolPtr(true)))
+			Expect(ssp.Spec.CommonTemplates.EnableCommonBootImageImport).To(Equal(pointer.BoolPtr(true)))
+			Expect(ssp.Spec.CommonTemplates.BootSourceNamespace).To(Equal(DefaultBootSourceNamespace))
+			Expect(ssp.Spec.NodeLabeller.Enabled).To(Equal(pointer.BoolPtr(true)))
+		})
+
+		It("should not override set fields", func() {
+			ssp.Spec.TemplateValidator.Enabled = pointer.BoolPtr(false)
+			ssp.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(5)
+			ssp.Spec.CommonTemplates.BootSourceNamespace = "custom-os-images"
+			ssp.Default()
+			Expect(ssp.Spec.TemplateValidator.Enabled).To(Equal(pointer.BoolPtr(false)))
+			Expect(ssp.Spec.TemplateValidator.Replicas).To(Equal(pointer.Int32Ptr(5)))
+			Expect(ssp.Spec.CommonTemplates.BootSourceNamespace).To(Equal("custom-os-images"))
+		})
+
+		It("should not set replicas that depend on the cluster topology", func() {
+			ssp.Default()
+			Expect(ssp.Spec.TemplateValidator.Replicas).To(BeNil())
+		})
+
+		It("should not set boot source namespace that is not allowed", func() {
+			ssp.Spec.Namespaces = []string{"test-templates-ns"}
+			ssp.Default()
+			Expect(ssp.Spec.CommonTemplates.BootSourceNamespace).To(BeEmpty())
+		})
+	})
+
+	Context("superseded settings", func() {
+		table.DescribeTable("should report settings without effect", func(spec SSPSpec, expected []string) {
+			messages := spec.SupersededSettings()
+			Expect(messages).To(HaveLen(len(expected)))
+			for i := range expected {
+				Expect(messages[i]).To(HavePrefix(expected[i]))
+			}
+		},
+			table.Entry("no superseded settings", SSPSpec{
+				TemplateValidator: TemplateValidator{Replicas: pointer.Int32Ptr(2)},
+				CommonTemplates:   CommonTemplates{Version: "v0.13.1"},
+			}, nil),
+			table.Entry("replicas with autoscaling", SSPSpec{
+				TemplateValidator: TemplateValidator{
+					Replicas:    pointer.Int32Ptr(2),
+					Autoscaling: &Autoscaling{MaxReplicas: 4},
+				},
+			}, []string{"templateValidator.replicas is ignored"}),
+			table.Entry("version with bundleRef", SSPSpec{
+				CommonTemplates: CommonTemplates{
+					Version:   "v0.13.1",
+					BundleRef: &TemplatesBundleReference{ConfigMapName: "custom-templates"},
+				},
+			}, []string{"commonTemplates.version is ignored"}),
+			table.Entry("dataImportCronTemplates with disabled boot image import", SSPSpec{
+				CommonTemplates: CommonTemplates{
+					EnableCommonBootImageImport: pointer.BoolPtr(false),
+					DataImportCronTemplates: []DataImportCronTemplate{{
+						ObjectMeta: metav1.ObjectMeta{Name: "centos8-image-cron"},
+					}},
+				},
+			}, []string{"commonTemplates.dataImportCronTemplates are ignored"}),
+			table.Entry("settings of disabled node-labeller", SSPSpec{
+				NodeLabeller: NodeLabeller{
+					Enabled:           pointer.BoolPtr(false),
+					PriorityClassName: "system-node-critical",
+				},
+			}, []string{"nodeLabeller settings are ignored"}),
+			table.Entry("defaults of disabled node-labeller", SSPSpec{
+				NodeLabeller: NodeLabeller{Enabled: pointer.BoolPtr(false)},
+			}, nil),
+		)
+
+		var handler *warningHandler
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(SchemeBuilder.AddToScheme(scheme)).To(Succeed())
+			decoder, err := admission.NewDecoder(scheme)
+			Expect(err).ToNot(HaveOccurred())
+
+			handler = newWarningWebhook(&SSP{}).Handler.(*warningHandler)
+			Expect(handler.InjectDecoder(decoder)).To(Succeed())
+		})
+
+		updateRequest := func(newSsp, oldSsp *SSP) admission.Request {
+			newRaw, err := json.Marshal(newSsp)
+			Expect(err).ToNot(HaveOccurred())
+			oldRaw, err := json.Marshal(oldSsp)
+			Expect(err).ToNot(HaveOccurred())
+			return admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: admissionv1beta1.Update,
+				Object:    runtime.RawExtension{Raw: newRaw},
+				OldObject: runtime.RawExtension{Raw: oldRaw},
+			}}
+		}
+
+		newSsp := func() *SSP {
+			return &SSP{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "SSP",
+					APIVersion: GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "test-templates-ns",
+					},
+					TemplateValidator: TemplateValidator{
+						Replicas:    pointer.Int32Ptr(2),
+						Autoscaling: &Autoscaling{MaxReplicas: 4},
+					},
+				},
+			}
+		}
+
+		It("should return superseded settings as warnings", func() {
+			sspObj := newSsp()
+			response := handler.Handle(context.Background(), updateRequest(sspObj, sspObj))
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Warnings).To(HaveLen(1))
+			Expect(response.Warnings[0]).To(ContainSubstring("templateValidator.replicas is ignored"))
+		})
+
+		It("should not return warnings for denied requests", func() {
+			oldSsp := newSsp()
+			sspObj := newSsp()
+			sspObj.Spec.CommonTemplates.Namespace = "new-templates-ns"
+			response := handler.Handle(context.Background(), updateRequest(sspObj, oldSsp))
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Warnings).To(BeEmpty())
+		})
+	})
+
+	Context("migrating commonTemplates.namespace", func() {
+		const newNamespace = "new-templates-ns"
+
+		var (
+			oldSsp *SSP
+			newSsp *SSP
+		)
+
+		BeforeEach(func() {
+			objects = append(objects, &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: newNamespace,
+				},
+			})
+
+			oldSsp = &SSP{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ssp",
+					Namespace: "test-ns",
+				},
+				Spec: SSPSpec{
+					CommonTemplates: CommonTemplates{
+						Namespace: "old-ns",
+					},
+				},
+			}
+			newSsp = oldSsp.DeepCopy()
+			newSsp.Annotations = map[string]string{MigrateTemplatesNamespaceAnnotation: "true"}
+		})
+
+		AfterEach(func() {
+			objects = make([]runtime.Object, 0)
+		})
+
+		It("should allow update of commonTemplates.namespace with annotation", func() {
+			newSsp.Spec.CommonTemplates.Namespace = newNamespace
+			Expect(newSsp.ValidateUpdate(oldSsp)).To(Succeed())
+		})
+
+		It("should not allow update to namespace that does not exist", func() {
+			newSsp.Spec.CommonTemplates.Namespace = "nonexisting-ns"
+			err := newSsp.ValidateUpdate(oldSsp)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the configured namespace for common templates does not exist: nonexisting-ns"))
+		})
 	})
 
 	It("should not allow update of commonTemplates.namespace", func() {
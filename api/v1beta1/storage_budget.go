@@ -0,0 +1,100 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+// defaultImportsToKeep is the number of imported versions kept by CDI, if importsToKeep is not set
+const defaultImportsToKeep = 3
+
+// validateBootSourceStorageBudget checks that the storage requested by all imported versions
+// of the boot sources fits into commonTemplates.bootSourceStorage.budget.
+// The dataImportCronTemplates must already be validated.
+func validateBootSourceStorageBudget(spec *SSPSpec) error {
+	storage := spec.CommonTemplates.BootSourceStorage
+	if storage == nil || storage.Budget == nil ||
+		!pointer.BoolPtrDerefOr(spec.CommonTemplates.EnableCommonBootImageImport, true) {
+		return nil
+	}
+
+	total := resource.Quantity{}
+	for i := range spec.CommonTemplates.DataImportCronTemplates {
+		field := fmt.Sprintf("commonTemplates.dataImportCronTemplates[%d]", i)
+		requested, err := requestedBootSourceStorage(&spec.CommonTemplates.DataImportCronTemplates[i], storage.Size)
+		if err != nil {
+			return fmt.Errorf("%s cannot be counted against commonTemplates.bootSourceStorage.budget: %v", field, err)
+		}
+		total.Add(requested)
+	}
+
+	if total.Cmp(*storage.Budget) > 0 {
+		return fmt.Errorf("commonTemplates.dataImportCronTemplates request %s of storage for all kept imports, "+
+			"which exceeds commonTemplates.bootSourceStorage.budget: %s", total.String(), storage.Budget.String())
+	}
+	return nil
+}
+
+// requestedBootSourceStorage returns the storage requested by all versions of the boot source
+// kept by the DataImportCron. The size in the DataVolume template takes precedence over the default size.
+func requestedBootSourceStorage(cronTemplate *DataImportCronTemplate, defaultSize *resource.Quantity) (resource.Quantity, error) {
+	spec := map[string]interface{}{}
+	err := json.Unmarshal(cronTemplate.Spec.Raw, &spec)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+
+	if garbageCollect, _, _ := unstructured.NestedString(spec, "garbageCollect"); garbageCollect == "Never" {
+		return resource.Quantity{}, fmt.Errorf("all imported versions are kept with garbageCollect: Never")
+	}
+	imports := int64(defaultImportsToKeep)
+	if importsToKeep, found, _ := unstructured.NestedFieldNoCopy(spec, "importsToKeep"); found {
+		if number, ok := importsToKeep.(float64); ok && number > 1 {
+			imports = int64(number)
+		} else {
+			// At least the last imported version is kept
+			imports = 1
+		}
+	}
+
+	// The operator sets the default size in the pvc section of the DataVolume, if it is present
+	dataVolumeSpec := []string{"template", "spec"}
+	storageField := append(dataVolumeSpec, "storage")
+	if _, found, _ := unstructured.NestedMap(spec, append(dataVolumeSpec, "pvc")...); found {
+		storageField = append(dataVolumeSpec, "pvc")
+	}
+	size := defaultSize
+	sizeStr, found, _ := unstructured.NestedString(spec, append(storageField, "resources", "requests", "storage")...)
+	if found {
+		parsed, err := resource.ParseQuantity(sizeStr)
+		if err != nil {
+			return resource.Quantity{}, fmt.Errorf("invalid storage size: %v", err)
+		}
+		size = &parsed
+	}
+	if size == nil {
+		return resource.Quantity{}, fmt.Errorf("storage size is not set")
+	}
+
+	return *resource.NewQuantity(size.Value()*imports, size.Format), nil
+}
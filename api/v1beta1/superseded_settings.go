@@ -0,0 +1,94 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	"k8s.io/api/admission/v1beta1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// validatingWebhookPath is the path the webhook builder uses for the validating webhook of the SSP CR
+const validatingWebhookPath = "/validate-ssp-kubevirt-io-v1beta1-ssp"
+
+// SupersededSettings returns messages describing the settings of the spec that are set,
+// but have no effect because other settings take precedence.
+func (s *SSPSpec) SupersededSettings() []string {
+	var messages []string
+	if s.TemplateValidator.Autoscaling != nil && s.TemplateValidator.Replicas != nil {
+		messages = append(messages, "templateValidator.replicas is ignored, "+
+			"the number of template validator replicas is managed by templateValidator.autoscaling")
+	}
+	if s.CommonTemplates.BundleRef != nil && s.CommonTemplates.Version != "" {
+		messages = append(messages, "commonTemplates.version is ignored, "+
+			"the common templates are loaded from commonTemplates.bundleRef")
+	}
+	if !pointer.BoolPtrDerefOr(s.CommonTemplates.EnableCommonBootImageImport, true) &&
+		len(s.CommonTemplates.DataImportCronTemplates) > 0 {
+		messages = append(messages, "commonTemplates.dataImportCronTemplates are ignored, "+
+			"no DataImportCrons are deployed when commonTemplates.enableCommonBootImageImport is false")
+	}
+	nodeLabeller := &s.NodeLabeller
+	if !pointer.BoolPtrDerefOr(nodeLabeller.Enabled, true) && (nodeLabeller.Placement != nil ||
+		nodeLabeller.Resources != nil || nodeLabeller.PriorityClassName != "" ||
+		nodeLabeller.Images != nil || len(nodeLabeller.ImagePullSecrets) > 0) {
+		messages = append(messages, "nodeLabeller settings are ignored, the node-labeller is not deployed "+
+			"when nodeLabeller.enabled is false")
+	}
+	return messages
+}
+
+// warningHandler returns the superseded settings of admitted SSP CRs as admission warnings.
+// The Validator interface cannot return warnings, so the validating handler is wrapped.
+type warningHandler struct {
+	validator admission.Handler
+	decoder   *admission.Decoder
+}
+
+var _ admission.Handler = &warningHandler{}
+
+func newWarningWebhook(validator admission.Validator) *admission.Webhook {
+	return &admission.Webhook{
+		Handler: &warningHandler{validator: admission.ValidatingWebhookFor(validator).Handler},
+	}
+}
+
+// InjectDecoder injects the decoder also into the wrapped validating handler
+func (h *warningHandler) InjectDecoder(decoder *admission.Decoder) error {
+	h.decoder = decoder
+	_, err := admission.InjectDecoderInto(decoder, h.validator)
+	return err
+}
+
+func (h *warningHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	response := h.validator.Handle(ctx, req)
+	if !response.Allowed || (req.Operation != v1beta1.Create && req.Operation != v1beta1.Update) {
+		return response
+	}
+
+	sspObj := &SSP{}
+	err := h.decoder.Decode(req, sspObj)
+	if err != nil {
+		// The validating handler decoded the same object, so this is not expected
+		ssplog.Error(err, "could not decode SSP CR, no warnings are returned")
+		return response
+	}
+	response.Warnings = sspObj.Spec.SupersededSettings()
+	return response
+}
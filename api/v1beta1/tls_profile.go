@@ -0,0 +1,68 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	ocpv1 "github.com/openshift/api/config/v1"
+)
+
+// tls13CipherSuites are the ciphers that can only be used with TLS 1.3
+var tls13CipherSuites = map[string]struct{}{
+	"TLS_AES_128_GCM_SHA256":       {},
+	"TLS_AES_256_GCM_SHA384":       {},
+	"TLS_CHACHA20_POLY1305_SHA256": {},
+}
+
+// validateTLSSecurityProfile checks that the ciphers of a custom profile can be used
+// with its minimal TLS version, so the operands do not fail to start
+func validateTLSSecurityProfile(profile *ocpv1.TLSSecurityProfile) error {
+	if profile == nil || profile.Type != ocpv1.TLSProfileCustomType {
+		return nil
+	}
+	if profile.Custom == nil {
+		return fmt.Errorf("tlsSecurityProfile.custom must be set when the profile type is %s", ocpv1.TLSProfileCustomType)
+	}
+
+	spec := profile.Custom.TLSProfileSpec
+	if len(spec.Ciphers) == 0 {
+		return nil
+	}
+	var tls13Ciphers, otherCiphers []string
+	for _, cipher := range spec.Ciphers {
+		if _, ok := tls13CipherSuites[cipher]; ok {
+			tls13Ciphers = append(tls13Ciphers, cipher)
+		} else {
+			otherCiphers = append(otherCiphers, cipher)
+		}
+	}
+
+	if spec.MinTLSVersion == ocpv1.VersionTLS13 {
+		if len(otherCiphers) > 0 {
+			return fmt.Errorf("tlsSecurityProfile.custom.ciphers cannot be used with minTLSVersion %s: %s",
+				spec.MinTLSVersion, strings.Join(otherCiphers, ", "))
+		}
+		return nil
+	}
+	if len(otherCiphers) == 0 {
+		return fmt.Errorf("tlsSecurityProfile.custom.ciphers must contain a cipher for TLS versions lower than 1.3 "+
+			"when minTLSVersion is %s, found only: %s", spec.MinTLSVersion, strings.Join(tls13Ciphers, ", "))
+	}
+	return nil
+}
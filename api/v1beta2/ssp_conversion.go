@@ -0,0 +1,134 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+var _ conversion.Convertible = &SSP{}
+
+// ConvertTo converts this SSP to the hub version.
+// All fields of the spec are mapped between the versions, so no data is lost.
+func (src *SSP) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.SSP)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+	dst.Spec = convertSpecToV1beta1(&src.Spec)
+	return nil
+}
+
+// ConvertFrom converts from the hub version to this version
+func (dst *SSP) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.SSP)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+	dst.Spec = convertSpecFromV1beta1(&src.Spec)
+	return nil
+}
+
+func convertSpecToV1beta1(src *SSPSpec) v1beta1.SSPSpec {
+	spec := src.DeepCopy()
+	return v1beta1.SSPSpec{
+		Paused:            spec.Paused,
+		TemplateValidator: spec.TemplateValidator,
+		CommonTemplates: v1beta1.CommonTemplates{
+			Enabled:                      spec.Templates.Enabled,
+			Namespace:                    spec.Templates.Namespace,
+			AdditionalNamespaces:         spec.Templates.AdditionalNamespaces,
+			DataImportCronTemplates:      spec.BootSources.DataImportCronTemplates,
+			BootSourceNamespace:          spec.BootSources.Namespace,
+			BootSourceStorage:            spec.BootSources.Storage,
+			EnableCommonBootImageImport:  spec.BootSources.Enabled,
+			Exclude:                      spec.Templates.Exclude,
+			BundleRef:                    spec.Templates.BundleRef,
+			Version:                      spec.Templates.Version,
+			DeprecatedTemplatesRetention: spec.Templates.DeprecatedTemplatesRetention,
+			Provider:                     spec.Templates.Provider,
+		},
+		Cluster:              spec.Cluster,
+		Namespaces:           spec.Namespaces,
+		NodeLabeller:         spec.NodeLabeller,
+		Infra:                spec.Placement.Infra,
+		Workloads:            spec.Placement.Workloads,
+		CommonLabels:         spec.CommonLabels,
+		CommonAnnotations:    spec.CommonAnnotations,
+		CustomizePatches:     spec.CustomizePatches,
+		IgnoredFields:        spec.IgnoredFields,
+		TLSSecurityProfile:   spec.Security.TLSSecurityProfile,
+		OperatorLogVerbosity: spec.OperatorLogVerbosity,
+		DNSPolicy:            spec.Pods.DNSPolicy,
+		DNSConfig:            spec.Pods.DNSConfig,
+		RuntimeClassName:     spec.Pods.RuntimeClassName,
+		Proxy:                spec.Pods.Proxy,
+		TrustedCABundle:      spec.Pods.TrustedCABundle,
+		CertConfig:           spec.Security.CertConfig,
+		AdoptionPolicy:       spec.AdoptionPolicy,
+		AdmissionPolicies:    spec.Security.AdmissionPolicies,
+	}
+}
+
+func convertSpecFromV1beta1(src *v1beta1.SSPSpec) SSPSpec {
+	spec := src.DeepCopy()
+	return SSPSpec{
+		Paused: spec.Paused,
+		Templates: Templates{
+			Enabled:                      spec.CommonTemplates.Enabled,
+			Namespace:                    spec.CommonTemplates.Namespace,
+			AdditionalNamespaces:         spec.CommonTemplates.AdditionalNamespaces,
+			Exclude:                      spec.CommonTemplates.Exclude,
+			BundleRef:                    spec.CommonTemplates.BundleRef,
+			Version:                      spec.CommonTemplates.Version,
+			DeprecatedTemplatesRetention: spec.CommonTemplates.DeprecatedTemplatesRetention,
+			Provider:                     spec.CommonTemplates.Provider,
+		},
+		BootSources: BootSources{
+			Enabled:                 spec.CommonTemplates.EnableCommonBootImageImport,
+			Namespace:               spec.CommonTemplates.BootSourceNamespace,
+			Storage:                 spec.CommonTemplates.BootSourceStorage,
+			DataImportCronTemplates: spec.CommonTemplates.DataImportCronTemplates,
+		},
+		TemplateValidator: spec.TemplateValidator,
+		NodeLabeller:      spec.NodeLabeller,
+		Placement: Placement{
+			Infra:     spec.Infra,
+			Workloads: spec.Workloads,
+		},
+		Pods: OperandPods{
+			DNSPolicy:        spec.DNSPolicy,
+			DNSConfig:        spec.DNSConfig,
+			RuntimeClassName: spec.RuntimeClassName,
+			Proxy:            spec.Proxy,
+			TrustedCABundle:  spec.TrustedCABundle,
+		},
+		Security: Security{
+			TLSSecurityProfile: spec.TLSSecurityProfile,
+			CertConfig:         spec.CertConfig,
+			AdmissionPolicies:  spec.AdmissionPolicies,
+		},
+		Cluster:              spec.Cluster,
+		Namespaces:           spec.Namespaces,
+		CommonLabels:         spec.CommonLabels,
+		CommonAnnotations:    spec.CommonAnnotations,
+		CustomizePatches:     spec.CustomizePatches,
+		IgnoredFields:        spec.IgnoredFields,
+		OperatorLogVerbosity: spec.OperatorLogVerbosity,
+		AdoptionPolicy:       spec.AdoptionPolicy,
+	}
+}
@@ -0,0 +1,92 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+var _ = Describe("SSP conversion", func() {
+	var (
+		fuzzer *fuzz.Fuzzer
+		hub    *v1beta1.SSP
+	)
+
+	BeforeEach(func() {
+		fuzzer = fuzz.New().NilChance(0).NumElements(1, 2).Funcs(
+			func(q *resource.Quantity, c fuzz.Continue) {
+				*q = resource.MustParse("10Gi")
+			},
+			func(cronTemplate *v1beta1.DataImportCronTemplate, c fuzz.Continue) {
+				cronTemplate.Name = c.RandString()
+				cronTemplate.Namespace = c.RandString()
+				cronTemplate.Spec = runtime.RawExtension{Raw: []byte(`{"schedule":"@daily"}`)}
+			},
+		)
+
+		hub = &v1beta1.SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-ssp",
+				Namespace:   "test-ns",
+				Annotations: map[string]string{"test-annotation": "value"},
+			},
+		}
+		fuzzer.Fuzz(&hub.Spec)
+	})
+
+	It("should convert all fields of the spec", func() {
+		spec := convertSpecFromV1beta1(&hub.Spec)
+		Expect(convertSpecToV1beta1(&spec)).To(Equal(hub.Spec))
+	})
+
+	It("should round-trip SSP CR through v1beta2", func() {
+		converted := &SSP{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.ObjectMeta).To(Equal(hub.ObjectMeta))
+		Expect(converted.Spec.Templates.Namespace).To(Equal(hub.Spec.CommonTemplates.Namespace))
+
+		result := &v1beta1.SSP{}
+		Expect(converted.ConvertTo(result)).To(Succeed())
+		Expect(result.ObjectMeta).To(Equal(hub.ObjectMeta))
+		Expect(result.Spec).To(Equal(hub.Spec))
+	})
+
+	It("should convert a changed v1beta2 spec", func() {
+		converted := &SSP{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		converted.Spec.OperatorLogVerbosity = pointer.Int32Ptr(7)
+
+		result := &v1beta1.SSP{}
+		Expect(converted.ConvertTo(result)).To(Succeed())
+		Expect(result.Spec.OperatorLogVerbosity).To(Equal(pointer.Int32Ptr(7)))
+	})
+})
+
+func TestAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "API v1beta2 Suite")
+}
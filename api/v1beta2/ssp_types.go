@@ -0,0 +1,236 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	ocpv1 "github.com/openshift/api/config/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+// The v1beta2 API groups the configuration of the v1beta1 API by operand.
+// Types that did not change between the versions are reused from v1beta1.
+
+// SSPSpec defines the desired state of SSP
+type SSPSpec struct {
+	// Paused stops the reconciliation of the managed resources while it is set.
+	// It has the same effect as the kubevirt.io/operator.paused annotation.
+	Paused bool `json:"paused,omitempty"`
+
+	// Templates is the configuration of the common templates operand
+	Templates Templates `json:"templates"`
+
+	// BootSources is the configuration of the boot source images used by the common templates
+	BootSources BootSources `json:"bootSources,omitempty"`
+
+	// TemplateValidator is the configuration of the template validator operand
+	TemplateValidator v1beta1.TemplateValidator `json:"templateValidator,omitempty"`
+
+	// NodeLabeller is the configuration of the node-labeller operand
+	NodeLabeller v1beta1.NodeLabeller `json:"nodeLabeller,omitempty"`
+
+	// Placement is the scheduling configuration of the operands that do not define their own placement
+	Placement Placement `json:"placement,omitempty"`
+
+	// Pods is the configuration shared by all operand pods
+	Pods OperandPods `json:"pods,omitempty"`
+
+	// Security is the configuration of the TLS servers and certificates of the operands
+	Security Security `json:"security,omitempty"`
+
+	// Cluster describes the nodes of the cluster, so operands can be deployed accordingly
+	Cluster *v1beta1.Cluster `json:"cluster,omitempty"`
+
+	// Namespaces restricts the namespaces the operands act on. If set, the template validator
+	// only validates virtual machines in these namespaces, and the namespaces of the common
+	// templates and boot sources must be listed. The template validator selects the namespaces
+	// by the kubernetes.io/metadata.name label, that the operator sets on Kubernetes older than 1.21.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// CommonLabels are added to all resources created by the operator.
+	// Removing a label from this map does not remove it from existing resources.
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// CommonAnnotations are added to all resources created by the operator.
+	// Removing an annotation from this map does not remove it from existing resources.
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// CustomizePatches are JSON patches applied to the resources created by the operator.
+	// The patched fields are kept by the operator instead of being reverted.
+	CustomizePatches []v1beta1.CustomizePatch `json:"customizePatches,omitempty"`
+
+	// IgnoredFields are fields of the resources created by the operator, that the operator
+	// does not set, so they can be managed by other components, e.g. replicas set by an autoscaler.
+	IgnoredFields []v1beta1.IgnoredFields `json:"ignoredFields,omitempty"`
+
+	// OperatorLogVerbosity is the log verbosity of the operator. Defaults to 1.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=10
+	OperatorLogVerbosity *int32 `json:"operatorLogVerbosity,omitempty"`
+
+	// AdoptionPolicy defines how existing resources that are not owned by the SSP CR,
+	// for example resources of a manual installation, are handled. Defaults to Adopt.
+	AdoptionPolicy v1beta1.AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+}
+
+// Templates is the configuration of the common templates operand
+type Templates struct {
+	// Enabled determines if the common templates are deployed. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Namespace is the k8s namespace where CommonTemplates should be installed.
+	// It can only be changed if the ssp.kubevirt.io/migrate-templates-namespace annotation is set,
+	// the templates are then moved to the new namespace.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Namespace string `json:"namespace"`
+
+	// AdditionalNamespaces is a list of extra k8s namespaces where CommonTemplates
+	// should be installed as well. Templates are removed from a namespace
+	// once it is dropped from this list.
+	AdditionalNamespaces []string `json:"additionalNamespaces,omitempty"`
+
+	// Exclude selects templates of the bundle that should not be deployed.
+	// Excluded templates that were already deployed are removed.
+	Exclude *v1beta1.TemplatesExclusion `json:"exclude,omitempty"`
+
+	// BundleRef references a custom templates bundle that is deployed
+	// instead of the templates bundle shipped with the operator.
+	BundleRef *v1beta1.TemplatesBundleReference `json:"bundleRef,omitempty"`
+
+	// Version pins the version of the templates bundle shipped with the operator, e.g. v0.13.0.
+	// If not set, the latest shipped version is used. It is ignored if BundleRef is set.
+	//+kubebuilder:validation:Pattern=^v[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.]+)?$
+	Version string `json:"version,omitempty"`
+
+	// DeprecatedTemplatesRetention defines if the templates of previous bundle versions
+	// are kept after an upgrade. If not set, they are kept forever.
+	DeprecatedTemplatesRetention *v1beta1.DeprecatedTemplatesRetention `json:"deprecatedTemplatesRetention,omitempty"`
+
+	// Provider overrides the provider annotations of the deployed templates
+	Provider *v1beta1.TemplatesProvider `json:"provider,omitempty"`
+}
+
+// BootSources is the configuration of the boot source images used by the common templates
+type BootSources struct {
+	// Enabled determines if the DataImportCrons from DataImportCronTemplates
+	// are deployed. Disabling it removes the DataImportCrons, already imported boot sources
+	// are kept. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Namespace is the k8s namespace where the boot sources of the common templates
+	// are imported. DataImportCrons without a namespace are created in it. When it is changed,
+	// the DataImportCrons and roles are removed from the previous namespace,
+	// already imported boot sources are kept. Defaults to kubevirt-os-images.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Namespace string `json:"namespace,omitempty"`
+
+	// Storage defines the storage used by the DataVolumes created by the DataImportCrons.
+	// These are defaults, values set in a DataImportCron template take precedence.
+	Storage *v1beta1.BootSourceStorage `json:"storage,omitempty"`
+
+	// DataImportCronTemplates defines a list of DataImportCrons managed by the SSP
+	// Operator. This is intended for custom boot sources of the common templates.
+	DataImportCronTemplates []v1beta1.DataImportCronTemplate `json:"dataImportCronTemplates,omitempty"`
+}
+
+// Placement is the scheduling configuration of the operands
+type Placement struct {
+	// Infra is the scheduling configuration of infrastructure operands, like the template validator
+	Infra v1beta1.ComponentConfig `json:"infra,omitempty"`
+
+	// Workloads is the scheduling configuration of operands that run on workload nodes, like the node-labeller
+	Workloads v1beta1.ComponentConfig `json:"workloads,omitempty"`
+}
+
+// OperandPods is the configuration shared by all operand pods
+type OperandPods struct {
+	// DNSPolicy is the DNS policy of the operand pods. Defaults to ClusterFirst.
+	//+kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	DNSPolicy v1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig defines DNS parameters of the operand pods, in addition to the ones generated from DNSPolicy.
+	// It must contain at least one nameserver if DNSPolicy is None.
+	DNSConfig *v1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// RuntimeClassName is the name of the RuntimeClass used to run the operand pods.
+	// If not set, the default container runtime is used.
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// Proxy configures the HTTP proxy environment variables of the operand pods.
+	// If not set, the proxy of the OpenShift cluster is used, if it is configured.
+	// It is also set as the cluster-wide import proxy of CDI, while boot sources are imported.
+	Proxy *v1beta1.Proxy `json:"proxy,omitempty"`
+
+	// TrustedCABundle references a ConfigMap with CA certificates that are trusted
+	// by the operand pods, for example the CA of an internal registry.
+	TrustedCABundle *v1beta1.TrustedCABundle `json:"trustedCABundle,omitempty"`
+}
+
+// Security is the configuration of the TLS servers and certificates of the operands
+type Security struct {
+	// TLSSecurityProfile is a configuration for the TLS servers of the operands.
+	// The template validator uses it from version v0.10.0, older versions
+	// do not support it and mark the template validator as degraded.
+	// If not set, the Intermediate profile is used.
+	TLSSecurityProfile *ocpv1.TLSSecurityProfile `json:"tlsSecurityProfile,omitempty"`
+
+	// CertConfig configures rotation of the certificates of the operand webhooks.
+	// If set, the operator issues and rotates the certificates itself,
+	// otherwise they are provided by the OpenShift service CA operator.
+	// Intervals that are not set are taken from the certificate rotation
+	// strategy of the KubeVirt CR, if it configures them.
+	CertConfig *v1beta1.CertConfig `json:"certConfig,omitempty"`
+
+	// AdmissionPolicies configures ValidatingAdmissionPolicies, that enforce some of the rules
+	// of the SSP webhooks in the API server, so they do not depend on the availability of the operator.
+	AdmissionPolicies *v1beta1.AdmissionPolicies `json:"admissionPolicies,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=kvssp
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Observed Version",type=string,JSONPath=`.status.observedVersion`
+// +kubebuilder:printcolumn:name="Paused",type=boolean,JSONPath=`.status.paused`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SSP is the Schema for the ssps API.
+// It is converted from and to v1beta1, the storage version, by the conversion webhook.
+type SSP struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SSPSpec           `json:"spec,omitempty"`
+	Status v1beta1.SSPStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SSPList contains a list of SSP
+type SSPList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SSP `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SSP{}, &SSPList{})
+}
@@ -0,0 +1,123 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// certificateWatcher loads the certificate and key from a directory again when they change,
+// so the TLS servers use rotated certificates without a restart
+type certificateWatcher struct {
+	dir string
+
+	lock        sync.RWMutex
+	certificate *tls.Certificate
+}
+
+var _ manager.LeaderElectionRunnable = &certificateWatcher{}
+
+// newCertificateWatcher loads the sdkTLSCrt and sdkTLSKey files from the directory
+func newCertificateWatcher(dir string) (*certificateWatcher, error) {
+	w := &certificateWatcher{dir: dir}
+	if _, err := w.loadCertificate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// GetCertificate returns the last successfully loaded certificate, it is used in tls.Config
+func (w *certificateWatcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.certificate, nil
+}
+
+// loadCertificate returns true if the loaded certificate differs from the previous one
+func (w *certificateWatcher) loadCertificate() (bool, error) {
+	certificate, err := tls.LoadX509KeyPair(path.Join(w.dir, sdkTLSCrt), path.Join(w.dir, sdkTLSKey))
+	if err != nil {
+		return false, fmt.Errorf("failed to load certificate from %s: %w", w.dir, err)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	changed := w.certificate == nil || !bytes.Equal(w.certificate.Certificate[0], certificate.Certificate[0])
+	w.certificate = &certificate
+	return changed, nil
+}
+
+// NeedLeaderElection returns false, because the TLS servers run without leader election
+func (w *certificateWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *certificateWatcher) Start(stop <-chan struct{}) error {
+	return watchDirectory(w.dir, stop, func() {
+		// The files may be only partially updated, in which case the previous
+		// certificate is kept until the next event
+		changed, err := w.loadCertificate()
+		if err != nil {
+			setupLog.V(1).Info("Could not reload certificate", "reason", err.Error())
+		} else if changed {
+			setupLog.Info("Certificate changed, reloaded it", "dir", w.dir)
+		}
+	})
+}
+
+// watchDirectory calls onChange for every change in the directory, until the stop channel is closed.
+// The directory is watched instead of the files, because mounted secrets
+// are updated by replacing a symlink in the directory.
+func watchDirectory(dir string, stop <-chan struct{}, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	err = watcher.Add(dir)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			onChange()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			setupLog.Error(err, "Error watching directory", "dir", dir)
+		}
+	}
+}
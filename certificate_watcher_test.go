@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Directory watcher", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "watched-dir")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should call the callback on changes until it is stopped", func() {
+		changes := make(chan struct{}, 10)
+		stop := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- watchDirectory(dir, stop, func() {
+				changes <- struct{}{}
+			})
+		}()
+
+		// The watch is added asynchronously, so the file is written until a change is seen
+		Eventually(func() <-chan struct{} {
+			Expect(ioutil.WriteFile(path.Join(dir, sdkTLSCrt), []byte("certificate"), 0600)).To(Succeed())
+			return changes
+		}).Should(Receive())
+
+		close(stop)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should fail if the directory does not exist", func() {
+		err := watchDirectory(path.Join(dir, "missing"), make(chan struct{}), func() {})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Certificate watcher", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "certificates")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	// writeCertificate writes a new self-signed certificate and its key,
+	// and returns the DER encoded certificate
+	writeCertificate := func(serialNumber int64) []byte {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serialNumber),
+			Subject:      pkix.Name{CommonName: "ssp-operator"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+		keyBytes, err := x509.MarshalECPrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ioutil.WriteFile(path.Join(dir, sdkTLSKey),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(dir, sdkTLSCrt),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)).To(Succeed())
+		return certificate
+	}
+
+	getCertificate := func(w *certificateWatcher) []byte {
+		certificate, err := w.GetCertificate(nil)
+		Expect(err).ToNot(HaveOccurred())
+		return certificate.Certificate[0]
+	}
+
+	It("should load the rotated certificate", func() {
+		first := writeCertificate(1)
+		w, err := newCertificateWatcher(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getCertificate(w)).To(Equal(first))
+
+		second := writeCertificate(2)
+		Expect(w.loadCertificate()).To(BeTrue())
+		Expect(getCertificate(w)).To(Equal(second))
+
+		Expect(w.loadCertificate()).To(BeFalse())
+	})
+
+	It("should keep the previous certificate, if the key does not match", func() {
+		first := writeCertificate(1)
+		w, err := newCertificateWatcher(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		firstCertificate, err := ioutil.ReadFile(path.Join(dir, sdkTLSCrt))
+		Expect(err).ToNot(HaveOccurred())
+		writeCertificate(2)
+		// Only the key was rotated so far
+		Expect(ioutil.WriteFile(path.Join(dir, sdkTLSCrt), firstCertificate, 0600)).To(Succeed())
+
+		_, err = w.loadCertificate()
+		Expect(err).To(HaveOccurred())
+		Expect(getCertificate(w)).To(Equal(first))
+	})
+
+	It("should reload the rotated certificate until it is stopped", func() {
+		writeCertificate(1)
+		w, err := newCertificateWatcher(dir)
+		Expect(err).ToNot(HaveOccurred())
+		stop := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- w.Start(stop)
+		}()
+
+		// The watch is added asynchronously, so the certificate is written until it is loaded
+		Eventually(func() int64 {
+			writeCertificate(2)
+			certificate, err := x509.ParseCertificate(getCertificate(w))
+			Expect(err).ToNot(HaveOccurred())
+			return certificate.SerialNumber.Int64()
+		}).Should(Equal(int64(2)))
+
+		close(stop)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+// getActiveSsp returns the SSP CR reconciled by the operator.
+// Only one SSP CR is supported in the cluster. If there are more,
+// the oldest one is active and the others are rejected.
+func getActiveSsp(ctx context.Context, reader client.Reader) (*ssp.SSP, error) {
+	var ssps ssp.SSPList
+	err := reader.List(ctx, &ssps)
+	if err != nil {
+		return nil, err
+	}
+	if len(ssps.Items) == 0 {
+		return nil, nil
+	}
+
+	active := &ssps.Items[0]
+	for i := range ssps.Items[1:] {
+		if isOlderSsp(&ssps.Items[i+1], active) {
+			active = &ssps.Items[i+1]
+		}
+	}
+	return active, nil
+}
+
+// isOlderSsp returns true if the first SSP CR was created before the second one.
+// CRs created at the same time are ordered by namespace and name.
+func isOlderSsp(a, b *ssp.SSP) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// rejectSsp marks the SSP CR as rejected, because another SSP CR is active
+func (r *SSPReconciler) rejectSsp(ctx context.Context, instance, active *ssp.SSP) error {
+	if isBeingDeleted(instance) {
+		// The resources are shared with the active SSP CR,
+		// so the finalizer is removed without cleanup
+		if controllerutil.ContainsFinalizer(instance, finalizerName) {
+			controllerutil.RemoveFinalizer(instance, finalizerName)
+			return r.Update(ctx, instance)
+		}
+		return nil
+	}
+
+	message := fmt.Sprintf("Only one SSP CR is reconciled, the active SSP CR is %s/%s", active.Namespace, active.Name)
+	condition := conditionsv1.FindStatusCondition(instance.Status.Conditions, ssp.ConditionRejected)
+	if condition != nil && condition.Status == v1.ConditionTrue && condition.Message == message &&
+		instance.Status.ObservedGeneration == instance.Generation {
+		return nil
+	}
+
+	r.Log.Info(fmt.Sprintf("Rejecting SSP CR %s/%s: %s", instance.Namespace, instance.Name, message))
+	if r.Recorder != nil {
+		r.Recorder.Event(instance, v1.EventTypeWarning, RejectedReason, message)
+	}
+	instance.Status.Phase = ssp.PhaseRejected
+	instance.Status.ObservedGeneration = instance.Generation
+	conditionsv1.SetStatusCondition(&instance.Status.Conditions, conditionsv1.Condition{
+		Type:    ssp.ConditionRejected,
+		Status:  v1.ConditionTrue,
+		Reason:  "rejected",
+		Message: message,
+	})
+	return r.Status().Update(ctx, instance)
+}
+
+// watchSspDeletion reconciles all SSP CRs when an SSP CR is removed,
+// so a rejected SSP CR becomes active when the active one is removed
+func watchSspDeletion(bldr *ctrl.Builder, c client.Client) {
+	pred := predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(event.UpdateEvent) bool {
+			return false
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+	bldr.Watches(&source.Kind{Type: &ssp.SSP{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: requestsForAllSSPs(c),
+	}, builder.WithPredicates(pred))
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var _ = Describe("Active SSP", func() {
+	var (
+		ctx      context.Context
+		recorder *record.FakeRecorder
+		created  time.Time
+	)
+
+	newSsp := func(namespace, name string, createdAfter time.Duration) *ssp.SSP {
+		sspObj := newTestSsp(namespace, name)
+		sspObj.CreationTimestamp = metav1.NewTime(created.Add(createdAfter))
+		return sspObj
+	}
+
+	newReconciler := func(objs ...*ssp.SSP) *SSPReconciler {
+		s := newTestScheme()
+		c := fake.NewFakeClientWithScheme(s)
+		for _, obj := range objs {
+			Expect(c.Create(ctx, obj)).To(Succeed())
+		}
+		return &SSPReconciler{
+			Client:   c,
+			Log:      testLog,
+			Scheme:   s,
+			Recorder: recorder,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		created = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("should not return an SSP CR if there is none", func() {
+		active, err := getActiveSsp(ctx, newReconciler())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(active).To(BeNil())
+	})
+
+	It("should choose the oldest SSP CR", func() {
+		r := newReconciler(
+			newSsp("a", "newer", time.Minute),
+			newSsp("b", "oldest", 0),
+			newSsp("c", "newest", time.Hour),
+		)
+		active, err := getActiveSsp(ctx, r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(active.Namespace).To(Equal("b"))
+		Expect(active.Name).To(Equal("oldest"))
+	})
+
+	It("should order SSP CRs created at the same time by namespace and name", func() {
+		Expect(isOlderSsp(newSsp("a", "z", 0), newSsp("b", "a", 0))).To(BeTrue())
+		Expect(isOlderSsp(newSsp("a", "a", 0), newSsp("a", "b", 0))).To(BeTrue())
+		Expect(isOlderSsp(newSsp("b", "a", 0), newSsp("a", "z", 0))).To(BeFalse())
+		Expect(isOlderSsp(newSsp("b", "a", 0), newSsp("a", "z", time.Second))).To(BeTrue())
+	})
+
+	Context("rejected SSP CR", func() {
+		var (
+			r        *SSPReconciler
+			active   *ssp.SSP
+			rejected *ssp.SSP
+		)
+
+		BeforeEach(func() {
+			active = newSsp("a", "active", 0)
+			rejected = newSsp("b", "rejected", time.Minute)
+			r = newReconciler(active, rejected)
+		})
+
+		It("should set the rejected phase and condition", func() {
+			Expect(r.rejectSsp(ctx, rejected, active)).To(Succeed())
+
+			updated := &ssp.SSP{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "b", Name: "rejected"}, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal(ssp.PhaseRejected))
+			condition := conditionsv1.FindStatusCondition(updated.Status.Conditions, ssp.ConditionRejected)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(v1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("a/active"))
+
+			Expect(recorder.Events).To(Receive(ContainSubstring(RejectedReason)))
+		})
+
+		It("should not record the event again, if the SSP CR is already rejected", func() {
+			Expect(r.rejectSsp(ctx, rejected, active)).To(Succeed())
+			Expect(recorder.Events).To(Receive())
+
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "b", Name: "rejected"}, rejected)).To(Succeed())
+			Expect(r.rejectSsp(ctx, rejected, active)).To(Succeed())
+			Expect(recorder.Events).ToNot(Receive())
+		})
+
+		It("should remove the finalizer of a rejected SSP CR that is being deleted", func() {
+			now := metav1.Now()
+			rejected.Finalizers = []string{finalizerName}
+			rejected.DeletionTimestamp = &now
+			Expect(r.Update(ctx, rejected)).To(Succeed())
+
+			Expect(r.rejectSsp(ctx, rejected, active)).To(Succeed())
+
+			updated := &ssp.SSP{}
+			Expect(r.Get(ctx, client.ObjectKey{Namespace: "b", Name: "rejected"}, updated)).To(Succeed())
+			Expect(updated.Finalizers).ToNot(ContainElement(finalizerName))
+			Expect(updated.Status.Phase).ToNot(Equal(ssp.PhaseRejected))
+			Expect(recorder.Events).ToNot(Receive())
+		})
+	})
+})
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/operands"
+)
+
+// openShiftAPI is only served on OpenShift clusters
+var openShiftAPI = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "clusterversions",
+}
+
+// clusterProxyAPI is the API of the OpenShift cluster proxy configuration
+var clusterProxyAPI = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "proxies",
+}
+
+// clusterAPIs are the CRDs and API resources available in the cluster.
+// Some APIs required by operands are not CRDs, for example templates on OpenShift
+// are served by the OpenShift API server, so the served resources are found using discovery.
+type clusterAPIs struct {
+	crds      map[string]struct{}
+	resources map[schema.GroupVersionResource]struct{}
+
+	// failedGroupVersions are the group versions whose discovery failed,
+	// their resources are considered not served
+	failedGroupVersions []string
+}
+
+// listClusterAPIs returns the existing CRDs and the served API resources required by the operands
+func listClusterAPIs(ctx context.Context, reader client.Reader, discoveryClient discovery.DiscoveryInterface) (*clusterAPIs, error) {
+	crds, err := listExistingCRDs(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	required := []schema.GroupVersionResource{openShiftAPI, clusterProxyAPI}
+	for _, operand := range sspOperands {
+		required = append(required, operand.RequiredAPIs()...)
+	}
+	resources, failed := servedResources(discoveryClient, required)
+	return &clusterAPIs{crds: crds, resources: resources, failedGroupVersions: failed}, nil
+}
+
+// servedResources returns the resources served in the group versions of the required resources.
+// A group version whose discovery fails, for example because its aggregated API server is down,
+// does not fail the others. It is returned in the failed group versions.
+func servedResources(discoveryClient discovery.DiscoveryInterface, required []schema.GroupVersionResource) (map[schema.GroupVersionResource]struct{}, []string) {
+	served := map[schema.GroupVersionResource]struct{}{}
+	checked := map[schema.GroupVersion]struct{}{}
+	var failed []string
+	for _, gvr := range required {
+		gv := gvr.GroupVersion()
+		if _, ok := checked[gv]; ok {
+			continue
+		}
+		checked[gv] = struct{}{}
+
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", gv.String(), err))
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			served[gv.WithResource(resource.Name)] = struct{}{}
+		}
+	}
+	return served, failed
+}
+
+// clusterAPIsCache stores the cluster APIs, so the CRDs are not listed and discovery
+// is not called on every reconciliation. It is invalidated when a CRD is created or removed.
+// Results with failed group versions are not stored, so their discovery is retried.
+// It is safe for concurrent use.
+type clusterAPIsCache struct {
+	reader    client.Reader
+	discovery discovery.DiscoveryInterface
+
+	lock sync.Mutex
+	apis *clusterAPIs
+}
+
+func newClusterAPIsCache(reader client.Reader, discoveryClient discovery.DiscoveryInterface) *clusterAPIsCache {
+	return &clusterAPIsCache{
+		reader:    reader,
+		discovery: discoveryClient,
+	}
+}
+
+// get returns the stored cluster APIs, or lists them if they are not stored
+func (c *clusterAPIsCache) get(ctx context.Context) (*clusterAPIs, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.apis != nil {
+		return c.apis, nil
+	}
+	apis, err := listClusterAPIs(ctx, c.reader, c.discovery)
+	if err != nil {
+		return nil, err
+	}
+	if len(apis.failedGroupVersions) == 0 {
+		c.apis = apis
+	}
+	return apis, nil
+}
+
+// invalidate removes the stored cluster APIs, so they are listed again
+func (c *clusterAPIsCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.apis = nil
+}
+
+func (a *clusterAPIs) hasCRD(name string) bool {
+	_, ok := a.crds[name]
+	return ok
+}
+
+// servesKind returns true if the resource of the kind is served by the cluster
+func (a *clusterAPIs) servesKind(gvk schema.GroupVersionKind) bool {
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+	_, ok := a.resources[plural]
+	return ok
+}
+
+// isOpenShift returns true if the cluster is an OpenShift cluster
+func (a *clusterAPIs) isOpenShift() bool {
+	_, ok := a.resources[openShiftAPI]
+	return ok
+}
+
+// platformName returns the name of the detected platform
+func (a *clusterAPIs) platformName() string {
+	if a.isOpenShift() {
+		return "OpenShift"
+	}
+	return "Kubernetes"
+}
+
+// missingAPIs returns the APIs required by the operand, that are not served
+func (a *clusterAPIs) missingAPIs(operand operands.Operand) []string {
+	var missing []string
+	for _, gvr := range operand.RequiredAPIs() {
+		if _, ok := a.resources[gvr]; !ok {
+			missing = append(missing, gvr.Resource+"."+gvr.Group+"/"+gvr.Version)
+		}
+	}
+	return missing
+}
+
+// setOperandMissingAPIsConditions marks the operand as not available,
+// because it is skipped until its APIs are available
+func setOperandMissingAPIsConditions(sspStatus *ssp.SSPStatus, operandName string, platform string, apis []string) {
+	operandStatus := getOperandStatus(sspStatus, operandName)
+	operandStatus.ResourcesReady = ""
+	message := fmt.Sprintf("Operand is not deployed, required APIs are not available on this %s cluster: %s",
+		platform, strings.Join(apis, ", "))
+	conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionAvailable,
+		Status:  v1.ConditionFalse,
+		Reason:  "missingAPIs",
+		Message: message,
+	})
+	conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionProgressing,
+		Status:  v1.ConditionFalse,
+		Reason:  "missingAPIs",
+		Message: message,
+	})
+	conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionDegraded,
+		Status:  v1.ConditionFalse,
+		Reason:  "missingAPIs",
+		Message: message,
+	})
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/operands"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+// fakeDiscovery serves the resources of the configured group versions
+type fakeDiscovery struct {
+	discovery.DiscoveryInterface
+	resources map[string][]metav1.APIResource
+	failing   map[string]bool
+	requested []string
+}
+
+func (f *fakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	f.requested = append(f.requested, groupVersion)
+	if f.failing[groupVersion] {
+		return nil, errors.NewServiceUnavailable("discovery failed")
+	}
+	resources, ok := f.resources[groupVersion]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{}, groupVersion)
+	}
+	return &metav1.APIResourceList{GroupVersion: groupVersion, APIResources: resources}, nil
+}
+
+// fakeOperand requires the configured APIs
+type fakeOperand struct {
+	operands.Operand
+	requiredAPIs []schema.GroupVersionResource
+}
+
+func (f *fakeOperand) RequiredAPIs() []schema.GroupVersionResource {
+	return f.requiredAPIs
+}
+
+var _ = Describe("Cluster APIs", func() {
+	templatesAPI := schema.GroupVersionResource{Group: "template.openshift.io", Version: "v1", Resource: "templates"}
+	processedAPI := templatesAPI.GroupVersion().WithResource("processedtemplates")
+	rulesAPI := schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"}
+
+	It("should find the served resources of the required group versions", func() {
+		discoveryClient := &fakeDiscovery{resources: map[string][]metav1.APIResource{
+			"template.openshift.io/v1": {{Name: "templates"}, {Name: "processedtemplates"}},
+		}}
+
+		served, failed := servedResources(discoveryClient, []schema.GroupVersionResource{templatesAPI, processedAPI, rulesAPI})
+		Expect(failed).To(BeEmpty())
+		Expect(served).To(HaveLen(2))
+		Expect(served).To(HaveKey(templatesAPI))
+		Expect(served).To(HaveKey(processedAPI))
+		Expect(discoveryClient.requested).To(ConsistOf("template.openshift.io/v1", "monitoring.coreos.com/v1"))
+	})
+
+	It("should skip group versions whose discovery fails", func() {
+		discoveryClient := &fakeDiscovery{
+			resources: map[string][]metav1.APIResource{
+				"template.openshift.io/v1": {{Name: "templates"}},
+			},
+			failing: map[string]bool{"monitoring.coreos.com/v1": true},
+		}
+
+		served, failed := servedResources(discoveryClient, []schema.GroupVersionResource{templatesAPI, rulesAPI})
+		Expect(served).To(HaveKey(templatesAPI))
+		Expect(served).ToNot(HaveKey(rulesAPI))
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0]).To(HavePrefix("monitoring.coreos.com/v1: "))
+	})
+
+	Context("cache", func() {
+		var (
+			reader          client.Reader
+			discoveryClient *fakeDiscovery
+		)
+
+		BeforeEach(func() {
+			s := newTestScheme()
+			s.AddKnownTypeWithName(crdGVK, &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(crdGVK.GroupVersion().WithKind(crdGVK.Kind+"List"), &unstructured.UnstructuredList{})
+			reader = fake.NewFakeClientWithScheme(s)
+			discoveryClient = &fakeDiscovery{resources: map[string][]metav1.APIResource{
+				"template.openshift.io/v1": {{Name: "templates"}},
+			}}
+		})
+
+		It("should reuse cluster APIs until invalidated", func() {
+			apisCache := newClusterAPIsCache(reader, discoveryClient)
+			apis, err := apisCache.get(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(apis.resources).To(HaveKey(templatesAPI))
+			requested := len(discoveryClient.requested)
+			Expect(requested).To(BeNumerically(">", 0))
+
+			_, err = apisCache.get(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(discoveryClient.requested).To(HaveLen(requested))
+
+			apisCache.invalidate()
+			_, err = apisCache.get(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(discoveryClient.requested).To(HaveLen(2 * requested))
+		})
+
+		It("should not reuse cluster APIs if discovery failed", func() {
+			discoveryClient.failing = map[string]bool{"template.openshift.io/v1": true}
+			apisCache := newClusterAPIsCache(reader, discoveryClient)
+			apis, err := apisCache.get(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(apis.failedGroupVersions).To(HaveLen(1))
+			Expect(apis.resources).ToNot(HaveKey(templatesAPI))
+
+			discoveryClient.failing = nil
+			apis, err = apisCache.get(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(apis.failedGroupVersions).To(BeEmpty())
+			Expect(apis.resources).To(HaveKey(templatesAPI))
+		})
+	})
+
+	It("should report the required APIs that are not served", func() {
+		apis := &clusterAPIs{resources: map[schema.GroupVersionResource]struct{}{templatesAPI: {}}}
+		operand := &fakeOperand{requiredAPIs: []schema.GroupVersionResource{templatesAPI, rulesAPI}}
+		Expect(apis.missingAPIs(operand)).To(ConsistOf("prometheusrules.monitoring.coreos.com/v1"))
+
+		operand.requiredAPIs = []schema.GroupVersionResource{templatesAPI}
+		Expect(apis.missingAPIs(operand)).To(BeEmpty())
+	})
+
+	It("should mark an operand with missing APIs as not available", func() {
+		sspStatus := &ssp.SSPStatus{}
+		setOperandMissingAPIsConditions(sspStatus, "test-operand", "Kubernetes", []string{"templates.template.openshift.io/v1"})
+
+		operandStatus := getOperandStatus(sspStatus, "test-operand")
+		Expect(conditionsv1.IsStatusConditionFalse(operandStatus.Conditions, conditionsv1.ConditionAvailable)).To(BeTrue())
+		Expect(conditionsv1.IsStatusConditionFalse(operandStatus.Conditions, conditionsv1.ConditionProgressing)).To(BeTrue())
+		Expect(conditionsv1.IsStatusConditionFalse(operandStatus.Conditions, conditionsv1.ConditionDegraded)).To(BeTrue())
+		available := conditionsv1.FindStatusCondition(operandStatus.Conditions, conditionsv1.ConditionAvailable)
+		Expect(available.Reason).To(Equal("missingAPIs"))
+		Expect(available.Message).To(ContainSubstring("templates.template.openshift.io/v1"))
+		Expect(available.Message).To(ContainSubstring("Kubernetes cluster"))
+	})
+
+	It("should detect OpenShift from the served APIs", func() {
+		apis := &clusterAPIs{resources: map[schema.GroupVersionResource]struct{}{}}
+		Expect(apis.isOpenShift()).To(BeFalse())
+		Expect(apis.platformName()).To(Equal("Kubernetes"))
+
+		apis.resources[openShiftAPI] = struct{}{}
+		Expect(apis.isOpenShift()).To(BeTrue())
+		Expect(apis.platformName()).To(Equal("OpenShift"))
+	})
+})
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// Kinds of audit sinks
+const (
+	AuditSinkLog       = "log"
+	AuditSinkEvents    = "events"
+	AuditSinkConfigMap = "configmap"
+)
+
+// AuditEntriesKey is the key of the audit ConfigMap with the recorded changes
+const AuditEntriesKey = "entries"
+
+// AuditConfigMapName returns the name of the ConfigMap with the audit trail of the SSP CR
+func AuditConfigMapName(sspObj *ssp.SSP) string {
+	return sspObj.Name + "-audit"
+}
+
+// AuditSink stores the changes the operator did to objects while reconciling the SSP CR
+type AuditSink interface {
+	Record(ctx context.Context, instance *ssp.SSP, entries []common.AuditEntry) error
+}
+
+// NewAuditSink creates an audit sink of the given kind
+func NewAuditSink(kind string, c client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, logger logr.Logger, maxEntries int) (AuditSink, error) {
+	switch kind {
+	case AuditSinkLog:
+		return &logAuditSink{logger: logger}, nil
+	case AuditSinkEvents:
+		return &eventAuditSink{recorder: recorder}, nil
+	case AuditSinkConfigMap:
+		return &configMapAuditSink{client: c, scheme: scheme, maxEntries: maxEntries}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink: %s", kind)
+	}
+}
+
+// logAuditSink writes every change as a structured log message
+type logAuditSink struct {
+	logger logr.Logger
+}
+
+func (s *logAuditSink) Record(_ context.Context, instance *ssp.SSP, entries []common.AuditEntry) error {
+	for _, entry := range entries {
+		s.logger.Info("Resource changed",
+			"ssp", fmt.Sprintf("%s/%s", instance.Namespace, instance.Name),
+			"reconcileID", entry.ReconcileID,
+			"verb", entry.Operation,
+			"kind", entry.Kind,
+			"namespace", entry.Namespace,
+			"name", entry.Name,
+			"fields", entry.Fields)
+	}
+	return nil
+}
+
+// eventAuditSink records an event on the SSP CR for every change
+type eventAuditSink struct {
+	recorder record.EventRecorder
+}
+
+func (s *eventAuditSink) Record(_ context.Context, instance *ssp.SSP, entries []common.AuditEntry) error {
+	for _, entry := range entries {
+		s.recorder.Eventf(instance, v1.EventTypeNormal, ResourceChangedReason,
+			"Reconcile %s: %s", entry.ReconcileID, entry.Change.String())
+	}
+	return nil
+}
+
+// configMapAuditSink appends the changes to a ConfigMap in the namespace of the SSP CR.
+// Only the latest changes are kept.
+type configMapAuditSink struct {
+	client     client.Client
+	scheme     *runtime.Scheme
+	maxEntries int
+}
+
+func (s *configMapAuditSink) Record(ctx context.Context, instance *ssp.SSP, entries []common.AuditEntry) error {
+	if isBeingDeleted(instance) {
+		// The ConfigMap is owned by the SSP CR and is removed with it
+		return nil
+	}
+
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      AuditConfigMapName(instance),
+		Namespace: instance.Namespace,
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, s.client, configMap, func() error {
+		var lines []string
+		if existing := configMap.Data[AuditEntriesKey]; existing != "" {
+			lines = strings.Split(existing, "\n")
+		}
+		for _, entry := range entries {
+			lines = append(lines, entry.String())
+		}
+		if len(lines) > s.maxEntries {
+			lines = lines[len(lines)-s.maxEntries:]
+		}
+		configMap.Data = map[string]string{
+			AuditEntriesKey: strings.Join(lines, "\n"),
+		}
+		return controllerutil.SetControllerReference(instance, configMap, s.scheme)
+	})
+	return err
+}
+
+// recordAudit passes the changes done during the reconciliation to the audit sink
+func (r *SSPReconciler) recordAudit(request *common.Request, auditClient *common.AuditClient) {
+	entries := auditClient.Entries()
+	if len(entries) == 0 {
+		return
+	}
+	err := r.AuditSink.Record(request.Context, request.Instance, entries)
+	if err != nil {
+		request.Logger.Error(err, "Error recording audit entries")
+	}
+}
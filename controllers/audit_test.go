@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var _ = Describe("Audit sinks", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		recorder *record.FakeRecorder
+		instance *ssp.SSP
+	)
+
+	newEntries := func(prefix string, count int) []common.AuditEntry {
+		entries := make([]common.AuditEntry, 0, count)
+		for i := 0; i < count; i++ {
+			entries = append(entries, common.AuditEntry{
+				Change: common.Change{
+					Operation: "create",
+					Kind:      "ConfigMap",
+					Namespace: testNamespace,
+					Name:      fmt.Sprintf("%s-%d", prefix, i),
+				},
+				Time:        time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				ReconcileID: prefix,
+			})
+		}
+		return entries
+	}
+
+	newSink := func(kind string, maxEntries int) AuditSink {
+		sink, err := NewAuditSink(kind, c, newTestScheme(), recorder, testLog, maxEntries)
+		Expect(err).ToNot(HaveOccurred())
+		return sink
+	}
+
+	getLines := func() []string {
+		configMap := &v1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: AuditConfigMapName(instance)}, configMap)).To(Succeed())
+		return strings.Split(configMap.Data[AuditEntriesKey], "\n")
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		instance = newTestSsp(testNamespace, testName)
+		c = fake.NewFakeClientWithScheme(newTestScheme(), instance)
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	It("should fail for an unknown sink", func() {
+		_, err := NewAuditSink("unknown", c, newTestScheme(), recorder, testLog, 10)
+		Expect(err).To(MatchError(ContainSubstring("unknown audit sink")))
+	})
+
+	It("should record an event for every entry", func() {
+		Expect(newSink(AuditSinkEvents, 10).Record(ctx, instance, newEntries("first", 2))).To(Succeed())
+		Expect(recorder.Events).To(Receive(And(ContainSubstring(ResourceChangedReason), ContainSubstring("first-0"))))
+		Expect(recorder.Events).To(Receive(ContainSubstring("first-1")))
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	It("should record entries in the audit ConfigMap", func() {
+		sink := newSink(AuditSinkConfigMap, 10)
+		Expect(sink.Record(ctx, instance, newEntries("first", 2))).To(Succeed())
+		Expect(sink.Record(ctx, instance, newEntries("second", 1))).To(Succeed())
+
+		lines := getLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(ContainSubstring("first-0"))
+		Expect(lines[2]).To(ContainSubstring("second-0"))
+	})
+
+	It("should keep only the latest entries in the audit ConfigMap", func() {
+		sink := newSink(AuditSinkConfigMap, 3)
+		Expect(sink.Record(ctx, instance, newEntries("first", 2))).To(Succeed())
+		Expect(sink.Record(ctx, instance, newEntries("second", 2))).To(Succeed())
+
+		lines := getLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(ContainSubstring("first-1"))
+		Expect(lines[2]).To(ContainSubstring("second-1"))
+	})
+
+	It("should not create the audit ConfigMap while the SSP CR is being deleted", func() {
+		now := metav1.Now()
+		instance.DeletionTimestamp = &now
+		Expect(newSink(AuditSinkConfigMap, 10).Record(ctx, instance, newEntries("first", 1))).To(Succeed())
+
+		configMap := &v1.ConfigMap{}
+		err := c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: AuditConfigMapName(instance)}, configMap)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ocpv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+const (
+	testNamespace = "kubevirt"
+	testName      = "test-ssp"
+)
+
+var testLog = logf.Log.WithName("controllers_test")
+
+// newTestScheme returns a new scheme, so the tests do not modify the global one
+func newTestScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+	Expect(ssp.AddToScheme(s)).To(Succeed())
+	Expect(ocpv1.AddToScheme(s)).To(Succeed())
+	return s
+}
+
+func newTestSsp(namespace, name string) *ssp.SSP {
+	return &ssp.SSP{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "SSP",
+			APIVersion: ssp.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(namespace + "-" + name),
+		},
+	}
+}
+
+func newTestRequest(c client.Client, s *runtime.Scheme, instance *ssp.SSP, recorder record.EventRecorder) *common.Request {
+	return &common.Request{
+		Request: reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: instance.Namespace,
+				Name:      instance.Name,
+			},
+		},
+		Client:       c,
+		Scheme:       s,
+		Context:      context.Background(),
+		Instance:     instance,
+		Logger:       testLog,
+		Recorder:     recorder,
+		VersionCache: common.NewVersionCache(),
+	}
+}
+
+func TestControllers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Controllers Suite")
+}
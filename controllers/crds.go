@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	ocpv1 "github.com/openshift/api/config/v1"
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+var crdGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// crdName returns the name of the CRD defining the kind
+func crdName(gvk schema.GroupVersionKind) string {
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+	return plural.Resource + "." + gvk.Group
+}
+
+// listExistingCRDs returns the names of all CRDs in the cluster
+func listExistingCRDs(ctx context.Context, reader client.Reader) (map[string]struct{}, error) {
+	crds := &unstructured.UnstructuredList{}
+	crds.SetGroupVersionKind(crdGVK.GroupVersion().WithKind(crdGVK.Kind + "List"))
+	err := reader.List(ctx, crds)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(crds.Items))
+	for _, item := range crds.Items {
+		names[item.GetName()] = struct{}{}
+	}
+	return names, nil
+}
+
+// watchCRDs reconciles all SSP CRs when a CRD is created or removed,
+// so operands are deployed once their APIs are installed.
+// The cluster APIs cache is invalidated before the reconciliation is queued.
+func watchCRDs(bldr *ctrl.Builder, c client.Client, apisCache *clusterAPIsCache) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	// CRD updates do not change the served resources
+	pred := predicate.Funcs{UpdateFunc: func(event.UpdateEvent) bool {
+		return false
+	}}
+	toRequests := requestsForAllSSPs(c)
+	bldr.Watches(&source.Kind{Type: crd}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []ctrl.Request {
+			apisCache.invalidate()
+			return toRequests(obj)
+		}),
+	}, builder.WithPredicates(pred))
+}
+
+// requestsForAllSSPs maps any object to the reconcile requests of all SSP CRs
+func requestsForAllSSPs(c client.Client) handler.ToRequestsFunc {
+	return func(handler.MapObject) []ctrl.Request {
+		var ssps ssp.SSPList
+		err := c.List(context.TODO(), &ssps)
+		if err != nil {
+			return nil
+		}
+
+		requests := make([]ctrl.Request, 0, len(ssps.Items))
+		for _, sspObj := range ssps.Items {
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: sspObj.Namespace,
+				Name:      sspObj.Name,
+			}})
+		}
+		return requests
+	}
+}
+
+// watchOperandResources starts watching the resources of operands, whose APIs are available.
+// Types that are already watched are skipped, so it can be called on every reconciliation.
+func (r *SSPReconciler) watchOperandResources(apis *clusterAPIs) error {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+
+	if r.watchedTypes == nil {
+		r.watchedTypes = map[watchedType]struct{}{}
+	}
+	err := r.watchKubeVirt(apis)
+	if err != nil {
+		return err
+	}
+	err = r.watchClusterProxy(apis)
+	if err != nil {
+		return err
+	}
+	for _, operand := range sspOperands {
+		if len(apis.missingAPIs(operand)) > 0 {
+			continue
+		}
+		err := r.watchTypes(operand.WatchTypes(), false, apis, func() handler.EventHandler {
+			return &handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &ssp.SSP{},
+			}
+		})
+		if err != nil {
+			return err
+		}
+		err = r.watchTypes(operand.WatchClusterTypes(), true, apis, func() handler.EventHandler {
+			return &libhandler.EnqueueRequestForAnnotation{
+				Type: schema.GroupKind{
+					Group: "ssp.kubevirt.io",
+					Kind:  "SSP",
+				},
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchKubeVirt reconciles all SSP CRs when the spec of a KubeVirt CR changes,
+// so the operands are updated with the KubeVirt settings
+func (r *SSPReconciler) watchKubeVirt(apis *clusterAPIs) error {
+	if !apis.hasCRD(common.KubeVirtCRD) || r.kubeVirtWatched {
+		return nil
+	}
+	kubevirt := &unstructured.Unstructured{}
+	kubevirt.SetGroupVersionKind(common.KubeVirtGVK)
+	err := r.controller.Watch(&source.Kind{Type: kubevirt},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: requestsForAllSSPs(r.Client)},
+		predicate.GenerationChangedPredicate{})
+	if err != nil {
+		return err
+	}
+	r.kubeVirtWatched = true
+	return nil
+}
+
+// watchClusterProxy reconciles all SSP CRs when the OpenShift cluster proxy changes,
+// so the operand pods are updated with the proxy settings
+func (r *SSPReconciler) watchClusterProxy(apis *clusterAPIs) error {
+	if _, ok := apis.resources[clusterProxyAPI]; !ok || r.clusterProxyWatched {
+		return nil
+	}
+	// The proxy settings are read from the status, which does not change the generation
+	pred := predicate.NewPredicateFuncs(func(obj metav1.Object, _ runtime.Object) bool {
+		return obj.GetName() == clusterProxyName
+	})
+	err := r.controller.Watch(&source.Kind{Type: &ocpv1.Proxy{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: requestsForAllSSPs(r.Client)},
+		pred)
+	if err != nil {
+		return err
+	}
+	r.clusterProxyWatched = true
+	return nil
+}
+
+type watchedType struct {
+	objType   reflect.Type
+	gvk       schema.GroupVersionKind
+	isCluster bool
+}
+
+// watchTypes starts watching the types that are not watched yet.
+// Unstructured types are optional operand APIs, they are watched once their CRD exists
+// or, for built-in APIs, once the API is served.
+func (r *SSPReconciler) watchTypes(objs []runtime.Object, isCluster bool, apis *clusterAPIs, newHandler func() handler.EventHandler) error {
+	for _, obj := range objs {
+		key := watchedType{objType: reflect.TypeOf(obj), isCluster: isCluster}
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			key.gvk = u.GroupVersionKind()
+			if !apis.hasCRD(crdName(key.gvk)) && !apis.servesKind(key.gvk) {
+				continue
+			}
+		}
+		if _, ok := r.watchedTypes[key]; ok {
+			continue
+		}
+		err := r.controller.Watch(&source.Kind{Type: obj}, newHandler())
+		if err != nil {
+			return err
+		}
+		r.watchedTypes[key] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ocpv1 "github.com/openshift/api/config/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"kubevirt.io/ssp-operator/internal/common"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+// fakeController records the watched types
+type fakeController struct {
+	controller.Controller
+	watched []runtime.Object
+}
+
+func (f *fakeController) Watch(src source.Source, _ handler.EventHandler, _ ...predicate.Predicate) error {
+	f.watched = append(f.watched, src.(*source.Kind).Type)
+	return nil
+}
+
+var _ = Describe("Dynamic watches", func() {
+	var (
+		r    *SSPReconciler
+		ctrl *fakeController
+		apis *clusterAPIs
+	)
+
+	testGVK := schema.GroupVersionKind{Group: "test.kubevirt.io", Version: "v1", Kind: "Test"}
+
+	newUnstructured := func(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		return obj
+	}
+
+	newHandler := func() handler.EventHandler {
+		return &handler.EnqueueRequestForObject{}
+	}
+
+	BeforeEach(func() {
+		ctrl = &fakeController{}
+		r = &SSPReconciler{
+			Client:       fake.NewFakeClientWithScheme(newTestScheme()),
+			controller:   ctrl,
+			watchedTypes: map[watchedType]struct{}{},
+		}
+		apis = &clusterAPIs{
+			crds:      map[string]struct{}{},
+			resources: map[schema.GroupVersionResource]struct{}{},
+		}
+	})
+
+	It("should watch typed objects only once", func() {
+		objs := []runtime.Object{&v1.ConfigMap{}, &v1.Service{}}
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(2))
+	})
+
+	It("should watch the same type separately for namespaced and cluster handlers", func() {
+		objs := []runtime.Object{&v1.ConfigMap{}}
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(r.watchTypes(objs, true, apis, newHandler)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(2))
+	})
+
+	It("should watch an optional API once its CRD exists", func() {
+		objs := []runtime.Object{newUnstructured(testGVK)}
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(ctrl.watched).To(BeEmpty())
+
+		apis.crds[crdName(testGVK)] = struct{}{}
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(1))
+	})
+
+	It("should watch an optional API once it is served", func() {
+		objs := []runtime.Object{newUnstructured(testGVK)}
+		apis.resources[testGVK.GroupVersion().WithResource("tests")] = struct{}{}
+		Expect(r.watchTypes(objs, false, apis, newHandler)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(1))
+	})
+
+	It("should watch KubeVirt once its CRD exists", func() {
+		Expect(r.watchKubeVirt(apis)).To(Succeed())
+		Expect(ctrl.watched).To(BeEmpty())
+
+		apis.crds[common.KubeVirtCRD] = struct{}{}
+		Expect(r.watchKubeVirt(apis)).To(Succeed())
+		Expect(r.watchKubeVirt(apis)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(1))
+		Expect(ctrl.watched[0].GetObjectKind().GroupVersionKind()).To(Equal(common.KubeVirtGVK))
+	})
+
+	It("should watch the cluster proxy once its API is served", func() {
+		Expect(r.watchClusterProxy(apis)).To(Succeed())
+		Expect(ctrl.watched).To(BeEmpty())
+
+		apis.resources[clusterProxyAPI] = struct{}{}
+		Expect(r.watchClusterProxy(apis)).To(Succeed())
+		Expect(r.watchClusterProxy(apis)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(1))
+		Expect(ctrl.watched[0]).To(BeAssignableToTypeOf(&ocpv1.Proxy{}))
+	})
+
+	It("should not watch operand resources again", func() {
+		Expect(r.watchOperandResources(apis)).To(Succeed())
+		watched := len(ctrl.watched)
+		Expect(watched).ToNot(BeZero())
+
+		Expect(r.watchOperandResources(apis)).To(Succeed())
+		Expect(ctrl.watched).To(HaveLen(watched))
+	})
+
+	It("should watch resources of operands once their APIs are served", func() {
+		Expect(r.watchOperandResources(apis)).To(Succeed())
+		watched := len(ctrl.watched)
+
+		for _, operand := range sspOperands {
+			for _, gvr := range operand.RequiredAPIs() {
+				apis.resources[gvr] = struct{}{}
+			}
+		}
+		Expect(r.watchOperandResources(apis)).To(Succeed())
+		Expect(len(ctrl.watched)).To(BeNumerically(">", watched))
+	})
+
+	It("should map objects to all SSP CRs", func() {
+		c := fake.NewFakeClientWithScheme(newTestScheme(),
+			newTestSsp("a", "first"),
+			newTestSsp("b", "second"),
+		)
+		requests := requestsForAllSSPs(c)(handler.MapObject{})
+		Expect(requests).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "a", Name: "first"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "b", Name: "second"}},
+		))
+	})
+})
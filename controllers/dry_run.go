@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// Keys of the dry-run ConfigMap
+const (
+	DryRunChangesKey    = "changes"
+	DryRunErrorKey      = "error"
+	DryRunGenerationKey = "observedGeneration"
+)
+
+// DryRunConfigMapName returns the name of the ConfigMap with the dry-run changes of the SSP CR
+func DryRunConfigMapName(sspObj *ssp.SSP) string {
+	return sspObj.Name + "-dry-run"
+}
+
+func isDryRun(instance *ssp.SSP) bool {
+	dryRunStr, ok := instance.GetAnnotations()[ssp.DryRunAnnotation]
+	if !ok {
+		return false
+	}
+	dryRun, err := strconv.ParseBool(dryRunStr)
+	if err != nil {
+		return false
+	}
+	return dryRun
+}
+
+// dryRun reconciles the operands with a client that does not modify the cluster,
+// and writes the changes that would be done to the dry-run ConfigMap.
+// The status of the SSP CR is not modified.
+func dryRun(request *common.Request, apis *clusterAPIs, failureThreshold int) error {
+	dryRunClient := common.NewDryRunClient(request.Client, request.Scheme)
+	dryRunRequest := *request
+	dryRunRequest.Client = dryRunClient
+	dryRunRequest.Instance = request.Instance.DeepCopy()
+	// Events would report changes that are not done
+	dryRunRequest.Recorder = &record.FakeRecorder{}
+	// Dry-run results are not stored, so they must not be cached
+	dryRunRequest.VersionCache = common.NewVersionCache()
+
+	_, reconcileErr := reconcileOperands(&dryRunRequest, apis, failureThreshold)
+
+	changes := dryRunClient.Changes()
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		lines = append(lines, change.String())
+	}
+
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      DryRunConfigMapName(request.Instance),
+		Namespace: request.Instance.Namespace,
+	}}
+	_, err := controllerutil.CreateOrUpdate(request.Context, request.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			DryRunChangesKey:    strings.Join(lines, "\n"),
+			DryRunGenerationKey: strconv.FormatInt(request.Instance.Generation, 10),
+		}
+		if reconcileErr != nil {
+			configMap.Data[DryRunErrorKey] = reconcileErr.Error()
+		}
+		return controllerutil.SetControllerReference(request.Instance, configMap, request.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	if reconcileErr != nil {
+		request.Logger.Info(fmt.Sprintf("Dry-run of operand reconciliation failed: %v", reconcileErr))
+	}
+	request.Logger.Info(fmt.Sprintf("Dry-run completed, found %d changes", len(changes)))
+	recordDryRunCompletedEvent(request, len(changes), configMap.Name)
+	return nil
+}
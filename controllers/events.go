@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	admission "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// Reasons of the events recorded on the SSP CR
+const (
+	ReconcileFailedReason      = "ReconcileFailed"
+	UpgradeStartedReason       = "UpgradeStarted"
+	UpgradeCompletedReason     = "UpgradeCompleted"
+	WebhookConfigCreatedReason = "WebhookConfigurationCreated"
+	WebhookConfigUpdatedReason = "WebhookConfigurationUpdated"
+	DryRunCompletedReason      = "DryRunCompleted"
+	RejectedReason             = "Rejected"
+	RolledBackReason           = "RolledBack"
+	RollbackFailedReason       = "RollbackFailed"
+	ResourceChangedReason      = "ResourceChanged"
+)
+
+// recordEventf records an event on the SSP CR. Nothing is recorded if the request has no recorder.
+func recordEventf(request *common.Request, eventType, reason, messageFmt string, args ...interface{}) {
+	if request.Recorder != nil {
+		request.Recorder.Eventf(request.Instance, eventType, reason, messageFmt, args...)
+	}
+}
+
+func recordReconcileFailedEvent(request *common.Request, err error) {
+	recordEventf(request, v1.EventTypeWarning, ReconcileFailedReason,
+		"Failed to reconcile SSP resources: %v", err)
+}
+
+// recordUpgradeStartedEvent records an event, if the operator starts
+// to reconcile operands that were deployed by a different version
+func recordUpgradeStartedEvent(request *common.Request, operatorVersion string) {
+	sspStatus := &request.Instance.Status
+	if sspStatus.ObservedVersion == "" || sspStatus.ObservedVersion == operatorVersion ||
+		sspStatus.TargetVersion == operatorVersion {
+		return
+	}
+	recordEventf(request, v1.EventTypeNormal, UpgradeStartedReason,
+		"Upgrading operands from version %s to %s", sspStatus.ObservedVersion, operatorVersion)
+}
+
+// recordUpgradeCompletedEvent records an event, if all operands
+// are deployed by the operator version after an upgrade
+func recordUpgradeCompletedEvent(request *common.Request, operatorVersion string) {
+	observedVersion := request.Instance.Status.ObservedVersion
+	if observedVersion == "" || observedVersion == operatorVersion {
+		return
+	}
+	recordEventf(request, v1.EventTypeNormal, UpgradeCompletedReason,
+		"Upgraded operands from version %s to %s", observedVersion, operatorVersion)
+}
+
+// recordWebhookConfigEvents records an event for every created or updated webhook configuration
+func recordWebhookConfigEvents(request *common.Request, statuses []common.ResourceStatus) {
+	for _, status := range statuses {
+		webhookConf, ok := status.Resource.(*admission.ValidatingWebhookConfiguration)
+		if !ok {
+			continue
+		}
+		switch status.Operation {
+		case controllerutil.OperationResultCreated:
+			recordEventf(request, v1.EventTypeNormal, WebhookConfigCreatedReason,
+				"Created validating webhook configuration %s", webhookConf.GetName())
+		case controllerutil.OperationResultUpdated:
+			recordEventf(request, v1.EventTypeNormal, WebhookConfigUpdatedReason,
+				"Updated validating webhook configuration %s", webhookConf.GetName())
+		}
+	}
+}
+
+// recordDryRunCompletedEvent records an event with the number of changes found by a dry-run
+func recordDryRunCompletedEvent(request *common.Request, changes int, configMapName string) {
+	recordEventf(request, v1.EventTypeNormal, DryRunCompletedReason,
+		"Dry-run found %d changes to SSP resources, see ConfigMap %s", changes, configMapName)
+}
+
+// recordRolledBackEvent records an event when the last successfully deployed spec is restored
+func recordRolledBackEvent(request *common.Request, deployedByVersion string) {
+	recordEventf(request, v1.EventTypeNormal, RolledBackReason,
+		"Restored the spec deployed by operator version %s", deployedByVersion)
+}
+
+func recordRollbackFailedEvent(request *common.Request, message string) {
+	recordEventf(request, v1.EventTypeWarning, RollbackFailedReason,
+		"Failed to roll back the SSP CR: %s", message)
+}
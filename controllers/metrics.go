@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// pausedGauge is 1 while the reconciliation of the SSP CR is paused
+var pausedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "kubevirt_ssp_operator_reconcile_paused",
+	Help: "Indicates whether the reconciliation of the SSP CR is paused (1) or not (0)",
+})
+
+func init() {
+	metrics.Registry.MustRegister(pausedGauge)
+}
+
+func setPausedMetric(paused bool) {
+	if paused {
+		pausedGauge.Set(1)
+	} else {
+		pausedGauge.Set(0)
+	}
+}
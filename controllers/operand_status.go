@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/reference"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// setOperandConditions sets the conditions of the operand in the SSP status
+// from the statuses of its resources. The version is set when all resources are ready.
+func setOperandConditions(sspStatus *ssp.SSPStatus, operandName string, version string, statuses []common.ResourceStatus) {
+	operandStatus := getOperandStatus(sspStatus, operandName)
+	if setResourceConditions(&operandStatus.Conditions, statuses, operandName+" resources") {
+		operandStatus.Version = version
+	}
+	operandStatus.ResourcesReady = resourcesReady(statuses)
+	operandStatus.ConsecutiveFailures = 0
+	operandStatus.FailingSince = nil
+}
+
+// resourcesReady returns the number of ready resources out of all resources.
+// Removed resources are not counted.
+func resourcesReady(statuses []common.ResourceStatus) string {
+	ready := 0
+	total := 0
+	for _, status := range statuses {
+		if status.Removed {
+			continue
+		}
+		total++
+		if status.NotAvailable == nil && status.Progressing == nil && status.Degraded == nil {
+			ready++
+		}
+	}
+	return fmt.Sprintf("%d/%d", ready, total)
+}
+
+// setOperandsReady sets the number of ready operands in the SSP status
+func setOperandsReady(sspStatus *ssp.SSPStatus) {
+	ready := 0
+	for _, operandStatus := range sspStatus.Operands {
+		if conditionsv1.IsStatusConditionTrue(operandStatus.Conditions, conditionsv1.ConditionAvailable) &&
+			!conditionsv1.IsStatusConditionTrue(operandStatus.Conditions, conditionsv1.ConditionProgressing) &&
+			!conditionsv1.IsStatusConditionTrue(operandStatus.Conditions, conditionsv1.ConditionDegraded) {
+			ready++
+		}
+	}
+	sspStatus.OperandsReady = fmt.Sprintf("%d/%d", ready, len(sspStatus.Operands))
+}
+
+// setOperandErrorConditions counts the failure of the operand reconciliation.
+// The operand is marked as not available and degraded once the number of consecutive
+// failures reaches the threshold, before that it is only marked as progressing.
+func setOperandErrorConditions(sspStatus *ssp.SSPStatus, operandName string, err error, failureThreshold int) {
+	operandStatus := getOperandStatus(sspStatus, operandName)
+	operandStatus.ResourcesReady = ""
+	operandStatus.ConsecutiveFailures++
+	if operandStatus.FailingSince == nil {
+		now := metav1.Now()
+		operandStatus.FailingSince = &now
+	}
+
+	if int(operandStatus.ConsecutiveFailures) < failureThreshold {
+		conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+			Type:    conditionsv1.ConditionProgressing,
+			Status:  v1.ConditionTrue,
+			Reason:  "progressing",
+			Message: fmt.Sprintf("Retrying after error: %v", err),
+		})
+		return
+	}
+
+	errorMsg := fmt.Sprintf("Error: %v", err)
+	if operandStatus.ConsecutiveFailures > 1 {
+		errorMsg = fmt.Sprintf("Failed %d consecutive times since %s: %v", operandStatus.ConsecutiveFailures,
+			operandStatus.FailingSince.UTC().Format(time.RFC3339), err)
+	}
+	conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionAvailable,
+		Status:  v1.ConditionFalse,
+		Reason:  "available",
+		Message: errorMsg,
+	})
+	conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionProgressing,
+		Status:  v1.ConditionTrue,
+		Reason:  "progressing",
+		Message: errorMsg,
+	})
+	conditionsv1.SetStatusCondition(&operandStatus.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionDegraded,
+		Status:  v1.ConditionTrue,
+		Reason:  "degraded",
+		Message: errorMsg,
+	})
+}
+
+// consecutiveFailures returns the highest number of consecutive failures of the operands
+func consecutiveFailures(sspStatus *ssp.SSPStatus) int {
+	result := 0
+	for _, operandStatus := range sspStatus.Operands {
+		if int(operandStatus.ConsecutiveFailures) > result {
+			result = int(operandStatus.ConsecutiveFailures)
+		}
+	}
+	return result
+}
+
+// setOperandLastError stores the error of the operand reconciliation in the SSP status,
+// with a reference to the resource that failed, if the error is related to one
+func setOperandLastError(request *common.Request, operandName string, err error) {
+	operandError := &ssp.OperandError{
+		Message: err.Error(),
+		Time:    metav1.Now(),
+	}
+	var resourceErr *common.ResourceError
+	if errors.As(err, &resourceErr) {
+		ref, refErr := reference.GetReference(request.Scheme, resourceErr.Resource)
+		if refErr != nil {
+			request.Logger.Error(refErr, "Failed to get reference of the failed resource")
+		} else {
+			// Only the identity of the object is stored, not its version
+			ref.UID = ""
+			ref.ResourceVersion = ""
+			operandError.Object = ref
+		}
+	}
+	getOperandStatus(&request.Instance.Status, operandName).LastError = operandError
+}
+
+// removeOperandStatus removes the status of a disabled operand
+func removeOperandStatus(sspStatus *ssp.SSPStatus, operandName string) {
+	for i := range sspStatus.Operands {
+		if sspStatus.Operands[i].Name == operandName {
+			sspStatus.Operands = append(sspStatus.Operands[:i], sspStatus.Operands[i+1:]...)
+			return
+		}
+	}
+}
+
+// getOperandStatus returns the status of the operand, it is added to the SSP status if missing
+func getOperandStatus(sspStatus *ssp.SSPStatus, operandName string) *ssp.OperandStatus {
+	for i := range sspStatus.Operands {
+		if sspStatus.Operands[i].Name == operandName {
+			return &sspStatus.Operands[i]
+		}
+	}
+	sspStatus.Operands = append(sspStatus.Operands, ssp.OperandStatus{Name: operandName})
+	return &sspStatus.Operands[len(sspStatus.Operands)-1]
+}
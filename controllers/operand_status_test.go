@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var _ = Describe("Degraded threshold", func() {
+	const (
+		operandName = "test-operand"
+		threshold   = 3
+	)
+
+	var (
+		request  *common.Request
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		s := newTestScheme()
+		instance := newTestSsp(testNamespace, testName)
+		recorder = record.NewFakeRecorder(10)
+		request = newTestRequest(fake.NewFakeClientWithScheme(s, instance), s, instance, recorder)
+	})
+
+	failOperand := func(times int) {
+		for i := 0; i < times; i++ {
+			setOperandErrorConditions(&request.Instance.Status, operandName, errors.New("test error"), threshold)
+		}
+	}
+
+	It("should only mark the operand as progressing before the threshold", func() {
+		failOperand(threshold - 1)
+
+		operandStatus := getOperandStatus(&request.Instance.Status, operandName)
+		Expect(operandStatus.ConsecutiveFailures).To(BeEquivalentTo(threshold - 1))
+		Expect(operandStatus.FailingSince).ToNot(BeNil())
+		Expect(conditionsv1.IsStatusConditionTrue(operandStatus.Conditions, conditionsv1.ConditionProgressing)).To(BeTrue())
+		Expect(conditionsv1.FindStatusCondition(operandStatus.Conditions, conditionsv1.ConditionDegraded)).To(BeNil())
+		Expect(conditionsv1.FindStatusCondition(operandStatus.Conditions, conditionsv1.ConditionAvailable)).To(BeNil())
+	})
+
+	It("should mark the operand as degraded when the threshold is reached", func() {
+		failOperand(threshold)
+
+		operandStatus := getOperandStatus(&request.Instance.Status, operandName)
+		Expect(conditionsv1.IsStatusConditionTrue(operandStatus.Conditions, conditionsv1.ConditionDegraded)).To(BeTrue())
+		Expect(conditionsv1.IsStatusConditionFalse(operandStatus.Conditions, conditionsv1.ConditionAvailable)).To(BeTrue())
+		degraded := conditionsv1.FindStatusCondition(operandStatus.Conditions, conditionsv1.ConditionDegraded)
+		Expect(degraded.Message).To(ContainSubstring("Failed 3 consecutive times"))
+	})
+
+	It("should reset the failures when the operand is reconciled", func() {
+		failOperand(threshold)
+		setOperandConditions(&request.Instance.Status, operandName, "v1", nil)
+
+		operandStatus := getOperandStatus(&request.Instance.Status, operandName)
+		Expect(operandStatus.ConsecutiveFailures).To(BeZero())
+		Expect(operandStatus.FailingSince).To(BeNil())
+	})
+
+	It("should not mark the SSP CR as degraded before the threshold", func() {
+		failOperand(threshold - 1)
+
+		_, err := handleError(request, errors.New("test error"), threshold)
+		Expect(err).To(HaveOccurred())
+
+		sspStatus := request.Instance.Status
+		Expect(sspStatus.Phase).ToNot(Equal(lifecycleapi.PhaseError))
+		Expect(conditionsv1.IsStatusConditionTrue(sspStatus.Conditions, conditionsv1.ConditionProgressing)).To(BeTrue())
+		Expect(conditionsv1.FindStatusCondition(sspStatus.Conditions, conditionsv1.ConditionDegraded)).To(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring(ReconcileFailedReason)))
+	})
+
+	It("should mark the SSP CR as degraded when the threshold is reached", func() {
+		failOperand(threshold)
+
+		_, err := handleError(request, errors.New("test error"), threshold)
+		Expect(err).To(HaveOccurred())
+
+		sspStatus := request.Instance.Status
+		Expect(sspStatus.Phase).To(Equal(lifecycleapi.PhaseError))
+		Expect(conditionsv1.IsStatusConditionTrue(sspStatus.Conditions, conditionsv1.ConditionDegraded)).To(BeTrue())
+		Expect(conditionsv1.IsStatusConditionFalse(sspStatus.Conditions, conditionsv1.ConditionAvailable)).To(BeTrue())
+
+		updated := &ssp.SSP{}
+		Expect(request.Client.Get(request.Context, request.NamespacedName, updated)).To(Succeed())
+		Expect(conditionsv1.IsStatusConditionTrue(updated.Status.Conditions, conditionsv1.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should mark the SSP CR as degraded without an event recorder", func() {
+		request.Recorder = nil
+		failOperand(threshold)
+
+		_, err := handleError(request, errors.New("test error"), threshold)
+		Expect(err).To(HaveOccurred())
+		Expect(conditionsv1.IsStatusConditionTrue(request.Instance.Status.Conditions, conditionsv1.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should requeue conflicts without marking the SSP CR as degraded", func() {
+		conflict := k8serrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "test", errors.New("conflict"))
+
+		result, err := handleError(request, conflict, threshold)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Requeue).To(BeTrue())
+		Expect(request.Instance.Status.Conditions).To(BeEmpty())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+// +kubebuilder:rbac:groups=operators.coreos.com,resources=operatorconditions,verbs=get;update;patch
+
+// OperatorConditionNameEnv is set by OLM to the name of the OperatorCondition of the operator
+const OperatorConditionNameEnv = "OPERATOR_CONDITION_NAME"
+
+// The vendored OLM API does not contain the OperatorCondition,
+// so it is read as an unstructured object.
+var operatorConditionGVK = schema.GroupVersionKind{
+	Group:   "operators.coreos.com",
+	Version: "v2",
+	Kind:    "OperatorCondition",
+}
+
+const conditionUpgradeable = "Upgradeable"
+
+// upgradeableCondition returns the Upgradeable condition for the SSP CR.
+// The operator is not upgradeable while the CR is progressing, degraded or being deleted.
+func upgradeableCondition(sspObj *ssp.SSP) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    conditionUpgradeable,
+		Status:  metav1.ConditionTrue,
+		Reason:  "upgradeable",
+		Message: "The operator can be upgraded",
+	}
+	switch {
+	case sspObj.Status.Phase == lifecycleapi.PhaseDeleting:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "deleting"
+		condition.Message = "The resources of the SSP CR are being removed"
+	case conditionsv1.IsStatusConditionTrue(sspObj.Status.Conditions, conditionsv1.ConditionDegraded):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "degraded"
+		condition.Message = "The SSP CR is degraded"
+	case conditionsv1.IsStatusConditionTrue(sspObj.Status.Conditions, conditionsv1.ConditionProgressing):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "progressing"
+		condition.Message = "The SSP CR is progressing"
+	}
+	return condition
+}
+
+// updateOperatorCondition sets the Upgradeable condition of the OLM OperatorCondition,
+// so OLM does not replace the operator while the SSP CR is not in a steady state.
+// Nothing is done if the operator is not deployed by OLM.
+func (r *SSPReconciler) updateOperatorCondition(ctx context.Context, sspObj *ssp.SSP) error {
+	if r.OperatorCondition == nil {
+		return nil
+	}
+
+	operatorCondition := &unstructured.Unstructured{}
+	operatorCondition.SetGroupVersionKind(operatorConditionGVK)
+	err := r.apiReader.Get(ctx, *r.OperatorCondition, operatorCondition)
+	if err != nil {
+		return err
+	}
+
+	found, _, err := unstructured.NestedSlice(operatorCondition.Object, "spec", "conditions")
+	if err != nil {
+		return err
+	}
+	conditions := make([]metav1.Condition, 0, len(found)+1)
+	for _, item := range found {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid condition in OperatorCondition %s: %v", r.OperatorCondition, item)
+		}
+		condition := metav1.Condition{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(itemMap, &condition)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	expected := upgradeableCondition(sspObj)
+	if current := meta.FindStatusCondition(conditions, conditionUpgradeable); current != nil &&
+		current.Status == expected.Status && current.Reason == expected.Reason &&
+		current.Message == expected.Message {
+		return nil
+	}
+	meta.SetStatusCondition(&conditions, expected)
+
+	items := make([]interface{}, 0, len(conditions))
+	for i := range conditions {
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	err = unstructured.SetNestedSlice(operatorCondition.Object, items, "spec", "conditions")
+	if err != nil {
+		return err
+	}
+	return r.Update(ctx, operatorCondition)
+}
+
+// updateOperatorConditionFromActiveSsp sets the Upgradeable condition from the active SSP CR,
+// when the reconciled SSP CR does not exist anymore.
+// The operator is upgradeable if there is no SSP CR.
+func (r *SSPReconciler) updateOperatorConditionFromActiveSsp(ctx context.Context) error {
+	activeSsp, err := getActiveSsp(ctx, r)
+	if err != nil {
+		return err
+	}
+	if activeSsp == nil {
+		activeSsp = &ssp.SSP{}
+	}
+	return r.updateOperatorCondition(ctx, activeSsp)
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var _ = Describe("Operator condition", func() {
+	var (
+		ctx context.Context
+		r   *SSPReconciler
+		key types.NamespacedName
+	)
+
+	newOperatorCondition := func(conditions ...interface{}) *unstructured.Unstructured {
+		operatorCondition := &unstructured.Unstructured{}
+		operatorCondition.SetGroupVersionKind(operatorConditionGVK)
+		operatorCondition.SetNamespace(key.Namespace)
+		operatorCondition.SetName(key.Name)
+		if len(conditions) > 0 {
+			Expect(unstructured.SetNestedSlice(operatorCondition.Object, conditions, "spec", "conditions")).To(Succeed())
+		}
+		return operatorCondition
+	}
+
+	getConditions := func() []interface{} {
+		operatorCondition := newOperatorCondition()
+		Expect(r.apiReader.Get(ctx, key, operatorCondition)).To(Succeed())
+		conditions, _, err := unstructured.NestedSlice(operatorCondition.Object, "spec", "conditions")
+		Expect(err).ToNot(HaveOccurred())
+		return conditions
+	}
+
+	getResourceVersion := func() string {
+		operatorCondition := newOperatorCondition()
+		Expect(r.apiReader.Get(ctx, key, operatorCondition)).To(Succeed())
+		return operatorCondition.GetResourceVersion()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		key = types.NamespacedName{Namespace: testNamespace, Name: "ssp-operator.v0.0.1"}
+	})
+
+	setupReconciler := func(operatorCondition *unstructured.Unstructured) {
+		s := newTestScheme()
+		c := fake.NewFakeClientWithScheme(s)
+		Expect(c.Create(ctx, operatorCondition)).To(Succeed())
+		r = &SSPReconciler{
+			Client:            c,
+			Log:               testLog,
+			Scheme:            s,
+			OperatorCondition: &key,
+			apiReader:         c,
+		}
+	}
+
+	It("should do nothing if the operator is not deployed by OLM", func() {
+		r = &SSPReconciler{Log: testLog}
+		Expect(r.updateOperatorCondition(ctx, newTestSsp(testNamespace, testName))).To(Succeed())
+	})
+
+	It("should add the Upgradeable condition and keep other conditions", func() {
+		other := map[string]interface{}{
+			"type":               "Other",
+			"status":             "True",
+			"reason":             "other",
+			"message":            "Set by another component",
+			"lastTransitionTime": "2021-01-01T00:00:00Z",
+		}
+		setupReconciler(newOperatorCondition(other))
+
+		Expect(r.updateOperatorCondition(ctx, newTestSsp(testNamespace, testName))).To(Succeed())
+
+		conditions := getConditions()
+		Expect(conditions).To(HaveLen(2))
+		Expect(conditions).To(ContainElement(HaveKeyWithValue("type", "Other")))
+		Expect(conditions).To(ContainElement(And(
+			HaveKeyWithValue("type", conditionUpgradeable),
+			HaveKeyWithValue("status", string(metav1.ConditionTrue)),
+		)))
+	})
+
+	It("should update the Upgradeable condition when the SSP CR becomes degraded", func() {
+		setupReconciler(newOperatorCondition())
+		sspObj := newTestSsp(testNamespace, testName)
+		Expect(r.updateOperatorCondition(ctx, sspObj)).To(Succeed())
+
+		conditionsv1.SetStatusCondition(&sspObj.Status.Conditions, conditionsv1.Condition{
+			Type:   conditionsv1.ConditionDegraded,
+			Status: v1.ConditionTrue,
+			Reason: "degraded",
+		})
+		Expect(r.updateOperatorCondition(ctx, sspObj)).To(Succeed())
+
+		conditions := getConditions()
+		Expect(conditions).To(HaveLen(1))
+		Expect(conditions[0]).To(And(
+			HaveKeyWithValue("status", string(metav1.ConditionFalse)),
+			HaveKeyWithValue("reason", "degraded"),
+		))
+	})
+
+	It("should not update the OperatorCondition if the condition did not change", func() {
+		setupReconciler(newOperatorCondition())
+		sspObj := newTestSsp(testNamespace, testName)
+		Expect(r.updateOperatorCondition(ctx, sspObj)).To(Succeed())
+		resourceVersion := getResourceVersion()
+
+		Expect(r.updateOperatorCondition(ctx, sspObj)).To(Succeed())
+		Expect(getResourceVersion()).To(Equal(resourceVersion))
+	})
+
+	Context("removed SSP CR", func() {
+		removedRequest := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: "removed"}}
+
+		It("should set the Upgradeable condition from the active SSP CR", func() {
+			setupReconciler(newOperatorCondition())
+			active := newTestSsp(testNamespace, testName)
+			conditionsv1.SetStatusCondition(&active.Status.Conditions, conditionsv1.Condition{
+				Type:   conditionsv1.ConditionDegraded,
+				Status: v1.ConditionTrue,
+				Reason: "degraded",
+			})
+			Expect(r.Create(ctx, active)).To(Succeed())
+
+			_, err := r.Reconcile(removedRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			conditions := getConditions()
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0]).To(And(
+				HaveKeyWithValue("status", string(metav1.ConditionFalse)),
+				HaveKeyWithValue("reason", "degraded"),
+			))
+		})
+
+		It("should set the operator as upgradeable if there is no SSP CR", func() {
+			setupReconciler(newOperatorCondition())
+
+			_, err := r.Reconcile(removedRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			conditions := getConditions()
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0]).To(HaveKeyWithValue("status", string(metav1.ConditionTrue)))
+		})
+	})
+
+	table.DescribeTable("Upgradeable condition", func(modify func(*ssp.SSP), status metav1.ConditionStatus, reason string) {
+		sspObj := newTestSsp(testNamespace, testName)
+		modify(sspObj)
+		condition := upgradeableCondition(sspObj)
+		Expect(condition.Status).To(Equal(status))
+		Expect(condition.Reason).To(Equal(reason))
+	},
+		table.Entry("in steady state", func(*ssp.SSP) {}, metav1.ConditionTrue, "upgradeable"),
+		table.Entry("when deleting", func(sspObj *ssp.SSP) {
+			sspObj.Status.Phase = lifecycleapi.PhaseDeleting
+		}, metav1.ConditionFalse, "deleting"),
+		table.Entry("when progressing", func(sspObj *ssp.SSP) {
+			conditionsv1.SetStatusCondition(&sspObj.Status.Conditions, conditionsv1.Condition{
+				Type:   conditionsv1.ConditionProgressing,
+				Status: v1.ConditionTrue,
+			})
+		}, metav1.ConditionFalse, "progressing"),
+		table.Entry("when degraded and progressing", func(sspObj *ssp.SSP) {
+			conditionsv1.SetStatusCondition(&sspObj.Status.Conditions, conditionsv1.Condition{
+				Type:   conditionsv1.ConditionProgressing,
+				Status: v1.ConditionTrue,
+			})
+			conditionsv1.SetStatusCondition(&sspObj.Status.Conditions, conditionsv1.Condition{
+				Type:   conditionsv1.ConditionDegraded,
+				Status: v1.ConditionTrue,
+			})
+		}, metav1.ConditionFalse, "degraded"),
+	)
+})
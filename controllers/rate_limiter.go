@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// NewRateLimiter returns the rate limiter of the SSP controller. Failed reconciliations
+// are retried with exponential backoff from baseDelay up to maxDelay. Every delay
+// is increased by a random fraction of up to jitterFactor, so retries of multiple
+// requests are spread over time. The overall rate is limited like in the default
+// controller rate limiter.
+func NewRateLimiter(baseDelay, maxDelay time.Duration, jitterFactor float64) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		&jitterRateLimiter{
+			RateLimiter:  workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+			maxDelay:     maxDelay,
+			jitterFactor: jitterFactor,
+		},
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+type jitterRateLimiter struct {
+	workqueue.RateLimiter
+	maxDelay     time.Duration
+	jitterFactor float64
+}
+
+func (j *jitterRateLimiter) When(item interface{}) time.Duration {
+	delay := j.RateLimiter.When(item)
+	if j.jitterFactor <= 0 {
+		return delay
+	}
+	delay = wait.Jitter(delay, j.jitterFactor)
+	if delay > j.maxDelay {
+		return j.maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/reference"
+
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// getRelatedObjects returns sorted references to the resources reconciled by the operands
+func getRelatedObjects(request *common.Request, statuses []common.ResourceStatus) []v1.ObjectReference {
+	found := map[v1.ObjectReference]struct{}{}
+	relatedObjects := make([]v1.ObjectReference, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Resource == nil || status.Removed {
+			continue
+		}
+		ref, err := reference.GetReference(request.Scheme, status.Resource)
+		if err != nil {
+			request.Logger.Error(err, "Failed to get reference of a related object")
+			continue
+		}
+		// Only the identity of the object is listed, not its version
+		ref.UID = ""
+		ref.ResourceVersion = ""
+		if _, ok := found[*ref]; ok {
+			continue
+		}
+		found[*ref] = struct{}{}
+		relatedObjects = append(relatedObjects, *ref)
+	}
+
+	sort.Slice(relatedObjects, func(i, j int) bool {
+		a, b := relatedObjects[i], relatedObjects[j]
+		if a.APIVersion != b.APIVersion {
+			return a.APIVersion < b.APIVersion
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return relatedObjects
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	common_templates "kubevirt.io/ssp-operator/internal/operands/common-templates"
+	node_labeller "kubevirt.io/ssp-operator/internal/operands/node-labeller"
+	template_validator "kubevirt.io/ssp-operator/internal/operands/template-validator"
+)
+
+// Keys of the last-known-good ConfigMap
+const (
+	LastKnownGoodSpecKey             = "spec"
+	LastKnownGoodOperatorVersionKey  = "operatorVersion"
+	LastKnownGoodTemplatesVersionKey = "templatesVersion"
+	LastKnownGoodImagesKey           = "images"
+	LastKnownGoodResourcesKey        = "resources"
+)
+
+// lastKnownGoodImages are the container images of the deployed operands
+type lastKnownGoodImages struct {
+	TemplateValidator string                 `json:"templateValidator,omitempty"`
+	NodeLabeller      ssp.NodeLabellerImages `json:"nodeLabeller,omitempty"`
+}
+
+// LastKnownGoodConfigMapName returns the name of the ConfigMap with the last
+// successfully deployed spec of the SSP CR
+func LastKnownGoodConfigMapName(sspObj *ssp.SSP) string {
+	return sspObj.Name + "-last-known-good"
+}
+
+func isRollbackRequested(instance *ssp.SSP) bool {
+	rollbackStr, ok := instance.GetAnnotations()[ssp.RollbackAnnotation]
+	if !ok {
+		return false
+	}
+	rollback, err := strconv.ParseBool(rollbackStr)
+	if err != nil {
+		return false
+	}
+	return rollback
+}
+
+// saveLastKnownGood stores the spec of the SSP CR, the deployed versions and images,
+// and the inventory of the applied resources, after all operands were deployed successfully.
+// The ConfigMap is only updated if its content changed.
+func saveLastKnownGood(request *common.Request, statuses []common.ResourceStatus) error {
+	spec, err := json.Marshal(request.Instance.Spec)
+	if err != nil {
+		return err
+	}
+	images, err := json.Marshal(lastKnownGoodImages{
+		TemplateValidator: template_validator.Image(&request.Instance.Spec),
+		NodeLabeller:      node_labeller.Images(&request.Instance.Spec),
+	})
+	if err != nil {
+		return err
+	}
+	resources, err := json.Marshal(resourceInventory(request, statuses))
+	if err != nil {
+		return err
+	}
+	data := map[string]string{
+		LastKnownGoodSpecKey:            string(spec),
+		LastKnownGoodOperatorVersionKey: getOperatorVersion(),
+		LastKnownGoodImagesKey:          string(images),
+		LastKnownGoodResourcesKey:       string(resources),
+	}
+	templatesOperand := common_templates.GetOperand().Name()
+	for _, operandStatus := range request.Instance.Status.Operands {
+		if operandStatus.Name == templatesOperand && operandStatus.Version != "" {
+			data[LastKnownGoodTemplatesVersionKey] = operandStatus.Version
+		}
+	}
+
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      LastKnownGoodConfigMapName(request.Instance),
+		Namespace: request.Instance.Namespace,
+	}}
+	// CreateOrUpdate does not update the ConfigMap, if the mutated ConfigMap is equal to the existing one
+	_, err = controllerutil.CreateOrUpdate(request.Context, request.Client, configMap, func() error {
+		configMap.Data = data
+		return controllerutil.SetControllerReference(request.Instance, configMap, request.Scheme)
+	})
+	return err
+}
+
+// resourceInventory returns the sorted keys of the applied resources,
+// in the form kind/namespace/name, or kind/name for cluster resources
+func resourceInventory(request *common.Request, statuses []common.ResourceStatus) []string {
+	inventory := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Removed || status.Resource == nil {
+			continue
+		}
+		kind := reflect.Indirect(reflect.ValueOf(status.Resource)).Type().Name()
+		if gvk, err := apiutil.GVKForObject(status.Resource, request.Scheme); err == nil {
+			kind = gvk.Kind
+		}
+		key := path.Join(kind, status.Resource.GetNamespace(), status.Resource.GetName())
+		inventory = append(inventory, key)
+	}
+	sort.Strings(inventory)
+	return inventory
+}
+
+// rollback restores the last successfully deployed spec of the SSP CR and removes the
+// rollback annotation. If the templates bundle version was not pinned, it is pinned
+// to the last deployed version, if that version is shipped with the operator.
+// Operand images that are not set in the spec are pinned to the last deployed images.
+// The operator itself is not rolled back.
+func rollback(request *common.Request) error {
+	instance := request.Instance
+	delete(instance.Annotations, ssp.RollbackAnnotation)
+
+	configMap := &v1.ConfigMap{}
+	err := request.Client.Get(request.Context, client.ObjectKey{
+		Namespace: instance.Namespace,
+		Name:      LastKnownGoodConfigMapName(instance),
+	}, configMap)
+	if errors.IsNotFound(err) {
+		recordRollbackFailedEvent(request, "No successfully deployed spec was recorded")
+		return request.Client.Update(request.Context, instance)
+	}
+	if err != nil {
+		return err
+	}
+
+	spec := ssp.SSPSpec{}
+	err = json.Unmarshal([]byte(configMap.Data[LastKnownGoodSpecKey]), &spec)
+	if err != nil {
+		recordRollbackFailedEvent(request, fmt.Sprintf("The recorded spec is not valid: %v", err))
+		return request.Client.Update(request.Context, instance)
+	}
+	templatesVersion := configMap.Data[LastKnownGoodTemplatesVersionKey]
+	if spec.CommonTemplates.Version == "" && spec.CommonTemplates.BundleRef == nil &&
+		templatesVersion != "" && templatesVersion != common_templates.Version &&
+		common_templates.HasBuiltInBundle(templatesVersion) {
+		spec.CommonTemplates.Version = templatesVersion
+	}
+	if imagesData, ok := configMap.Data[LastKnownGoodImagesKey]; ok {
+		images := lastKnownGoodImages{}
+		err = json.Unmarshal([]byte(imagesData), &images)
+		if err != nil {
+			recordRollbackFailedEvent(request, fmt.Sprintf("The recorded images are not valid: %v", err))
+			return request.Client.Update(request.Context, instance)
+		}
+		pinImages(&spec, &images)
+	}
+
+	instance.Spec = spec
+	err = request.Client.Update(request.Context, instance)
+	if err != nil {
+		return err
+	}
+	request.Logger.Info(fmt.Sprintf("Rolled back the spec of SSP CR %s/%s", instance.Namespace, instance.Name))
+	recordRolledBackEvent(request, configMap.Data[LastKnownGoodOperatorVersionKey])
+	return nil
+}
+
+// pinImages sets the operand images, that are not set in the spec, to the last deployed images,
+// if they differ from the current defaults of the operator
+func pinImages(spec *ssp.SSPSpec, images *lastKnownGoodImages) {
+	pin := func(override *string, deployed, defaultImage string) {
+		if *override == "" && deployed != "" && deployed != defaultImage {
+			*override = deployed
+		}
+	}
+	defaults := &ssp.SSPSpec{}
+	pin(&spec.TemplateValidator.Image, images.TemplateValidator, template_validator.Image(defaults))
+
+	overrides := ssp.NodeLabellerImages{}
+	if spec.NodeLabeller.Images != nil {
+		overrides = *spec.NodeLabeller.Images
+	}
+	defaultImages := node_labeller.Images(defaults)
+	pin(&overrides.NodeLabeller, images.NodeLabeller.NodeLabeller, defaultImages.NodeLabeller)
+	pin(&overrides.KvmInfoNfdPlugin, images.NodeLabeller.KvmInfoNfdPlugin, defaultImages.KvmInfoNfdPlugin)
+	pin(&overrides.CpuNfdPlugin, images.NodeLabeller.CpuNfdPlugin, defaultImages.CpuNfdPlugin)
+	pin(&overrides.VirtLauncher, images.NodeLabeller.VirtLauncher, defaultImages.VirtLauncher)
+	if overrides != (ssp.NodeLabellerImages{}) {
+		spec.NodeLabeller.Images = &overrides
+	}
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	common_templates "kubevirt.io/ssp-operator/internal/operands/common-templates"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var _ = Describe("Rollback", func() {
+	var (
+		request  *common.Request
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		s := newTestScheme()
+		instance := newTestSsp(testNamespace, testName)
+		instance.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(2)
+		recorder = record.NewFakeRecorder(10)
+		request = newTestRequest(fake.NewFakeClientWithScheme(s, instance), s, instance, recorder)
+	})
+
+	getLastKnownGood := func() *v1.ConfigMap {
+		configMap := &v1.ConfigMap{}
+		Expect(request.Client.Get(request.Context, client.ObjectKey{
+			Namespace: testNamespace,
+			Name:      LastKnownGoodConfigMapName(request.Instance),
+		}, configMap)).To(Succeed())
+		return configMap
+	}
+
+	requestRollback := func() {
+		Expect(request.Client.Get(request.Context, request.NamespacedName, request.Instance)).To(Succeed())
+		request.Instance.Annotations = map[string]string{ssp.RollbackAnnotation: "true"}
+		Expect(request.Client.Update(request.Context, request.Instance)).To(Succeed())
+	}
+
+	getSsp := func() *ssp.SSP {
+		updated := &ssp.SSP{}
+		Expect(request.Client.Get(request.Context, request.NamespacedName, updated)).To(Succeed())
+		return updated
+	}
+
+	table.DescribeTable("should detect the rollback annotation", func(annotations map[string]string, expected bool) {
+		sspObj := newTestSsp(testNamespace, testName)
+		sspObj.Annotations = annotations
+		Expect(isRollbackRequested(sspObj)).To(Equal(expected))
+	},
+		table.Entry("without annotations", nil, false),
+		table.Entry("with true", map[string]string{ssp.RollbackAnnotation: "true"}, true),
+		table.Entry("with false", map[string]string{ssp.RollbackAnnotation: "false"}, false),
+		table.Entry("with invalid value", map[string]string{ssp.RollbackAnnotation: "yes"}, false),
+	)
+
+	It("should save the deployed spec and versions", func() {
+		request.Instance.Status.Operands = []ssp.OperandStatus{{
+			Name:    common_templates.GetOperand().Name(),
+			Version: "v0.0.1",
+		}}
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+
+		configMap := getLastKnownGood()
+		spec := ssp.SSPSpec{}
+		Expect(json.Unmarshal([]byte(configMap.Data[LastKnownGoodSpecKey]), &spec)).To(Succeed())
+		Expect(spec).To(Equal(request.Instance.Spec))
+		Expect(configMap.Data[LastKnownGoodOperatorVersionKey]).To(Equal(getOperatorVersion()))
+		Expect(configMap.Data[LastKnownGoodTemplatesVersionKey]).To(Equal("v0.0.1"))
+		Expect(metav1.IsControlledBy(configMap, request.Instance)).To(BeTrue())
+	})
+
+	It("should save the inventory of applied resources", func() {
+		statuses := []common.ResourceStatus{{
+			Resource: &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-account", Namespace: testNamespace}},
+		}, {
+			Resource: &rbac.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "test-role"}},
+		}, {
+			Resource: &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: testNamespace}},
+			Removed:  true,
+		}}
+		Expect(saveLastKnownGood(request, statuses)).To(Succeed())
+
+		var inventory []string
+		Expect(json.Unmarshal([]byte(getLastKnownGood().Data[LastKnownGoodResourcesKey]), &inventory)).To(Succeed())
+		Expect(inventory).To(Equal([]string{
+			"ClusterRole/test-role",
+			"ServiceAccount/" + testNamespace + "/test-account",
+		}))
+	})
+
+	It("should not update the ConfigMap if nothing changed", func() {
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+		resourceVersion := getLastKnownGood().ResourceVersion
+
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+		Expect(getLastKnownGood().ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should pin the saved operand images", func() {
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+		configMap := getLastKnownGood()
+		configMap.Data[LastKnownGoodImagesKey] = `{"templateValidator":"old-validator","nodeLabeller":{"nodeLabeller":"old-labeller"}}`
+		Expect(request.Client.Update(request.Context, configMap)).To(Succeed())
+
+		requestRollback()
+		Expect(rollback(request)).To(Succeed())
+
+		updated := getSsp()
+		Expect(updated.Spec.TemplateValidator.Image).To(Equal("old-validator"))
+		Expect(updated.Spec.NodeLabeller.Images).To(Equal(&ssp.NodeLabellerImages{NodeLabeller: "old-labeller"}))
+	})
+
+	It("should not pin operand images that did not change", func() {
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+
+		requestRollback()
+		Expect(rollback(request)).To(Succeed())
+
+		updated := getSsp()
+		Expect(updated.Spec.TemplateValidator.Image).To(BeEmpty())
+		Expect(updated.Spec.NodeLabeller.Images).To(BeNil())
+	})
+
+	It("should restore the saved spec and remove the annotation", func() {
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+		savedSpec := request.Instance.Spec
+
+		requestRollback()
+		request.Instance.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(5)
+		Expect(request.Client.Update(request.Context, request.Instance)).To(Succeed())
+
+		Expect(rollback(request)).To(Succeed())
+
+		updated := getSsp()
+		Expect(updated.Spec).To(Equal(savedSpec))
+		Expect(updated.Annotations).ToNot(HaveKey(ssp.RollbackAnnotation))
+		Expect(recorder.Events).To(Receive(ContainSubstring(RolledBackReason)))
+	})
+
+	It("should not pin a templates version that is not shipped with the operator", func() {
+		request.Instance.Status.Operands = []ssp.OperandStatus{{
+			Name:    common_templates.GetOperand().Name(),
+			Version: "v0.0.1",
+		}}
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+
+		requestRollback()
+		Expect(rollback(request)).To(Succeed())
+		Expect(getSsp().Spec.CommonTemplates.Version).To(BeEmpty())
+	})
+
+	It("should only remove the annotation, if no spec was saved", func() {
+		requestRollback()
+		request.Instance.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(5)
+		Expect(request.Client.Update(request.Context, request.Instance)).To(Succeed())
+
+		Expect(rollback(request)).To(Succeed())
+
+		updated := getSsp()
+		Expect(*updated.Spec.TemplateValidator.Replicas).To(BeEquivalentTo(5))
+		Expect(updated.Annotations).ToNot(HaveKey(ssp.RollbackAnnotation))
+		Expect(recorder.Events).To(Receive(ContainSubstring(RollbackFailedReason)))
+	})
+
+	It("should only remove the annotation, if the saved spec is not valid", func() {
+		Expect(saveLastKnownGood(request, nil)).To(Succeed())
+		configMap := getLastKnownGood()
+		configMap.Data[LastKnownGoodSpecKey] = "{"
+		Expect(request.Client.Update(request.Context, configMap)).To(Succeed())
+
+		requestRollback()
+		Expect(rollback(request)).To(Succeed())
+
+		Expect(getSsp().Annotations).ToNot(HaveKey(ssp.RollbackAnnotation))
+		Expect(recorder.Events).To(Receive(ContainSubstring("not valid")))
+	})
+})
@@ -21,21 +21,31 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	ocpv1 "github.com/openshift/api/config/v1"
 	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
-	libhandler "github.com/operator-framework/operator-lib/handler"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/pointer"
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -45,20 +55,35 @@ import (
 	ssp "kubevirt.io/ssp-operator/api/v1beta1"
 	"kubevirt.io/ssp-operator/internal/common"
 	"kubevirt.io/ssp-operator/internal/operands"
+	admission_policies "kubevirt.io/ssp-operator/internal/operands/admission-policies"
 	common_templates "kubevirt.io/ssp-operator/internal/operands/common-templates"
 	"kubevirt.io/ssp-operator/internal/operands/metrics"
 	node_labeller "kubevirt.io/ssp-operator/internal/operands/node-labeller"
 	template_validator "kubevirt.io/ssp-operator/internal/operands/template-validator"
+	"kubevirt.io/ssp-operator/internal/topology"
 )
 
 const finalizerName = "finalize.ssp.kubevirt.io"
 const defaultOperatorVersion = "devel"
+const DefaultOperatorLogVerbosity = ssp.DefaultOperatorLogVerbosity
 
 var sspOperands = []operands.Operand{
 	metrics.GetOperand(),
 	template_validator.GetOperand(),
 	common_templates.GetOperand(),
 	node_labeller.GetOperand(),
+	admission_policies.GetOperand(),
+}
+
+// Operands are removed in this order when the SSP CR is deleted.
+// The validator webhook is removed first, so it does not validate
+// VMs using templates that are being removed.
+var sspOperandsCleanupOrder = []operands.Operand{
+	template_validator.GetOperand(),
+	node_labeller.GetOperand(),
+	common_templates.GetOperand(),
+	metrics.GetOperand(),
+	admission_policies.GetOperand(),
 }
 
 // List of legacy CRDs and their corresponding kinds
@@ -72,17 +97,64 @@ var kvsspCRDs = map[string]string{
 // SSPReconciler reconciles a SSP object
 type SSPReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// MaxConcurrentReconciles is the number of SSP CRs reconciled concurrently,
+	// and the number of independent resources an operand reconciles concurrently
+	MaxConcurrentReconciles int
 
+	// DegradedFailureThreshold is the number of consecutive failed reconciliations
+	// of an operand, after which the operand and the SSP CR are marked as degraded
+	DegradedFailureThreshold int
+
+	// AuditSink records the changes the operator does to objects.
+	// The changes are not recorded if it is nil.
+	AuditSink AuditSink
+
+	// RateLimiter limits how often failed reconciliations are retried.
+	// The default controller rate limiter is used if it is nil.
+	RateLimiter workqueue.RateLimiter
+
+	// cacheLock protects the fields below
+	cacheLock        sync.Mutex
 	LastSspSpec      ssp.SSPSpec
-	SubresourceCache common.VersionCache
+	LastClusterProxy *ssp.Proxy
+	LastKubeVirt     *common.KubeVirtConfig
+	SubresourceCache *common.VersionCache
+
+	// SetLogVerbosity changes the verbosity of the operator logs, it can be nil
+	SetLogVerbosity func(verbosity int32)
+
+	// SetTLSSecurityProfile changes the TLS settings of the operator metrics server, it can be nil
+	SetTLSSecurityProfile func(profile *ocpv1.TLSSecurityProfile)
+
+	// OperatorCondition is the key of the OLM OperatorCondition, whose Upgradeable
+	// condition is set by the operator. It is nil if the operator is not deployed by OLM.
+	OperatorCondition *types.NamespacedName
+
+	// clusterAPIs stores the APIs available in the cluster between reconciliations
+	clusterAPIs *clusterAPIsCache
+
+	// apiReader reads objects that are not worth caching, like nodes and pods
+	apiReader client.Reader
+
+	// controller is used to watch resources of operands,
+	// whose APIs are installed after the operator started
+	controller          controller.Controller
+	watchLock           sync.Mutex
+	watchedTypes        map[watchedType]struct{}
+	kubeVirtWatched     bool
+	clusterProxyWatched bool
 }
 
 // +kubebuilder:rbac:groups=ssp.kubevirt.io,resources=ssps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssp.kubevirt.io,resources=ssps/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssp.kubevirt.io,resources=ssps/finalizers,verbs=update
-// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=list
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=list;watch
+// +kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=ssp.kubevirt.io,resources=kubevirtcommontemplatesbundles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssp.kubevirt.io,resources=kubevirtmetricsaggregations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssp.kubevirt.io,resources=kubevirtnodelabellerbundles,verbs=get;list;watch;create;update;patch;delete
@@ -102,13 +174,48 @@ func (r *SSPReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
-			return ctrl.Result{}, nil
+			return ctrl.Result{}, r.updateOperatorConditionFromActiveSsp(ctx)
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
 
-	r.clearCacheIfNeeded(instance)
+	// Only one SSP CR is reconciled, extra CRs are rejected
+	activeSsp, err := getActiveSsp(ctx, r)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if activeSsp != nil && activeSsp.UID != instance.UID {
+		return ctrl.Result{}, r.rejectSsp(ctx, instance, activeSsp)
+	}
+	// The condition is removed from the status by the next status update
+	conditionsv1.RemoveStatusCondition(&instance.Status.Conditions, ssp.ConditionRejected)
+
+	// The Upgradeable condition is updated from the status set during the reconciliation
+	defer func() {
+		if err := r.updateOperatorCondition(ctx, instance); err != nil {
+			reqLogger.Error(err, "Error updating OperatorCondition.")
+		}
+	}()
+
+	clusterProxy, err := r.getClusterProxy(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	kubeVirt, err := r.getKubeVirtConfig(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	versionCache := r.clearCacheIfNeeded(instance, clusterProxy, kubeVirt)
+
+	if r.SetLogVerbosity != nil {
+		r.SetLogVerbosity(pointer.Int32PtrDerefOr(instance.Spec.OperatorLogVerbosity, DefaultOperatorLogVerbosity))
+	}
+	if r.SetTLSSecurityProfile != nil {
+		r.SetTLSSecurityProfile(instance.Spec.TLSSecurityProfile)
+	}
 
 	sspRequest := &common.Request{
 		Request:      req,
@@ -117,7 +224,38 @@ func (r *SSPReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		Context:      ctx,
 		Instance:     instance,
 		Logger:       reqLogger,
-		VersionCache: r.SubresourceCache,
+		Recorder:     r.Recorder,
+		VersionCache: versionCache,
+		APIReader:    r.apiReader,
+		ClusterProxy: clusterProxy,
+		KubeVirt:     kubeVirt,
+
+		MaxConcurrentResources: r.MaxConcurrentReconciles,
+	}
+
+	if r.AuditSink != nil {
+		reconcileID := string(uuid.NewUUID())
+		auditClient := common.NewAuditClient(r.Client, r.Scheme, reconcileID)
+		sspRequest.Client = auditClient
+		sspRequest.Logger = reqLogger.WithValues("reconcileID", reconcileID)
+		defer r.recordAudit(sspRequest, auditClient)
+	}
+
+	apis, err := r.clusterAPIs.get(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(apis.failedGroupVersions) > 0 {
+		reqLogger.Info("Discovery of some APIs failed, they are considered not served",
+			"groupVersions", apis.failedGroupVersions)
+	}
+	err = r.watchOperandResources(apis)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	sspRequest.SingleNode, err = topology.IsSingleNodeCluster(ctx, r.apiReader)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	if !isInitialized(sspRequest.Instance) {
@@ -128,7 +266,7 @@ func (r *SSPReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	if isBeingDeleted(sspRequest.Instance) {
-		err := cleanup(sspRequest)
+		err := cleanup(sspRequest, apis)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -136,17 +274,41 @@ func (r *SSPReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, nil
 	}
 
-	if isPaused(instance) {
-		if instance.Status.Paused {
+	if isRollbackRequested(instance) {
+		// The update of the spec triggers reconciliation again
+		return ctrl.Result{}, rollback(sspRequest)
+	}
+
+	paused := isPaused(instance)
+	setPausedMetric(paused)
+	if paused {
+		// Resources are not modified while paused, but spec changes are still observed
+		if instance.Status.Paused && conditionsv1.IsStatusConditionTrue(instance.Status.Conditions, ssp.ConditionPaused) &&
+			instance.Status.ObservedGeneration == instance.Generation {
 			return ctrl.Result{}, nil
 		}
-		reqLogger.Info(fmt.Sprintf("Pausing SSP operator on resource: %v/%v", instance.Namespace, instance.Name))
+		if !instance.Status.Paused {
+			reqLogger.Info(fmt.Sprintf("Pausing SSP operator on resource: %v/%v", instance.Namespace, instance.Name))
+		}
 		instance.Status.Paused = true
+		instance.Status.Phase = ssp.PhasePaused
 		instance.Status.ObservedGeneration = instance.Generation
+		conditionsv1.SetStatusCondition(&instance.Status.Conditions, conditionsv1.Condition{
+			Type:    ssp.ConditionPaused,
+			Status:  v1.ConditionTrue,
+			Reason:  "paused",
+			Message: "Reconciliation of SSP resources is paused",
+		})
 		err := r.Status().Update(ctx, instance)
 		return ctrl.Result{}, err
 	}
 
+	if isDryRun(instance) {
+		// Resources and the status are not modified during a dry-run
+		sspRequest.Logger.V(1).Info("Computing changes of operands using dry-run...")
+		return ctrl.Result{}, dryRun(sspRequest, apis, r.DegradedFailureThreshold)
+	}
+
 	sspRequest.Logger.V(1).Info("Updating CR status prior to operand reconciliation...")
 	err = preUpdateStatus(sspRequest)
 	if err != nil {
@@ -155,12 +317,17 @@ func (r *SSPReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	sspRequest.Logger.V(1).Info("CR status updated")
 
 	sspRequest.Logger.V(1).Info("Reconciling operands...")
-	statuses, err := reconcileOperands(sspRequest)
+	statuses, err := reconcileOperands(sspRequest, apis, r.DegradedFailureThreshold)
 	if err != nil {
-		return handleError(sspRequest, err)
+		return handleError(sspRequest, err, r.DegradedFailureThreshold)
 	}
 	sspRequest.Logger.V(1).Info("Operands reconciled")
 
+	err = removeTemplatesNamespaceMigrationAnnotation(sspRequest)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	sspRequest.Logger.V(1).Info("Updating CR status post reconciliation...")
 	err = updateStatus(sspRequest, statuses)
 	if err != nil {
@@ -168,30 +335,100 @@ func (r *SSPReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 	sspRequest.Logger.V(1).Info("CR status updated")
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter(statuses)}, nil
+}
+
+// requeueAfter returns the shortest requeue duration requested by the operands
+func requeueAfter(statuses []common.ResourceStatus) time.Duration {
+	var result time.Duration
+	for _, status := range statuses {
+		if status.RequeueAfter > 0 && (result == 0 || status.RequeueAfter < result) {
+			result = status.RequeueAfter
+		}
+	}
+	return result
 }
 
-func (r *SSPReconciler) clearCacheIfNeeded(sspObj *ssp.SSP) {
-	if !reflect.DeepEqual(r.LastSspSpec, sspObj.Spec) {
-		r.SubresourceCache = common.VersionCache{}
+// clearCacheIfNeeded clears the cache if the SSP spec, the cluster proxy or the KubeVirt
+// settings changed, and returns the cache to use in the reconciliation
+func (r *SSPReconciler) clearCacheIfNeeded(sspObj *ssp.SSP, clusterProxy *ssp.Proxy, kubeVirt *common.KubeVirtConfig) *common.VersionCache {
+	r.cacheLock.Lock()
+	defer r.cacheLock.Unlock()
+	if !reflect.DeepEqual(r.LastSspSpec, sspObj.Spec) || !reflect.DeepEqual(r.LastClusterProxy, clusterProxy) ||
+		!reflect.DeepEqual(r.LastKubeVirt, kubeVirt) {
+		r.SubresourceCache = common.NewVersionCache()
 		r.LastSspSpec = sspObj.Spec
+		r.LastClusterProxy = clusterProxy
+		r.LastKubeVirt = kubeVirt
 	}
+	return r.SubresourceCache
 }
 
 func (r *SSPReconciler) clearCache() {
+	r.cacheLock.Lock()
+	defer r.cacheLock.Unlock()
 	r.LastSspSpec = ssp.SSPSpec{}
-	r.SubresourceCache = common.VersionCache{}
+	r.LastClusterProxy = nil
+	r.LastKubeVirt = nil
+	r.SubresourceCache = common.NewVersionCache()
+}
+
+// clusterProxyName is the name of the OpenShift cluster proxy configuration
+const clusterProxyName = "cluster"
+
+// getClusterProxy returns the proxy configured for the OpenShift cluster.
+// It returns nil if the cluster has no proxy, or if it is not an OpenShift cluster.
+func (r *SSPReconciler) getClusterProxy(ctx context.Context) (*ssp.Proxy, error) {
+	clusterProxy := &ocpv1.Proxy{}
+	err := r.Get(ctx, client.ObjectKey{Name: clusterProxyName}, clusterProxy)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := clusterProxy.Status
+	if status.HTTPProxy == "" && status.HTTPSProxy == "" && status.NoProxy == "" {
+		return nil, nil
+	}
+	return &ssp.Proxy{
+		HTTPProxy:  status.HTTPProxy,
+		HTTPSProxy: status.HTTPSProxy,
+		NoProxy:    status.NoProxy,
+	}, nil
+}
+
+// getKubeVirtConfig returns the settings of the KubeVirt CR.
+// It returns nil if there is no KubeVirt CR, or if KubeVirt is not installed.
+func (r *SSPReconciler) getKubeVirtConfig(ctx context.Context) (*common.KubeVirtConfig, error) {
+	kubevirts := &unstructured.UnstructuredList{}
+	kubevirts.SetGroupVersionKind(common.KubeVirtGVK.GroupVersion().WithKind(common.KubeVirtGVK.Kind + "List"))
+	err := r.List(ctx, kubevirts)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(kubevirts.Items) == 0 {
+		return nil, nil
+	}
+	return common.NewKubeVirtConfig(&kubevirts.Items[0])
 }
 
 func getOperatorVersion() string {
 	return common.EnvOrDefault(common.OperatorVersionKey, defaultOperatorVersion)
 }
 
-func isPaused(object metav1.Object) bool {
-	if object.GetAnnotations() == nil {
+func isPaused(instance *ssp.SSP) bool {
+	if instance.Spec.Paused {
+		return true
+	}
+	if instance.GetAnnotations() == nil {
 		return false
 	}
-	pausedStr, ok := object.GetAnnotations()[ssp.OperatorPausedAnnotation]
+	pausedStr, ok := instance.GetAnnotations()[ssp.OperatorPausedAnnotation]
 	if !ok {
 		return false
 	}
@@ -206,8 +443,10 @@ func isBeingDeleted(object metav1.Object) bool {
 	return !object.GetDeletionTimestamp().IsZero()
 }
 
-func isInitialized(ssp *ssp.SSP) bool {
-	return isBeingDeleted(ssp) || ssp.Status.Phase != lifecycleapi.PhaseEmpty
+func isInitialized(sspObj *ssp.SSP) bool {
+	// A CR that was rejected is initialized when it becomes active
+	return isBeingDeleted(sspObj) ||
+		(sspObj.Status.Phase != lifecycleapi.PhaseEmpty && sspObj.Status.Phase != ssp.PhaseRejected)
 }
 
 func initialize(request *common.Request) error {
@@ -222,20 +461,44 @@ func initialize(request *common.Request) error {
 	return request.Client.Status().Update(request.Context, request.Instance)
 }
 
-func cleanup(request *common.Request) error {
+func cleanup(request *common.Request, apis *clusterAPIs) error {
 	if controllerutil.ContainsFinalizer(request.Instance, finalizerName) {
 		request.Instance.Status.Phase = lifecycleapi.PhaseDeleting
 		request.Instance.Status.ObservedGeneration = request.Instance.Generation
-		err := request.Client.Status().Update(request.Context, request.Instance)
-		if err != nil {
-			return err
+		if !ssp.IsForceDeletionRequested(request.Instance) {
+			importing, err := ssp.ImportingDataImportCrons(request.Context, request.Client, request.Instance)
+			if err != nil {
+				return err
+			}
+			if len(importing) > 0 {
+				// DataImportCrons are watched, so the cleanup continues when the imports finish
+				return setDeletingCondition(request, fmt.Sprintf("Waiting for boot source imports to finish: %s",
+					strings.Join(importing, ", ")))
+			}
 		}
-		for _, operand := range sspOperands {
+		for _, operand := range sspOperandsCleanupOrder {
+			// Operands with missing APIs were not deployed
+			if len(apis.missingAPIs(operand)) > 0 {
+				continue
+			}
+			err := setDeletingCondition(request, fmt.Sprintf("Removing resources of operand: %s", operand.Name()))
+			if err != nil {
+				return err
+			}
 			err = operand.Cleanup(request)
 			if err != nil {
+				statusErr := setDeletingCondition(request,
+					fmt.Sprintf("Failed to remove resources of operand %s: %v", operand.Name(), err))
+				if statusErr != nil {
+					request.Logger.Error(statusErr, "Error updating SSP status.")
+				}
 				return err
 			}
 		}
+		err := setDeletingCondition(request, "All resources were removed")
+		if err != nil {
+			return err
+		}
 		controllerutil.RemoveFinalizer(request.Instance, finalizerName)
 		err = request.Client.Update(request.Context, request.Instance)
 		if err != nil {
@@ -254,6 +517,36 @@ func cleanup(request *common.Request) error {
 	return err
 }
 
+// removeTemplatesNamespaceMigrationAnnotation removes the annotation that allowed moving the common templates.
+// It is called after the operands were reconciled, when the templates are deployed to the new namespace
+// and removed from the previous one, so a later change of the namespace has to be allowed again.
+func removeTemplatesNamespaceMigrationAnnotation(request *common.Request) error {
+	if _, ok := request.Instance.GetAnnotations()[ssp.MigrateTemplatesNamespaceAnnotation]; !ok {
+		return nil
+	}
+	// A copy is patched, so the status computed during the reconciliation is kept
+	patched := request.Instance.DeepCopy()
+	delete(patched.Annotations, ssp.MigrateTemplatesNamespaceAnnotation)
+	err := request.Client.Patch(request.Context, patched, client.MergeFrom(request.Instance))
+	if err != nil {
+		return err
+	}
+	request.Instance.Annotations = patched.Annotations
+	request.Instance.ResourceVersion = patched.ResourceVersion
+	return nil
+}
+
+// setDeletingCondition reports the progress of the cleanup in the Deleting condition
+func setDeletingCondition(request *common.Request, message string) error {
+	conditionsv1.SetStatusCondition(&request.Instance.Status.Conditions, conditionsv1.Condition{
+		Type:    ssp.ConditionDeleting,
+		Status:  v1.ConditionTrue,
+		Reason:  "deleting",
+		Message: message,
+	})
+	return request.Client.Status().Update(request.Context, request.Instance)
+}
+
 func pauseCRs(sspRequest *common.Request, kinds []string) error {
 	patch := []byte(`{
   "metadata":{
@@ -282,32 +575,19 @@ func pauseCRs(sspRequest *common.Request, kinds []string) error {
 	return nil
 }
 
-func listExistingCRDKinds(sspRequest *common.Request) []string {
-	// Get the list of all CRDs and build a list of the SSP ones
-	crds := &unstructured.UnstructuredList{}
-	crds.SetKind("CustomResourceDefinition")
-	crds.SetAPIVersion("apiextensions.k8s.io/v1")
-	err := sspRequest.Client.List(sspRequest.Context, crds)
+// legacyCRDKinds returns the kinds of the existing legacy CRDs
+func legacyCRDKinds(apis *clusterAPIs) []string {
 	foundKinds := make([]string, 0, len(kvsspCRDs))
-	if err != nil {
-		return nil
-	}
-
-	for _, item := range crds.Items {
-		name := item.GetName()
-		for crd, kind := range kvsspCRDs {
-			if crd == name {
-				foundKinds = append(foundKinds, kind)
-				break
-			}
+	for crd, kind := range kvsspCRDs {
+		if apis.hasCRD(crd) {
+			foundKinds = append(foundKinds, kind)
 		}
 	}
-
 	return foundKinds
 }
 
-func reconcileOperands(sspRequest *common.Request) ([]common.ResourceStatus, error) {
-	kinds := listExistingCRDKinds(sspRequest)
+func reconcileOperands(sspRequest *common.Request, apis *clusterAPIs, failureThreshold int) ([]common.ResourceStatus, error) {
+	kinds := legacyCRDKinds(apis)
 
 	// Mark existing CRs as paused
 	err := pauseCRs(sspRequest, kinds)
@@ -315,27 +595,58 @@ func reconcileOperands(sspRequest *common.Request) ([]common.ResourceStatus, err
 		return nil, err
 	}
 
-	// Reconcile all operands
+	// Reconcile all operands. A failing operand does not stop the reconciliation
+	// of the others, the errors are returned together.
 	allStatuses := make([]common.ResourceStatus, 0, len(sspOperands))
+	var errs []error
 	for _, operand := range sspOperands {
+		if missing := apis.missingAPIs(operand); len(missing) > 0 {
+			sspRequest.Logger.V(1).Info(fmt.Sprintf("Operand APIs are not available, skipping operand: %s", operand.Name()))
+			if operand.Enabled(sspRequest) {
+				setOperandMissingAPIsConditions(&sspRequest.Instance.Status, operand.Name(), apis.platformName(), missing)
+			} else {
+				removeOperandStatus(&sspRequest.Instance.Status, operand.Name())
+			}
+			continue
+		}
+
+		if !operand.Enabled(sspRequest) {
+			sspRequest.Logger.V(1).Info(fmt.Sprintf("Operand is disabled, removing its resources: %s", operand.Name()))
+			err := operand.Cleanup(sspRequest)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cleanup of operand %s: %w", operand.Name(), err))
+				continue
+			}
+			removeOperandStatus(&sspRequest.Instance.Status, operand.Name())
+			continue
+		}
+
 		sspRequest.Logger.V(1).Info(fmt.Sprintf("Reconciling operand: %s", operand.Name()))
 		statuses, err := operand.Reconcile(sspRequest)
 		if err != nil {
 			sspRequest.Logger.V(1).Info(fmt.Sprintf("Operand reconciliation failed: %s", err.Error()))
-			return nil, err
+			// Conflicts are retried immediately, they are not failures of the operand
+			if !errors.IsConflict(err) {
+				setOperandErrorConditions(&sspRequest.Instance.Status, operand.Name(), err, failureThreshold)
+				setOperandLastError(sspRequest, operand.Name(), err)
+			}
+			errs = append(errs, fmt.Errorf("operand %s: %w", operand.Name(), err))
+			continue
 		}
+		setOperandConditions(&sspRequest.Instance.Status, operand.Name(), operand.Version(sspRequest), statuses)
 		allStatuses = append(allStatuses, statuses...)
 	}
 
-	return allStatuses, nil
+	return allStatuses, utilerrors.Reduce(utilerrors.NewAggregate(errs))
 }
 
 func preUpdateStatus(request *common.Request) error {
 	operatorVersion := getOperatorVersion()
 
+	recordUpgradeStartedEvent(request, operatorVersion)
+
 	sspStatus := &request.Instance.Status
 	sspStatus.Phase = lifecycleapi.PhaseDeploying
-	sspStatus.ObservedGeneration = request.Instance.Generation
 	sspStatus.OperatorVersion = operatorVersion
 	sspStatus.TargetVersion = operatorVersion
 
@@ -344,6 +655,12 @@ func preUpdateStatus(request *common.Request) error {
 			request.Instance.Namespace, request.Instance.Name))
 	}
 	sspStatus.Paused = false
+	conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		Type:    ssp.ConditionPaused,
+		Status:  v1.ConditionFalse,
+		Reason:  "notPaused",
+		Message: "Reconciliation of SSP resources is not paused",
+	})
 
 	if !conditionsv1.IsStatusConditionPresentAndEqual(sspStatus.Conditions, conditionsv1.ConditionAvailable, v1.ConditionFalse) {
 		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
@@ -376,6 +693,52 @@ func preUpdateStatus(request *common.Request) error {
 }
 
 func updateStatus(request *common.Request, statuses []common.ResourceStatus) error {
+	recordWebhookConfigEvents(request, statuses)
+
+	sspStatus := &request.Instance.Status
+	deployed := setResourceConditions(&sspStatus.Conditions, statuses, "SSP resources")
+	setOperandsReady(sspStatus)
+	setSupersededSettingsCondition(sspStatus, &request.Instance.Spec)
+	sspStatus.RelatedObjects = getRelatedObjects(request, statuses)
+
+	// The generation is observed only after all operands were reconciled,
+	// so clients can wait until the latest spec is applied
+	sspStatus.ObservedGeneration = request.Instance.Generation
+	if deployed {
+		recordUpgradeCompletedEvent(request, getOperatorVersion())
+		sspStatus.Phase = lifecycleapi.PhaseDeployed
+		sspStatus.ObservedVersion = getOperatorVersion()
+		err := saveLastKnownGood(request, statuses)
+		if err != nil {
+			return err
+		}
+	} else {
+		sspStatus.Phase = lifecycleapi.PhaseDeploying
+	}
+
+	return request.Client.Status().Update(request.Context, request.Instance)
+}
+
+// setSupersededSettingsCondition reports the settings of the spec that have no effect.
+// The operands are deployed without them, so the Degraded condition is not changed.
+func setSupersededSettingsCondition(sspStatus *ssp.SSPStatus, spec *ssp.SSPSpec) {
+	messages := spec.SupersededSettings()
+	if len(messages) == 0 {
+		conditionsv1.RemoveStatusCondition(&sspStatus.Conditions, ssp.ConditionSupersededSettings)
+		return
+	}
+	conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		Type:    ssp.ConditionSupersededSettings,
+		Status:  v1.ConditionTrue,
+		Reason:  "superseded",
+		Message: strings.Join(messages, "; "),
+	})
+}
+
+// setResourceConditions sets the Available, Progressing and Degraded conditions
+// from the statuses of the resources. It returns true if all resources are available,
+// and none of them is progressing or degraded.
+func setResourceConditions(conditions *[]conditionsv1.Condition, statuses []common.ResourceStatus, resources string) bool {
 	notAvailable := make([]common.ResourceStatus, 0, len(statuses))
 	progressing := make([]common.ResourceStatus, 0, len(statuses))
 	degraded := make([]common.ResourceStatus, 0, len(statuses))
@@ -391,91 +754,82 @@ func updateStatus(request *common.Request, statuses []common.ResourceStatus) err
 		}
 	}
 
-	sspStatus := &request.Instance.Status
 	switch len(notAvailable) {
 	case 0:
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionTrue,
 			Reason:  "available",
-			Message: "All SSP resources are available",
+			Message: fmt.Sprintf("All %s are available", resources),
 		})
 	case 1:
 		status := notAvailable[0]
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionFalse,
 			Reason:  "available",
 			Message: prefixResourceTypeAndName(*status.NotAvailable, status.Resource),
 		})
 	default:
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionAvailable,
 			Status:  v1.ConditionFalse,
 			Reason:  "available",
-			Message: fmt.Sprintf("%d SSP resources are not available", len(notAvailable)),
+			Message: fmt.Sprintf("%d %s are not available", len(notAvailable), resources),
 		})
 	}
 
 	switch len(progressing) {
 	case 0:
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionFalse,
 			Reason:  "progressing",
-			Message: "No SSP resources are progressing",
+			Message: fmt.Sprintf("No %s are progressing", resources),
 		})
 	case 1:
 		status := progressing[0]
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionTrue,
 			Reason:  "progressing",
 			Message: prefixResourceTypeAndName(*status.Progressing, status.Resource),
 		})
 	default:
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionProgressing,
 			Status:  v1.ConditionTrue,
 			Reason:  "progressing",
-			Message: fmt.Sprintf("%d SSP resources are progressing", len(progressing)),
+			Message: fmt.Sprintf("%d %s are progressing", len(progressing), resources),
 		})
 	}
 
 	switch len(degraded) {
 	case 0:
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionFalse,
 			Reason:  "degraded",
-			Message: "No SSP resources are degraded",
+			Message: fmt.Sprintf("No %s are degraded", resources),
 		})
 	case 1:
 		status := degraded[0]
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionTrue,
 			Reason:  "degraded",
 			Message: prefixResourceTypeAndName(*status.Degraded, status.Resource),
 		})
 	default:
-		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+		conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
 			Type:    conditionsv1.ConditionDegraded,
 			Status:  v1.ConditionTrue,
 			Reason:  "degraded",
-			Message: fmt.Sprintf("%d SSP resources are degraded", len(degraded)),
+			Message: fmt.Sprintf("%d %s are degraded", len(degraded), resources),
 		})
 	}
 
-	sspStatus.ObservedGeneration = request.Instance.Generation
-	if len(notAvailable) == 0 && len(progressing) == 0 && len(degraded) == 0 {
-		sspStatus.Phase = lifecycleapi.PhaseDeployed
-		sspStatus.ObservedVersion = getOperatorVersion()
-	} else {
-		sspStatus.Phase = lifecycleapi.PhaseDeploying
-	}
-
-	return request.Client.Status().Update(request.Context, request.Instance)
+	return len(notAvailable) == 0 && len(progressing) == 0 && len(degraded) == 0
 }
 
 func prefixResourceTypeAndName(message string, resource controllerutil.Object) string {
@@ -486,21 +840,39 @@ func prefixResourceTypeAndName(message string, resource controllerutil.Object) s
 		message)
 }
 
-func handleError(request *common.Request, errParam error) (ctrl.Result, error) {
+// handleError reports the reconciliation error in the SSP status. If the error was caused
+// by an operand, the SSP CR is degraded only once the operand reached the failure threshold.
+func handleError(request *common.Request, errParam error, failureThreshold int) (ctrl.Result, error) {
 	if errParam == nil {
 		return ctrl.Result{}, nil
 	}
 
-	if errors.IsConflict(errParam) {
+	if isConflict(errParam) {
 		// Conflict happens if multiple components modify the same resource.
 		// Ignore the error and restart reconciliation.
 		return ctrl.Result{Requeue: true}, nil
 	}
 
 	// Default error handling, if error is not known
+	recordReconcileFailedEvent(request, errParam)
 	errorMsg := fmt.Sprintf("Error: %v", errParam)
 	sspStatus := &request.Instance.Status
-	sspStatus.Phase = lifecycleapi.PhaseDeploying
+	setOperandsReady(sspStatus)
+	if failures := consecutiveFailures(sspStatus); failures > 0 && failures < failureThreshold {
+		conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
+			Type:    conditionsv1.ConditionProgressing,
+			Status:  v1.ConditionTrue,
+			Reason:  "progressing",
+			Message: fmt.Sprintf("Retrying after error: %v", errParam),
+		})
+		err := request.Client.Status().Update(request.Context, request.Instance)
+		if err != nil {
+			request.Logger.Error(err, "Error updating SSP status.")
+		}
+		return ctrl.Result{}, errParam
+	}
+
+	sspStatus.Phase = lifecycleapi.PhaseError
 	conditionsv1.SetStatusCondition(&sspStatus.Conditions, conditionsv1.Condition{
 		Type:    conditionsv1.ConditionAvailable,
 		Status:  v1.ConditionFalse,
@@ -527,14 +899,62 @@ func handleError(request *common.Request, errParam error) (ctrl.Result, error) {
 	return ctrl.Result{}, errParam
 }
 
+// isConflict returns true if the error, or all of the aggregated errors, are conflicts
+func isConflict(err error) bool {
+	if aggregate, ok := err.(utilerrors.Aggregate); ok {
+		for _, aggregatedErr := range aggregate.Errors() {
+			if !errors.IsConflict(aggregatedErr) {
+				return false
+			}
+		}
+		return true
+	}
+	return errors.IsConflict(err)
+}
+
 func (r *SSPReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	r.SubresourceCache = common.VersionCache{}
+	r.SubresourceCache = common.NewVersionCache()
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r.clusterAPIs = newClusterAPIsCache(mgr.GetClient(), discoveryClient)
+	r.apiReader = mgr.GetAPIReader()
+
+	// Resources of operands are only watched if their APIs are available,
+	// otherwise the controller would fail to start
+	apis, err := listClusterAPIs(context.Background(), mgr.GetAPIReader(), discoveryClient)
+	if err != nil {
+		return err
+	}
+	r.Log.Info(fmt.Sprintf("Detected platform: %s", apis.platformName()))
+	if len(apis.failedGroupVersions) > 0 {
+		r.Log.Info("Discovery of some APIs failed, they are considered not served",
+			"groupVersions", apis.failedGroupVersions)
+	}
+	for _, operand := range sspOperands {
+		if missing := apis.missingAPIs(operand); len(missing) > 0 {
+			r.Log.Info(fmt.Sprintf("Operand %s is skipped, required APIs are not available: %s",
+				operand.Name(), strings.Join(missing, ", ")))
+		}
+	}
 
 	builder := ctrl.NewControllerManagedBy(mgr)
 	watchSspResource(builder)
-	watchClusterResources(builder)
-	watchNamespacedResources(builder)
-	return builder.Complete(r)
+	watchSspDeletion(builder, mgr.GetClient())
+	watchCRDs(builder, mgr.GetClient(), r.clusterAPIs)
+	watchTemplatesBundleConfigMaps(builder, mgr.GetClient())
+	watchSelectedNamespaces(builder, mgr.GetClient())
+	builder.WithOptions(controller.Options{
+		MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+		RateLimiter:             r.RateLimiter,
+	})
+	r.controller, err = builder.Build(r)
+	if err != nil {
+		return err
+	}
+	return r.watchOperandResources(apis)
 }
 
 func watchSspResource(bldr *ctrl.Builder) {
@@ -560,45 +980,69 @@ func watchSspResource(bldr *ctrl.Builder) {
 	bldr.For(&ssp.SSP{}, builder.WithPredicates(pred))
 }
 
-func watchNamespacedResources(builder *ctrl.Builder) {
-	watchResources(builder,
-		&handler.EnqueueRequestForOwner{
-			IsController: true,
-			OwnerType:    &ssp.SSP{},
-		},
-		operands.Operand.WatchTypes,
-	)
-}
+// watchTemplatesBundleConfigMaps triggers reconciliation when a ConfigMap
+// referenced by spec.commonTemplates.bundleRef changes.
+// These ConfigMaps are created by the user, so they are not owned by the SSP CR.
+func watchTemplatesBundleConfigMaps(builder *ctrl.Builder, c client.Client) {
+	builder.Watches(&source.Kind{Type: &v1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []ctrl.Request {
+			var ssps ssp.SSPList
+			err := c.List(context.TODO(), &ssps, client.InNamespace(obj.Meta.GetNamespace()))
+			if err != nil {
+				return nil
+			}
 
-func watchClusterResources(builder *ctrl.Builder) {
-	watchResources(builder,
-		&libhandler.EnqueueRequestForAnnotation{
-			Type: schema.GroupKind{
-				Group: "ssp.kubevirt.io",
-				Kind:  "SSP",
-			},
-		},
-		operands.Operand.WatchClusterTypes,
-	)
+			var requests []ctrl.Request
+			for _, sspObj := range ssps.Items {
+				bundleRef := sspObj.Spec.CommonTemplates.BundleRef
+				if bundleRef != nil && bundleRef.ConfigMapName == obj.Meta.GetName() {
+					requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+						Namespace: sspObj.Namespace,
+						Name:      sspObj.Name,
+					}})
+				}
+			}
+			return requests
+		}),
+	})
 }
 
-func watchResources(builder *ctrl.Builder, handler handler.EventHandler, watchTypesFunc func(operands.Operand) []runtime.Object) {
-	watchedTypes := make(map[reflect.Type]struct{})
-	for _, operand := range sspOperands {
-		for _, t := range watchTypesFunc(operand) {
-			if _, ok := watchedTypes[reflect.TypeOf(t)]; ok {
-				continue
+// watchSelectedNamespaces triggers reconciliation when a namespace listed
+// in spec.namespaces changes, so it is labeled for the validator webhook once it is created.
+// These namespaces are not owned by the SSP CR.
+func watchSelectedNamespaces(builder *ctrl.Builder, c client.Client) {
+	builder.Watches(&source.Kind{Type: &v1.Namespace{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []ctrl.Request {
+			var ssps ssp.SSPList
+			err := c.List(context.TODO(), &ssps)
+			if err != nil {
+				return nil
 			}
 
-			builder.Watches(&source.Kind{Type: t}, handler)
-			watchedTypes[reflect.TypeOf(t)] = struct{}{}
-		}
-	}
+			var requests []ctrl.Request
+			for _, sspObj := range ssps.Items {
+				for _, namespace := range sspObj.Spec.Namespaces {
+					if namespace == obj.Meta.GetName() {
+						requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+							Namespace: sspObj.Namespace,
+							Name:      sspObj.Name,
+						}})
+						break
+					}
+				}
+			}
+			return requests
+		}),
+	})
 }
 
 func InitScheme(scheme *runtime.Scheme) error {
+	err := ocpv1.Install(scheme)
+	if err != nil {
+		return err
+	}
 	for _, operand := range sspOperands {
-		err := operand.AddWatchTypesToScheme(scheme)
+		err = operand.AddWatchTypesToScheme(scheme)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var _ = Describe("Templates namespace migration", func() {
+	var request *common.Request
+
+	BeforeEach(func() {
+		s := newTestScheme()
+		instance := newTestSsp(testNamespace, testName)
+		instance.Annotations = map[string]string{
+			ssp.MigrateTemplatesNamespaceAnnotation: "true",
+			"other-annotation":                      "value",
+		}
+		request = newTestRequest(fake.NewFakeClientWithScheme(s, instance), s, instance, nil)
+		Expect(request.Client.Get(request.Context, request.NamespacedName, request.Instance)).To(Succeed())
+	})
+
+	getSsp := func() *ssp.SSP {
+		updated := &ssp.SSP{}
+		Expect(request.Client.Get(request.Context, request.NamespacedName, updated)).To(Succeed())
+		return updated
+	}
+
+	It("should remove the migration annotation", func() {
+		Expect(removeTemplatesNamespaceMigrationAnnotation(request)).To(Succeed())
+
+		updated := getSsp()
+		Expect(updated.Annotations).ToNot(HaveKey(ssp.MigrateTemplatesNamespaceAnnotation))
+		Expect(updated.Annotations).To(HaveKeyWithValue("other-annotation", "value"))
+		Expect(request.Instance.Annotations).To(Equal(updated.Annotations))
+	})
+
+	It("should keep the status computed during the reconciliation", func() {
+		request.Instance.Status.Phase = lifecycleapi.PhaseDeployed
+		Expect(removeTemplatesNamespaceMigrationAnnotation(request)).To(Succeed())
+
+		Expect(request.Instance.Status.Phase).To(Equal(lifecycleapi.PhaseDeployed))
+		Expect(request.Client.Status().Update(request.Context, request.Instance)).To(Succeed())
+		Expect(getSsp().Status.Phase).To(Equal(lifecycleapi.PhaseDeployed))
+	})
+
+	It("should not modify the SSP CR without the annotation", func() {
+		delete(request.Instance.Annotations, ssp.MigrateTemplatesNamespaceAnnotation)
+		resourceVersion := request.Instance.ResourceVersion
+
+		Expect(removeTemplatesNamespaceMigrationAnnotation(request)).To(Succeed())
+		Expect(getSsp().ResourceVersion).To(Equal(resourceVersion))
+	})
+})
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"fmt"
+
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+const AdoptionRefusedReason = "AdoptionRefused"
+
+// adoptionPolicy returns the adoption policy of the SSP CR, or the default if it is not set
+func adoptionPolicy(request *Request) ssp.AdoptionPolicy {
+	if request.Instance.Spec.AdoptionPolicy == "" {
+		return ssp.AdoptionPolicyAdopt
+	}
+	return request.Instance.Spec.AdoptionPolicy
+}
+
+// checkAdoption returns a message, if the found resource is not owned
+// by the SSP CR and cannot be adopted. Resources controlled by other
+// objects are never adopted.
+func checkAdoption(request *Request, found controllerutil.Object) StatusMessage {
+	if isOwnedByInstance(request.Instance, found) {
+		return nil
+	}
+
+	kind := cacheKeyFromObj(found).Kind
+	var message string
+	if controller := metav1.GetControllerOf(found); controller != nil {
+		message = fmt.Sprintf("Resource exists and is controlled by %s %s", controller.Kind, controller.Name)
+	} else if adoptionPolicy(request) == ssp.AdoptionPolicyRefuse {
+		message = fmt.Sprintf("Resource exists and is not owned by the SSP CR, adoption policy is %s", ssp.AdoptionPolicyRefuse)
+	} else {
+		request.Logger.Info(fmt.Sprintf("Adopting existing %s resource: %s", kind, found.GetName()))
+		return nil
+	}
+
+	request.Logger.Info(fmt.Sprintf("Not adopting %s resource %s: %s", kind, found.GetName(), message))
+	if request.Recorder != nil {
+		request.Recorder.Event(request.Instance, core.EventTypeWarning, AdoptionRefusedReason,
+			fmt.Sprintf("%s %s: %s", kind, found.GetName(), message))
+	}
+	return &message
+}
+
+// isOwnedByInstance returns true, if the resource has the owner annotations
+// or the controller reference of the SSP CR
+func isOwnedByInstance(instance *ssp.SSP, resource controllerutil.Object) bool {
+	if controller := metav1.GetControllerOf(resource); controller != nil && controller.UID == instance.UID {
+		return true
+	}
+	ownerName := instance.Namespace + "/" + instance.Name
+	return resource.GetAnnotations()[libhandler.NamespacedNameAnnotation] == ownerName
+}
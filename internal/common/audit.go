@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AuditEntry is a change done to an object by the operator
+type AuditEntry struct {
+	Change
+	Time        time.Time
+	ReconcileID string
+}
+
+func (e AuditEntry) String() string {
+	return fmt.Sprintf("%s [%s] %s", e.Time.UTC().Format(time.RFC3339), e.ReconcileID, e.Change.String())
+}
+
+// AuditClient is a client that records the changes done to objects.
+// Changes of the status subresource and dry-run requests are not recorded.
+type AuditClient struct {
+	client.Client
+	scheme      *runtime.Scheme
+	reconcileID string
+
+	lock    sync.Mutex
+	entries []AuditEntry
+}
+
+var _ client.Client = &AuditClient{}
+
+func NewAuditClient(c client.Client, scheme *runtime.Scheme, reconcileID string) *AuditClient {
+	return &AuditClient{
+		Client:      c,
+		scheme:      scheme,
+		reconcileID: reconcileID,
+	}
+}
+
+// Entries returns the recorded changes, in the order they were done
+func (c *AuditClient) Entries() []AuditEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entries := make([]AuditEntry, len(c.entries))
+	copy(entries, c.entries)
+	return entries
+}
+
+func (c *AuditClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	err := c.Client.Create(ctx, obj, opts...)
+	if err != nil {
+		return err
+	}
+	createOptions := &client.CreateOptions{}
+	createOptions.ApplyOptions(opts)
+	if isDryRun(createOptions.DryRun) {
+		return nil
+	}
+	return c.record(ChangeCreate, obj, nil)
+}
+
+func (c *AuditClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	updateOptions := &client.UpdateOptions{}
+	updateOptions.ApplyOptions(opts)
+	if isDryRun(updateOptions.DryRun) {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+
+	current, err := getCurrent(ctx, c.Client, obj)
+	if err != nil {
+		return err
+	}
+	err = c.Client.Update(ctx, obj, opts...)
+	if err != nil {
+		return err
+	}
+	return c.recordUpdate(current, obj)
+}
+
+func (c *AuditClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(opts)
+	if isDryRun(patchOptions.DryRun) {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	current, err := getCurrent(ctx, c.Client, obj)
+	if err != nil {
+		return err
+	}
+	err = c.Client.Patch(ctx, obj, patch, opts...)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		// Apply patches create missing objects
+		return c.record(ChangeCreate, obj, nil)
+	}
+	return c.recordUpdate(current, obj)
+}
+
+func (c *AuditClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	err := c.Client.Delete(ctx, obj, opts...)
+	if err != nil {
+		return err
+	}
+	deleteOptions := &client.DeleteOptions{}
+	deleteOptions.ApplyOptions(opts)
+	if isDryRun(deleteOptions.DryRun) {
+		return nil
+	}
+	return c.record(ChangeDelete, obj, nil)
+}
+
+// recordUpdate records the fields that differ between the current and the updated object.
+// Updates that did not change any field are not recorded.
+func (c *AuditClient) recordUpdate(current, updated runtime.Object) error {
+	if current == nil {
+		return c.record(ChangeUpdate, updated, nil)
+	}
+	fields, err := updatedFields(current, updated)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return c.record(ChangeUpdate, updated, fields)
+}
+
+func (c *AuditClient) record(operation string, obj runtime.Object, fields []string) error {
+	change, err := newChange(c.scheme, operation, obj, fields)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = append(c.entries, AuditEntry{
+		Change:      change,
+		Time:        time.Now(),
+		ReconcileID: c.reconcileID,
+	})
+	return nil
+}
+
+func isDryRun(dryRun []string) bool {
+	for _, value := range dryRun {
+		if value == metav1.DryRunAll {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,11 @@
 package common
 
-import "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+import (
+	"reflect"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
 
 type cacheKey struct {
 	Kind      string
@@ -11,12 +16,26 @@ type cacheKey struct {
 type cacheValue struct {
 	resourceVersion string
 	generation      int64
+	applied         controllerutil.Object
 }
 
-type VersionCache map[cacheKey]cacheValue
+// VersionCache stores the versions and the last applied state of applied resources.
+// It is safe for concurrent use.
+type VersionCache struct {
+	lock     sync.Mutex
+	versions map[cacheKey]cacheValue
+}
 
-func (v VersionCache) Contains(obj controllerutil.Object) bool {
-	cached, ok := v[cacheKeyFromObj(obj)]
+func NewVersionCache() *VersionCache {
+	return &VersionCache{
+		versions: map[cacheKey]cacheValue{},
+	}
+}
+
+func (v *VersionCache) Contains(obj controllerutil.Object) bool {
+	v.lock.Lock()
+	cached, ok := v.versions[cacheKeyFromObj(obj)]
+	v.lock.Unlock()
 	if !ok {
 		return false
 	}
@@ -30,25 +49,55 @@ func (v VersionCache) Contains(obj controllerutil.Object) bool {
 	return cached.generation == obj.GetGeneration()
 }
 
-func (v VersionCache) Add(obj controllerutil.Object) {
+// Has returns true if any version of the object is cached
+func (v *VersionCache) Has(obj controllerutil.Object) bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	_, ok := v.versions[cacheKeyFromObj(obj)]
+	return ok
+}
+
+func (v *VersionCache) Add(obj controllerutil.Object) {
 	kind := obj.GetObjectKind().GroupVersionKind().Kind
 	if kind == "" {
 		// Do not cache objects without kind
 		return
 	}
-	v[cacheKeyFromObj(obj)] = cacheValue{
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.versions[cacheKeyFromObj(obj)] = cacheValue{
 		resourceVersion: obj.GetResourceVersion(),
 		generation:      obj.GetGeneration(),
+		applied:         obj.DeepCopyObject().(controllerutil.Object),
 	}
 }
 
-func (v VersionCache) RemoveObj(obj controllerutil.Object) {
-	delete(v, cacheKeyFromObj(obj))
+// LastApplied returns the object as it was when it was last added, or nil if it is not cached
+func (v *VersionCache) LastApplied(obj controllerutil.Object) controllerutil.Object {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	cached, ok := v.versions[cacheKeyFromObj(obj)]
+	if !ok {
+		return nil
+	}
+	return cached.applied
+}
+
+func (v *VersionCache) RemoveObj(obj controllerutil.Object) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.versions, cacheKeyFromObj(obj))
 }
 
 func cacheKeyFromObj(obj controllerutil.Object) cacheKey {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		// Objects created by the operator do not set their kind,
+		// the name of the Go type is the same as the kind
+		kind = reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	}
 	return cacheKey{
-		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Kind:      kind,
 		Name:      obj.GetName(),
 		Namespace: obj.GetNamespace(),
 	}
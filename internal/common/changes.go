@@ -0,0 +1,108 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Operations of a change
+const (
+	ChangeCreate   = "create"
+	ChangeUpdate   = "update"
+	ChangeDelete   = "delete"
+	ChangeRecreate = "recreate"
+)
+
+// Change is a change done to an object, or one that would be done by a dry-run
+type Change struct {
+	Operation string
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Fields are the paths of the fields changed by an update
+	Fields []string
+}
+
+func (c Change) String() string {
+	name := c.Name
+	if c.Namespace != "" {
+		name = c.Namespace + "/" + c.Name
+	}
+	if len(c.Fields) == 0 {
+		return fmt.Sprintf("%s %s %s", c.Operation, c.Kind, name)
+	}
+	return fmt.Sprintf("%s %s %s: %s", c.Operation, c.Kind, name, strings.Join(c.Fields, ", "))
+}
+
+func newChange(scheme *runtime.Scheme, operation string, obj runtime.Object, fields []string) (Change, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return Change{}, err
+	}
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return Change{}, err
+	}
+	return Change{
+		Operation: operation,
+		Kind:      gvk.Kind,
+		Namespace: accessor.GetNamespace(),
+		Name:      accessor.GetName(),
+		Fields:    fields,
+	}, nil
+}
+
+// updatedFields returns the sorted paths of the fields that differ between the current
+// and the updated object. The objects are compared in both directions, so removed fields are found too.
+func updatedFields(current, updated runtime.Object) ([]string, error) {
+	currentObj, currentOk := current.(controllerutil.Object)
+	updatedObj, updatedOk := updated.(controllerutil.Object)
+	if !currentOk || !updatedOk {
+		return nil, fmt.Errorf("object %T does not have metadata", updated)
+	}
+
+	setFields, err := changedFields(updatedObj, currentObj)
+	if err != nil {
+		return nil, err
+	}
+	removedFields, err := changedFields(currentObj, updatedObj)
+	if err != nil {
+		return nil, err
+	}
+	fieldSet := map[string]struct{}{}
+	for _, field := range append(setFields, removedFields...) {
+		fieldSet[field] = struct{}{}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// getCurrent returns a copy of the object in the cluster, or nil if it does not exist
+func getCurrent(ctx context.Context, c client.Reader, obj runtime.Object) (runtime.Object, error) {
+	resource, ok := obj.(controllerutil.Object)
+	if !ok {
+		return nil, fmt.Errorf("object %T does not have metadata", obj)
+	}
+	current := newEmptyResource(resource)
+	err := c.Get(ctx, client.ObjectKey{Namespace: resource.GetNamespace(), Name: resource.GetName()}, current)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return current, nil
+}
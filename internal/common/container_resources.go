@@ -0,0 +1,21 @@
+package common
+
+import (
+	core "k8s.io/api/core/v1"
+)
+
+// AddResourceRequirements sets the compute resource requirements of all
+// containers and init containers in the pod spec. Nil requirements leave
+// the pod spec unchanged.
+func AddResourceRequirements(podSpec *core.PodSpec, resources *core.ResourceRequirements) {
+	if resources == nil {
+		return
+	}
+
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Resources = *resources.DeepCopy()
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Resources = *resources.DeepCopy()
+	}
+}
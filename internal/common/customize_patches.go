@@ -0,0 +1,64 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// applyCustomizePatches applies the patches from the SSP CR that target the resource.
+// The patched resource is returned, the original resource is not modified.
+func applyCustomizePatches(request *Request, resource controllerutil.Object) (controllerutil.Object, error) {
+	patches := request.Instance.Spec.CustomizePatches
+	if len(patches) == 0 {
+		return resource, nil
+	}
+
+	gvk, err := apiutil.GVKForObject(resource, request.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, patch := range patches {
+		if patch.Kind != gvk.Kind || patch.Name != resource.GetName() {
+			continue
+		}
+		if patch.Namespace != "" && patch.Namespace != resource.GetNamespace() {
+			continue
+		}
+
+		resource, err = applyJSONPatch(resource, patch.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply customize patch to %s %s: %w", gvk.Kind, patch.Name, err)
+		}
+	}
+	return resource, nil
+}
+
+func applyJSONPatch(resource controllerutil.Object, patchStr string) (controllerutil.Object, error) {
+	patch, err := jsonpatch.DecodePatch([]byte(patchStr))
+	if err != nil {
+		return nil, err
+	}
+	original, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := patch.Apply(original)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newEmptyResource(resource)
+	err = json.Unmarshal(patched, result)
+	if err != nil {
+		return nil, err
+	}
+	if result.GetName() != resource.GetName() || result.GetNamespace() != resource.GetNamespace() {
+		return nil, fmt.Errorf("the patch must not change the name or namespace of the resource")
+	}
+	return result, nil
+}
@@ -0,0 +1,17 @@
+package common
+
+import (
+	core "k8s.io/api/core/v1"
+)
+
+// AddDNSConfig sets the DNS policy and configuration of the pod spec,
+// if they are configured in the SSP CR
+func AddDNSConfig(podSpec *core.PodSpec, request *Request) {
+	spec := &request.Instance.Spec
+	if spec.DNSPolicy != "" {
+		podSpec.DNSPolicy = spec.DNSPolicy
+	}
+	if spec.DNSConfig != nil {
+		podSpec.DNSConfig = spec.DNSConfig.DeepCopy()
+	}
+}
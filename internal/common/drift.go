@@ -0,0 +1,148 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	DriftRevertedReason = "DriftReverted"
+
+	unknownFieldManager = "unknown"
+)
+
+// reportDrift emits an event and logs, if the operator reverted fields
+// of the found resource that were changed by somebody else.
+// Fields that differ from the last applied resource are changed by the operator itself, so they are not reported.
+func reportDrift(request *Request, expected, found, lastApplied controllerutil.Object) {
+	fields, err := changedFields(expected, found)
+	if err != nil {
+		request.Logger.V(1).Info(fmt.Sprintf("Could not compare resource fields: %v", err))
+		return
+	}
+	renderedFields, err := changedFields(expected, lastApplied)
+	if err != nil {
+		request.Logger.V(1).Info(fmt.Sprintf("Could not compare resource fields: %v", err))
+		return
+	}
+	fields = subtractFields(fields, renderedFields)
+	if len(fields) == 0 {
+		return
+	}
+
+	kind := cacheKeyFromObj(expected).Kind
+	manager := lastFieldManager(found)
+	message := fmt.Sprintf("Reverted fields modified by %q: %s", manager, strings.Join(fields, ", "))
+	request.Logger.Info(fmt.Sprintf("Reverted modified %s resource: %s", kind, found.GetName()),
+		"fields", fields, "manager", manager)
+	if request.Recorder != nil {
+		request.Recorder.Event(found, core.EventTypeWarning, DriftRevertedReason, message)
+	}
+}
+
+// changedFields returns the sorted paths of the fields set in the expected
+// resource, that have a different value in the found resource.
+// Lists are compared as a whole. The status and server set metadata are ignored.
+func changedFields(expected, found controllerutil.Object) ([]string, error) {
+	expectedMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected)
+	if err != nil {
+		return nil, err
+	}
+	foundMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(found)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(expectedMap, "status")
+	delete(expectedMap, "apiVersion")
+	delete(expectedMap, "kind")
+	if metadata, ok := expectedMap["metadata"].(map[string]interface{}); ok {
+		expectedMap["metadata"] = map[string]interface{}{
+			"labels":          metadata["labels"],
+			"annotations":     metadata["annotations"],
+			"ownerReferences": metadata["ownerReferences"],
+		}
+	}
+
+	var fields []string
+	collectChangedFields("", expectedMap, foundMap, &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// subtractFields returns the fields that are not in the removed fields
+func subtractFields(fields, removed []string) []string {
+	removedSet := make(map[string]struct{}, len(removed))
+	for _, field := range removed {
+		removedSet[field] = struct{}{}
+	}
+	var result []string
+	for _, field := range fields {
+		if _, ok := removedSet[field]; !ok {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+func collectChangedFields(path string, expected, found map[string]interface{}, fields *[]string) {
+	for key, expectedVal := range expected {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		collectChangedValue(fieldPath, expectedVal, found[key], fields)
+	}
+}
+
+func collectChangedValue(path string, expected, found interface{}, fields *[]string) {
+	switch expectedVal := expected.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		foundMap, _ := found.(map[string]interface{})
+		collectChangedFields(path, expectedVal, foundMap, fields)
+	case []interface{}:
+		// Fields defaulted by the server in list items are ignored,
+		// if the lists have the same length
+		foundList, _ := found.([]interface{})
+		if len(expectedVal) != len(foundList) {
+			*fields = append(*fields, path)
+			return
+		}
+		for i := range expectedVal {
+			collectChangedValue(fmt.Sprintf("%s[%d]", path, i), expectedVal[i], foundList[i], fields)
+		}
+	default:
+		if !reflect.DeepEqual(expected, found) {
+			*fields = append(*fields, path)
+		}
+	}
+}
+
+// lastFieldManager returns the field manager, other than the operator,
+// that updated the resource last.
+func lastFieldManager(obj controllerutil.Object) string {
+	var last *metav1.ManagedFieldsEntry
+	entries := obj.GetManagedFields()
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Manager == FieldManager {
+			continue
+		}
+		if last == nil || last.Time.Before(entry.Time) {
+			last = entry
+		}
+	}
+	if last == nil {
+		return unknownFieldManager
+	}
+	return last.Manager
+}
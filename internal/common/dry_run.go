@@ -0,0 +1,148 @@
+package common
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DryRunClient is a client that does not modify the cluster.
+// Requests that modify objects are sent with the server-side dry-run option,
+// and the changes that would be done are recorded.
+type DryRunClient struct {
+	client.Client
+	scheme *runtime.Scheme
+
+	lock    sync.Mutex
+	changes []Change
+}
+
+var _ client.Client = &DryRunClient{}
+
+func NewDryRunClient(c client.Client, scheme *runtime.Scheme) *DryRunClient {
+	return &DryRunClient{
+		Client: c,
+		scheme: scheme,
+	}
+}
+
+// Changes returns the recorded changes, sorted by kind, namespace and name
+func (c *DryRunClient) Changes() []Change {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	changes := make([]Change, len(c.changes))
+	copy(changes, c.changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		if changes[i].Namespace != changes[j].Namespace {
+			return changes[i].Namespace < changes[j].Namespace
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func (c *DryRunClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	err := c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...)
+	if err != nil {
+		return err
+	}
+	return c.record(ChangeCreate, obj, nil)
+}
+
+func (c *DryRunClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	current, err := getCurrent(ctx, c.Client, obj)
+	if err != nil {
+		return err
+	}
+	err = c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...)
+	if err != nil {
+		return err
+	}
+	return c.recordUpdate(current, obj)
+}
+
+func (c *DryRunClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	current, err := getCurrent(ctx, c.Client, obj)
+	if err != nil {
+		return err
+	}
+	err = c.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		// Apply patches create missing objects
+		return c.record(ChangeCreate, obj, nil)
+	}
+	return c.recordUpdate(current, obj)
+}
+
+func (c *DryRunClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	err := c.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...)
+	if err != nil {
+		return err
+	}
+	return c.record(ChangeDelete, obj, nil)
+}
+
+func (c *DryRunClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	return c.Client.DeleteAllOf(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+// Status returns a writer that does not modify the status of objects
+func (c *DryRunClient) Status() client.StatusWriter {
+	return &dryRunStatusWriter{client: c}
+}
+
+// RecordRecreate records that the object would be deleted and created again
+func (c *DryRunClient) RecordRecreate(obj runtime.Object) error {
+	return c.record(ChangeRecreate, obj, nil)
+}
+
+// recordUpdate records the fields that differ between the current and the updated object
+func (c *DryRunClient) recordUpdate(current, updated runtime.Object) error {
+	if current == nil {
+		return c.record(ChangeUpdate, updated, nil)
+	}
+	fields, err := updatedFields(current, updated)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return c.record(ChangeUpdate, updated, fields)
+}
+
+func (c *DryRunClient) record(operation string, obj runtime.Object, fields []string) error {
+	change, err := newChange(c.scheme, operation, obj, fields)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.changes = append(c.changes, change)
+	return nil
+}
+
+type dryRunStatusWriter struct {
+	client *DryRunClient
+}
+
+var _ client.StatusWriter = &dryRunStatusWriter{}
+
+func (w *dryRunStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return w.client.Client.Status().Update(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (w *dryRunStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.client.Client.Status().Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+}
@@ -5,7 +5,8 @@ import (
 )
 
 const (
-	OperatorVersionKey = "OPERATOR_VERSION"
+	OperatorVersionKey        = "OPERATOR_VERSION"
+	OperatorServiceAccountKey = "OPERATOR_SERVICE_ACCOUNT"
 
 	TemplateValidatorImageKey    = "VALIDATOR_IMAGE"
 	KubevirtNodeLabellerImageKey = "NODE_LABELLER_IMAGE"
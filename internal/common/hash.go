@@ -0,0 +1,82 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ResourceHashAnnotation contains the hash of the resource rendered by the operator
+const ResourceHashAnnotation = "ssp.kubevirt.io/resource-hash"
+
+// setResourceHash sets the hash annotation on the resource.
+// The hash is computed from the resource without the annotation.
+func setResourceHash(resource controllerutil.Object) error {
+	annotations := resource.GetAnnotations()
+	delete(annotations, ResourceHashAnnotation)
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ResourceHashAnnotation] = hex.EncodeToString(sum[:])
+	resource.SetAnnotations(annotations)
+	return nil
+}
+
+// isModifiedByOthers returns true, if fields of the expected resource may have been modified
+// by another field manager after the operator applied the resource.
+// The operator applies with forced ownership, so after an apply it owns all fields it sets.
+// A field modified or removed by another manager is not owned by the operator anymore,
+// and a modified field is owned by the other manager.
+func isModifiedByOthers(found, expected controllerutil.Object) bool {
+	var applied *metav1.ManagedFieldsEntry
+	entries := found.GetManagedFields()
+	for i := range entries {
+		if entries[i].Manager == FieldManager && entries[i].Operation == metav1.ManagedFieldsOperationApply {
+			applied = &entries[i]
+			break
+		}
+	}
+	if applied == nil {
+		return true
+	}
+
+	expectedPaths, err := expectedFieldPaths(expected)
+	if err != nil {
+		return true
+	}
+	appliedFields, err := decodeFieldSet(applied)
+	if err != nil {
+		return true
+	}
+	for _, path := range expectedPaths {
+		if !fieldSetContains(appliedFields, path) {
+			return true
+		}
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry == applied {
+			continue
+		}
+		fields, err := decodeFieldSet(entry)
+		if err != nil {
+			return true
+		}
+		for _, owned := range ownedFieldPaths(fields, nil) {
+			if owned.overlaps(expectedPaths) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+// removeIgnoredFields removes the fields ignored in the SSP CR from the resource.
+// Fields that are not applied are not owned by the operator, so values set
+// by other components are kept. The original resource is not modified.
+func removeIgnoredFields(request *Request, resource controllerutil.Object) (controllerutil.Object, error) {
+	ignoredFields := request.Instance.Spec.IgnoredFields
+	if len(ignoredFields) == 0 {
+		return resource, nil
+	}
+
+	gvk, err := apiutil.GVKForObject(resource, request.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, ignored := range ignoredFields {
+		if ignored.Kind != gvk.Kind || ignored.Name != resource.GetName() {
+			continue
+		}
+		if ignored.Namespace != "" && ignored.Namespace != resource.GetNamespace() {
+			continue
+		}
+		paths = append(paths, ignored.Paths...)
+	}
+	if len(paths) == 0 {
+		return resource, nil
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+	removedFields := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		fields, err := ssp.ParseFieldPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignored field of %s %s: %w", gvk.Kind, resource.GetName(), err)
+		}
+		unstructured.RemoveNestedField(obj, fields...)
+		removedFields = append(removedFields, fields)
+	}
+
+	result := newEmptyResource(resource)
+	if u, ok := result.(*unstructured.Unstructured); ok {
+		u.SetUnstructuredContent(obj)
+		return u, nil
+	}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Required fields of typed resources are set to their zero value when converted back,
+	// so they would be applied with the zero value instead of being ignored
+	converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(result)
+	if err != nil {
+		return nil, err
+	}
+	for i, fields := range removedFields {
+		if _, found, _ := unstructured.NestedFieldNoCopy(converted, fields...); found {
+			return nil, fmt.Errorf("ignored field %s of %s %s cannot be ignored, because it is required",
+				paths[i], gvk.Kind, resource.GetName())
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// +kubebuilder:rbac:groups=kubevirt.io,resources=kubevirts,verbs=get;list;watch
+
+// KubeVirtGVK is the GroupVersionKind of the KubeVirt CR.
+// KubeVirt types are not vendored, so the CR is read as an unstructured object.
+var KubeVirtGVK = schema.GroupVersionKind{
+	Group:   "kubevirt.io",
+	Version: "v1",
+	Kind:    "KubeVirt",
+}
+
+// KubeVirtCRD is the name of the CRD of the KubeVirt CR
+const KubeVirtCRD = "kubevirts.kubevirt.io"
+
+// KubeVirtConfig contains the settings of the KubeVirt CR that affect the operands
+type KubeVirtConfig struct {
+	// FeatureGates are the feature gates enabled in KubeVirt
+	FeatureGates []string
+
+	// DefaultArchitecture is the architecture of VMs that do not set one, empty if not configured
+	DefaultArchitecture string
+
+	// CARotateInterval and CAOverlapInterval are the duration and renewBefore
+	// of the self-signed KubeVirt CA, zero if not configured
+	CARotateInterval  time.Duration
+	CAOverlapInterval time.Duration
+
+	// CertRotateInterval is the duration of the KubeVirt serving certificates, zero if not configured
+	CertRotateInterval time.Duration
+}
+
+// HasFeatureGate returns true if the feature gate is enabled in KubeVirt
+func (k *KubeVirtConfig) HasFeatureGate(featureGate string) bool {
+	for _, enabled := range k.FeatureGates {
+		if enabled == featureGate {
+			return true
+		}
+	}
+	return false
+}
+
+// NewKubeVirtConfig reads the relevant settings from the KubeVirt CR
+func NewKubeVirtConfig(kubevirt *unstructured.Unstructured) (*KubeVirtConfig, error) {
+	config := &KubeVirtConfig{}
+	var err error
+	config.FeatureGates, _, err = unstructured.NestedStringSlice(kubevirt.Object,
+		"spec", "configuration", "developerConfiguration", "featureGates")
+	if err != nil {
+		return nil, err
+	}
+	config.DefaultArchitecture, _, err = unstructured.NestedString(kubevirt.Object,
+		"spec", "configuration", "architectureConfiguration", "defaultArchitecture")
+	if err != nil {
+		return nil, err
+	}
+
+	selfSigned := []string{"spec", "certificateRotateStrategy", "selfSigned"}
+	for _, field := range []struct {
+		path   []string
+		target *time.Duration
+	}{
+		{[]string{"ca", "duration"}, &config.CARotateInterval},
+		{[]string{"ca", "renewBefore"}, &config.CAOverlapInterval},
+		{[]string{"server", "duration"}, &config.CertRotateInterval},
+	} {
+		*field.target, err = nestedDuration(kubevirt, append(selfSigned, field.path...)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+func nestedDuration(obj *unstructured.Unstructured, fields ...string) (time.Duration, error) {
+	value, found, err := unstructured.NestedString(obj.Object, fields...)
+	if err != nil || !found {
+		return 0, err
+	}
+	return time.ParseDuration(value)
+}
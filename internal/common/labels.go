@@ -36,15 +36,43 @@ func AddAppLabels(requestInstance *v1beta1.SSP, name string, component AppCompon
 	labels[AppKubernetesNameLabel] = name
 	labels[AppKubernetesComponentLabel] = component.String()
 	labels[AppKubernetesManagedByLabel] = "ssp-operator"
+	obj.SetLabels(labels)
 
 	return obj
 }
 
+// AddCommonMetadata adds the user defined common labels and
+// annotations from the SSP CR to the provided obj
+func AddCommonMetadata(requestInstance *v1beta1.SSP, obj controllerutil.Object) controllerutil.Object {
+	if len(requestInstance.Spec.CommonLabels) > 0 {
+		labels := getOrCreateLabels(obj)
+		for key, val := range requestInstance.Spec.CommonLabels {
+			labels[key] = val
+		}
+		obj.SetLabels(labels)
+	}
+
+	if len(requestInstance.Spec.CommonAnnotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for key, val := range requestInstance.Spec.CommonAnnotations {
+			annotations[key] = val
+		}
+		// Unstructured objects return a copy of their maps, so they have to be set back
+		obj.SetAnnotations(annotations)
+	}
+
+	return obj
+}
+
+// getOrCreateLabels returns the labels of obj. Unstructured objects return
+// a copy of their labels, so the result has to be set back with obj.SetLabels.
 func getOrCreateLabels(obj controllerutil.Object) map[string]string {
 	labels := obj.GetLabels()
 	if labels == nil {
 		labels = map[string]string{}
-		obj.SetLabels(labels)
 	}
 	return labels
 }
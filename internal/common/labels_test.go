@@ -76,3 +76,55 @@ var _ = Describe("AddAppLabels", func() {
 		Expect(labels[AppKubernetesManagedByLabel]).To(Equal("ssp-operator"))
 	})
 })
+
+var _ = Describe("AddCommonMetadata", func() {
+	var instance *ssp.SSP
+
+	BeforeEach(func() {
+		instance = &ssp.SSP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: ssp.SSPSpec{
+				CommonLabels: map[string]string{
+					"cost-center": "1234",
+				},
+				CommonAnnotations: map[string]string{
+					"owner": "team-a",
+				},
+			},
+		}
+	})
+
+	It("adds common labels and annotations", func() {
+		obj := AddCommonMetadata(instance, &v1.ConfigMap{})
+
+		Expect(obj.GetLabels()).To(HaveKeyWithValue("cost-center", "1234"))
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue("owner", "team-a"))
+	})
+
+	It("keeps existing labels and annotations", func() {
+		obj := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{"existing": "label"},
+				Annotations: map[string]string{"existing": "annotation"},
+			},
+		}
+		AddCommonMetadata(instance, obj)
+
+		Expect(obj.GetLabels()).To(HaveKeyWithValue("existing", "label"))
+		Expect(obj.GetLabels()).To(HaveKeyWithValue("cost-center", "1234"))
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue("existing", "annotation"))
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue("owner", "team-a"))
+	})
+
+	It("does not create empty maps", func() {
+		instance.Spec.CommonLabels = nil
+		instance.Spec.CommonAnnotations = nil
+		obj := AddCommonMetadata(instance, &v1.ConfigMap{})
+
+		Expect(obj.GetLabels()).To(BeNil())
+		Expect(obj.GetAnnotations()).To(BeNil())
+	})
+})
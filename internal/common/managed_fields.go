@@ -0,0 +1,235 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// LegacyFieldManager is the field manager of operator versions that updated resources
+// instead of applying them. They did not set a field manager, so the API server
+// used the name of the operator binary from the user agent.
+const LegacyFieldManager = "manager"
+
+// migrateManagedFields moves the fields owned by the legacy field manager to the
+// apply field manager of the operator. Without it, fields that the operator stopped
+// setting would stay owned by the legacy manager and would never be removed by apply.
+// It returns true if the managed fields of the found resource were updated.
+func migrateManagedFields(request *Request, found controllerutil.Object) (bool, error) {
+	if _, ok := request.Client.(*DryRunClient); ok {
+		return false, nil
+	}
+	gvk, err := apiutil.GVKForObject(found, request.Scheme)
+	if err != nil {
+		return false, err
+	}
+	entries, migrated, err := migratedManagedFields(found.GetManagedFields(), gvk.GroupVersion().String())
+	if err != nil || !migrated {
+		return false, err
+	}
+	found.SetManagedFields(entries)
+	err = request.Client.Update(request.Context, found, client.FieldOwner(FieldManager))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// migratedManagedFields returns the entries with the fields of the legacy field manager
+// merged into the apply entry of the operator. The status is not migrated, because
+// it is not applied by the operator.
+func migratedManagedFields(entries []metav1.ManagedFieldsEntry, apiVersion string) ([]metav1.ManagedFieldsEntry, bool, error) {
+	fields := map[string]interface{}{}
+	var applied *metav1.ManagedFieldsEntry
+	result := make([]metav1.ManagedFieldsEntry, 0, len(entries))
+	migrated := false
+	for i := range entries {
+		entry := entries[i]
+		isLegacy := entry.Manager == LegacyFieldManager && entry.Operation == metav1.ManagedFieldsOperationUpdate
+		isApplied := entry.Manager == FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply
+		if !isLegacy && !isApplied {
+			result = append(result, entry)
+			continue
+		}
+		entryFields, err := decodeFieldSet(&entry)
+		if err != nil {
+			return nil, false, err
+		}
+		if isLegacy {
+			delete(entryFields, "f:status")
+		}
+		mergeFieldSets(fields, entryFields)
+		if isLegacy {
+			migrated = true
+			continue
+		}
+		applied = &entry
+	}
+	if !migrated {
+		return entries, false, nil
+	}
+
+	if applied == nil {
+		applied = &metav1.ManagedFieldsEntry{
+			Manager:    FieldManager,
+			Operation:  metav1.ManagedFieldsOperationApply,
+			APIVersion: apiVersion,
+			FieldsType: "FieldsV1",
+		}
+	}
+	now := metav1.Now()
+	applied.Time = &now
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	applied.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+	return append(result, *applied), true, nil
+}
+
+// mergeFieldSets adds the fields of the source set to the target set.
+// A FieldsV1 set is a tree of objects, where every key is a field, an item or ".".
+func mergeFieldSets(target, source map[string]interface{}) {
+	for key, sourceVal := range source {
+		sourceMap, _ := sourceVal.(map[string]interface{})
+		targetMap, ok := target[key].(map[string]interface{})
+		if !ok || sourceMap == nil {
+			if !ok {
+				target[key] = sourceVal
+			}
+			continue
+		}
+		mergeFieldSets(targetMap, sourceMap)
+	}
+}
+
+// fieldPath is a path in a FieldsV1 set, like ["f:metadata", "f:labels", "f:app"]
+type fieldPath []string
+
+func (p fieldPath) hasPrefix(prefix fieldPath) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ownedFieldPath is a field owned by a field manager
+type ownedFieldPath struct {
+	path fieldPath
+
+	// onlyNode is true if the manager owns only the existence of the map or list,
+	// but not its items
+	onlyNode bool
+}
+
+// overlaps returns true if the owned field is one of the paths,
+// or if it is inside or contains one of them
+func (o ownedFieldPath) overlaps(paths []fieldPath) bool {
+	for _, path := range paths {
+		if o.onlyNode {
+			if len(path) == len(o.path) && path.hasPrefix(o.path) {
+				return true
+			}
+		} else if path.hasPrefix(o.path) || o.path.hasPrefix(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeFieldSet(entry *metav1.ManagedFieldsEntry) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if entry.FieldsV1 == nil {
+		return fields, nil
+	}
+	err := json.Unmarshal(entry.FieldsV1.Raw, &fields)
+	return fields, err
+}
+
+// expectedFieldPaths returns the paths of the fields set in the resource.
+// Lists are not entered, because their type is not known, so a list is a single field.
+// The status and the identity of the resource are skipped, they are not owned by apply.
+func expectedFieldPaths(resource controllerutil.Object) ([]fieldPath, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	delete(obj, "apiVersion")
+	delete(obj, "kind")
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "name")
+		delete(metadata, "namespace")
+	}
+
+	var paths []fieldPath
+	var walk func(obj map[string]interface{}, prefix fieldPath)
+	walk = func(obj map[string]interface{}, prefix fieldPath) {
+		for key, val := range obj {
+			if val == nil {
+				continue
+			}
+			path := append(append(fieldPath{}, prefix...), "f:"+key)
+			if valMap, ok := val.(map[string]interface{}); ok && len(valMap) > 0 {
+				walk(valMap, path)
+				continue
+			}
+			paths = append(paths, path)
+		}
+	}
+	walk(obj, nil)
+	return paths, nil
+}
+
+// fieldSetContains returns true if the field, or a map containing it, is in the set
+func fieldSetContains(fields map[string]interface{}, path fieldPath) bool {
+	current := fields
+	for _, key := range path {
+		val, ok := current[key]
+		if !ok {
+			return false
+		}
+		current, _ = val.(map[string]interface{})
+		if len(current) == 0 {
+			// The whole field is owned
+			return true
+		}
+	}
+	return true
+}
+
+// ownedFieldPaths returns the fields in the set. Items of lists are returned as their list.
+func ownedFieldPaths(fields map[string]interface{}, prefix fieldPath) []ownedFieldPath {
+	var result []ownedFieldPath
+	for key, val := range fields {
+		switch {
+		case key == ".":
+			result = append(result, ownedFieldPath{path: prefix, onlyNode: true})
+		case !strings.HasPrefix(key, "f:"):
+			// List item, identified by a key, value or index
+			result = append(result, ownedFieldPath{path: prefix})
+		default:
+			path := append(append(fieldPath{}, prefix...), key)
+			valMap, _ := val.(map[string]interface{})
+			if len(valMap) == 0 {
+				result = append(result, ownedFieldPath{path: path})
+				continue
+			}
+			result = append(result, ownedFieldPaths(valMap, path)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,18 @@
+package common
+
+import (
+	core "k8s.io/api/core/v1"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+)
+
+// AddPlacementFields sets the node scheduling fields of the pod spec
+// from the provided node placement. Nil placement leaves the pod spec unchanged.
+func AddPlacementFields(podSpec *core.PodSpec, nodePlacement *lifecycleapi.NodePlacement) {
+	if nodePlacement == nil {
+		return
+	}
+
+	podSpec.Affinity = nodePlacement.Affinity
+	podSpec.NodeSelector = nodePlacement.NodeSelector
+	podSpec.Tolerations = nodePlacement.Tolerations
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=list
+
+// RunningImageID returns the image ID of the container running in the pods
+// in the namespace of the request, that match the labels. The image ID contains the digest
+// of the pulled image, so it identifies the image even if it is referenced by a tag.
+// The default image is returned if no pod is running, or if the pods run
+// different images, for example during a rollout.
+func RunningImageID(request *Request, matchingLabels map[string]string, containerName string, defaultImage string) string {
+	reader := request.APIReader
+	if reader == nil {
+		reader = request.Client
+	}
+	pods := &core.PodList{}
+	err := reader.List(request.Context, pods, client.InNamespace(request.Namespace), client.MatchingLabels(matchingLabels))
+	if err != nil {
+		request.Logger.V(1).Info(fmt.Sprintf("Could not list pods: %v", err))
+		return defaultImage
+	}
+
+	imageID := ""
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != core.PodRunning {
+			continue
+		}
+		podImageID := containerImageID(pod, containerName)
+		if podImageID == "" || (imageID != "" && podImageID != imageID) {
+			return defaultImage
+		}
+		imageID = podImageID
+	}
+	if imageID == "" {
+		return defaultImage
+	}
+	return imageID
+}
+
+// containerImageID returns the image ID of the container or init container.
+// The prefix of the container runtime, like docker-pullable://, is removed.
+func containerImageID(pod *core.Pod, containerName string) string {
+	for _, statuses := range [][]core.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.Name != containerName {
+				continue
+			}
+			if index := strings.Index(status.ImageID, "://"); index >= 0 {
+				return status.ImageID[index+len("://"):]
+			}
+			return status.ImageID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"strings"
+
+	core "k8s.io/api/core/v1"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+// GetProxy returns the proxy configured in the SSP CR,
+// or the proxy of the cluster if the SSP CR does not configure one.
+func GetProxy(request *Request) *ssp.Proxy {
+	if proxy := request.Instance.Spec.Proxy; proxy != nil {
+		return proxy
+	}
+	return request.ClusterProxy
+}
+
+// AddProxyEnv sets the proxy environment variables of all containers
+// and init containers in the pod spec. Both upper and lower case
+// variables are set, because tools differ in which ones they read.
+func AddProxyEnv(podSpec *core.PodSpec, proxy *ssp.Proxy) {
+	if proxy == nil {
+		return
+	}
+
+	var env []core.EnvVar
+	for _, variable := range []struct {
+		name  string
+		value string
+	}{
+		{"HTTP_PROXY", proxy.HTTPProxy},
+		{"HTTPS_PROXY", proxy.HTTPSProxy},
+		{"NO_PROXY", proxy.NoProxy},
+	} {
+		if variable.value == "" {
+			continue
+		}
+		env = append(env,
+			core.EnvVar{Name: variable.name, Value: variable.value},
+			core.EnvVar{Name: strings.ToLower(variable.name), Value: variable.value},
+		)
+	}
+
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Env = append(podSpec.InitContainers[i].Env, env...)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, env...)
+	}
+}
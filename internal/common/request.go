@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -18,5 +19,22 @@ type Request struct {
 	Context      context.Context
 	Instance     *ssp.SSP
 	Logger       logr.Logger
-	VersionCache VersionCache
+	Recorder     record.EventRecorder
+	VersionCache *VersionCache
+
+	// APIReader reads objects that are not worth caching, like pods. If it is nil, Client is used.
+	APIReader client.Reader
+
+	// MaxConcurrentResources is the number of independent resources
+	// of an operand that can be reconciled concurrently
+	MaxConcurrentResources int
+
+	// ClusterProxy is the proxy configured for the OpenShift cluster, it is nil if there is none
+	ClusterProxy *ssp.Proxy
+
+	// KubeVirt contains the settings of the KubeVirt CR, it is nil if there is none
+	KubeVirt *KubeVirtConfig
+
+	// SingleNode is true if workloads of the cluster run on a single node
+	SingleNode bool
 }
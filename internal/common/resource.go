@@ -3,13 +3,24 @@ package common
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	libhandler "github.com/operator-framework/operator-lib/handler"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// FieldManager is the name of the field manager used to apply resources
+const FieldManager = "ssp-operator"
+
 type StatusMessage = *string
 
 type ResourceStatus struct {
@@ -17,6 +28,16 @@ type ResourceStatus struct {
 	Progressing  StatusMessage
 	NotAvailable StatusMessage
 	Degraded     StatusMessage
+
+	// RequeueAfter requests another reconciliation after the duration,
+	// e.g. to renew a certificate. Zero means no requeue is needed.
+	RequeueAfter time.Duration
+
+	// Removed is true if the resource was deleted, because it is not needed
+	Removed bool
+
+	// Operation is the operation done on the resource during reconciliation
+	Operation controllerutil.OperationResult
 }
 
 type ReconcileFunc = func(*Request) (ResourceStatus, error)
@@ -33,6 +54,49 @@ func CollectResourceStatus(request *Request, funcs ...ReconcileFunc) ([]Resource
 	return res, nil
 }
 
+// CollectResourceStatusConcurrently calls the independent reconcile functions
+// using up to request.MaxConcurrentResources workers. The statuses are returned
+// in the order of the functions.
+func CollectResourceStatusConcurrently(request *Request, funcs ...ReconcileFunc) ([]ResourceStatus, error) {
+	workers := request.MaxConcurrentResources
+	if workers > len(funcs) {
+		workers = len(funcs)
+	}
+	if workers <= 1 {
+		return CollectResourceStatus(request, funcs...)
+	}
+
+	statuses := make([]ResourceStatus, len(funcs))
+	errs := make([]error, len(funcs))
+	indexes := make(chan int, len(funcs))
+	for i := range funcs {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				statuses[i], errs[i] = funcs[i](request)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// ResourceUpdateFunc is called before an existing resource is applied.
+// It can copy fields from the found resource to the expected resource,
+// if they should keep the values set by other components.
 type ResourceUpdateFunc = func(expected, found controllerutil.Object)
 type ResourceStatusFunc = func(resource controllerutil.Object) ResourceStatus
 
@@ -90,13 +154,47 @@ func (r *reconcileBuilder) WithAppLabels(name string, component AppComponent) Re
 	return r
 }
 
+// ResourceError is returned if the reconciliation of a resource fails
+type ResourceError struct {
+	Resource controllerutil.Object
+	Err      error
+}
+
+func (e *ResourceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResourceError) Unwrap() error {
+	return e.Err
+}
+
 func (r *reconcileBuilder) Reconcile() (ResourceStatus, error) {
+	status, err := r.reconcile()
+	if err != nil {
+		return ResourceStatus{}, &ResourceError{Resource: r.resource, Err: err}
+	}
+	return status, nil
+}
+
+func (r *reconcileBuilder) reconcile() (ResourceStatus, error) {
+	// The resource is copied, so metadata from the SSP CR
+	// does not accumulate on objects reused between reconciliations
+	r.resource = r.resource.DeepCopyObject().(controllerutil.Object)
+	AddCommonMetadata(r.request.Instance, r.resource)
 	if r.addLabels {
 		AddAppLabels(r.request.Instance, r.operandName, r.operandComponent, r.resource)
 	}
+	resource, err := applyCustomizePatches(r.request, r.resource)
+	if err != nil {
+		return ResourceStatus{}, err
+	}
+	resource, err = removeIgnoredFields(r.request, resource)
+	if err != nil {
+		return ResourceStatus{}, err
+	}
 	return createOrUpdate(
 		r.request,
-		r.resource,
+		resource,
 		r.isClusterResource,
 		r.updateFunc,
 		r.statusFunc,
@@ -124,37 +222,142 @@ func createOrUpdate(request *Request, resource controllerutil.Object, isClusterR
 	if err != nil {
 		return ResourceStatus{}, err
 	}
+	err = setResourceHash(resource)
+	if err != nil {
+		return ResourceStatus{}, err
+	}
 
 	found := newEmptyResource(resource)
-	found.SetName(resource.GetName())
-	found.SetNamespace(resource.GetNamespace())
-	res, err := controllerutil.CreateOrUpdate(request.Context, request.Client, found, func() error {
-		// We expect users will not add any other owner references,
-		// if that is not correct, this code needs to be changed.
-		found.SetOwnerReferences(resource.GetOwnerReferences())
-
-		updateLabels(resource, found)
-		updateAnnotations(resource, found)
-		if !request.VersionCache.Contains(found) {
-			// The generation was updated by other cluster components,
-			// operator needs to update the resource
-			updateResource(resource, found)
+	err = request.Client.Get(request.Context, client.ObjectKey{Namespace: resource.GetNamespace(), Name: resource.GetName()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return ResourceStatus{}, err
+	}
+	exists := err == nil
+
+	migrated := false
+	if exists {
+		if message := checkAdoption(request, found); message != nil {
+			return ResourceStatus{
+				Resource:     resource,
+				NotAvailable: message,
+				Degraded:     message,
+			}, nil
+		}
+
+		migrated, err = migrateManagedFields(request, found)
+		if err != nil {
+			return ResourceStatus{}, err
+		}
+
+		// The hash annotation is compared with the other metadata,
+		// so a resource is only applied if the rendered resource changed,
+		// or if it could have been modified since it was applied.
+		// Migrated resources are applied, so fields not set anymore are removed.
+		if !migrated && hasMetadata(found, resource) && (request.VersionCache.Contains(found) || !isModifiedByOthers(found, resource)) {
+			status := statusFunc(found)
+			status.Resource = resource
+			status.Operation = controllerutil.OperationResultNone
+			return status, nil
+		}
+		updateResource(resource, found)
+	}
+	// The resource was modified by somebody else since it was applied
+	var lastApplied controllerutil.Object
+	if exists && !migrated && !request.VersionCache.Contains(found) {
+		lastApplied = request.VersionCache.LastApplied(found)
+	}
+
+	applied, err := applyResource(request, resource)
+	if exists && isImmutableFieldError(err) {
+		applied, err = recreateResource(request, resource, found)
+		if err == nil {
+			exists = false
 		}
-		return nil
-	})
+	}
 	if err != nil {
-		request.Logger.V(1).Info(fmt.Sprintf("Resource create/update failed: %v", err))
+		request.Logger.V(1).Info(fmt.Sprintf("Resource apply failed: %v", err))
 		return ResourceStatus{}, err
 	}
 
-	request.VersionCache.Add(found)
-	logOperation(res, found, request.Logger)
+	request.VersionCache.Add(applied)
+	operation := controllerutil.OperationResultNone
+	if !exists {
+		operation = controllerutil.OperationResultCreated
+	} else if applied.GetResourceVersion() != found.GetResourceVersion() {
+		operation = controllerutil.OperationResultUpdated
+		if lastApplied != nil {
+			reportDrift(request, resource, found, lastApplied)
+		}
+	}
+	logOperation(operation, applied, request.Logger)
 
-	status := statusFunc(found)
+	status := statusFunc(applied)
 	status.Resource = resource
+	status.Operation = operation
 	return status, nil
 }
 
+// applyResource applies the resource using server-side apply and returns the resulting object.
+// The operator forces ownership of the fields it sets, fields set by others are kept.
+func applyResource(request *Request, resource controllerutil.Object) (controllerutil.Object, error) {
+	gvk, err := apiutil.GVKForObject(resource, request.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	applied := resource.DeepCopyObject().(controllerutil.Object)
+	applied.GetObjectKind().SetGroupVersionKind(gvk)
+	applied.SetResourceVersion("")
+	applied.SetManagedFields(nil)
+	err = request.Client.Patch(request.Context, applied, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// recreateResource deletes the found resource and applies the resource again.
+// It is used when the resource cannot be updated, because immutable fields changed.
+func recreateResource(request *Request, resource, found controllerutil.Object) (controllerutil.Object, error) {
+	if dryRun, ok := request.Client.(*DryRunClient); ok {
+		// The found resource is not deleted by a dry-run, so applying it again would fail
+		return resource, dryRun.RecordRecreate(resource)
+	}
+
+	kind := cacheKeyFromObj(found).Kind
+	request.Logger.Info(fmt.Sprintf("Recreating %s resource %s, because immutable fields changed", kind, found.GetName()))
+
+	uid := found.GetUID()
+	err := request.Client.Delete(request.Context, found,
+		client.Preconditions{UID: &uid},
+		client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	request.VersionCache.RemoveObj(found)
+
+	return applyResource(request, resource)
+}
+
+// isImmutableFieldError returns true, if the error was caused by changing an immutable field
+func isImmutableFieldError(err error) bool {
+	return errors.IsInvalid(err) && strings.Contains(err.Error(), "field is immutable")
+}
+
+// hasMetadata returns true if the found resource has all labels and annotations of the expected resource
+func hasMetadata(found, expected controllerutil.Object) bool {
+	return containsStringMap(found.GetLabels(), expected.GetLabels()) &&
+		containsStringMap(found.GetAnnotations(), expected.GetAnnotations())
+}
+
+func containsStringMap(found, expected map[string]string) bool {
+	for key, val := range expected {
+		if foundVal, ok := found[key]; !ok || foundVal != val {
+			return false
+		}
+	}
+	return true
+}
+
 func setOwner(request *Request, resource controllerutil.Object, isClusterRes bool) error {
 	if isClusterRes {
 		resource.SetOwnerReferences(nil)
@@ -167,32 +370,13 @@ func setOwner(request *Request, resource controllerutil.Object, isClusterRes boo
 }
 
 func newEmptyResource(resource controllerutil.Object) controllerutil.Object {
-	return reflect.New(reflect.TypeOf(resource).Elem()).Interface().(controllerutil.Object)
-}
-
-func updateAnnotations(expected, found controllerutil.Object) {
-	if found.GetAnnotations() == nil {
-		found.SetAnnotations(expected.GetAnnotations())
-		return
-	}
-	updateStringMap(expected.GetAnnotations(), found.GetAnnotations())
-}
-
-func updateLabels(expected, found controllerutil.Object) {
-	if found.GetLabels() == nil {
-		found.SetLabels(expected.GetLabels())
-		return
-	}
-	updateStringMap(expected.GetLabels(), found.GetLabels())
-}
-
-func updateStringMap(expected, found map[string]string) {
-	if expected == nil {
-		return
-	}
-	for key, val := range expected {
-		found[key] = val
+	if u, ok := resource.(*unstructured.Unstructured); ok {
+		// Unstructured objects need to know their kind to be fetched
+		empty := &unstructured.Unstructured{}
+		empty.SetGroupVersionKind(u.GroupVersionKind())
+		return empty
 	}
+	return reflect.New(reflect.TypeOf(resource).Elem()).Interface().(controllerutil.Object)
 }
 
 func logOperation(result controllerutil.OperationResult, resource controllerutil.Object, logger logr.Logger) {
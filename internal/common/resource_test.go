@@ -2,23 +2,34 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	libhandler "github.com/operator-framework/operator-lib/handler"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
 )
 
 var log = logf.Log.WithName("common_operand_package")
@@ -59,10 +70,21 @@ var _ = Describe("Create or update resource", func() {
 				},
 			},
 			Logger:       log,
-			VersionCache: VersionCache{},
+			Recorder:     record.NewFakeRecorder(10),
+			VersionCache: NewVersionCache(),
 		}
 	})
 
+	getTestResource := func() *v1.Service {
+		key, err := client.ObjectKeyFromObject(newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &v1.Service{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		found.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Service"))
+		return found
+	}
+
 	It("should create resource", func() {
 		_, err := createOrUpdateTestResource(&request)
 		Expect(err).ToNot(HaveOccurred())
@@ -81,6 +103,569 @@ var _ = Describe("Create or update resource", func() {
 		expectEqualResourceExists(newTestResource(namespace), &request)
 	})
 
+	It("should add common labels and annotations and keep user added ones", func() {
+		resource := newTestResource(namespace)
+		resource.Labels["user-label"] = "user-value"
+		resource.Annotations["user-annotation"] = "user-value"
+		Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+		request.Instance.Spec.CommonLabels = map[string]string{"cost-center": "1234"}
+		request.Instance.Spec.CommonAnnotations = map[string]string{"owner": "team-a"}
+
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(resource)
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &v1.Service{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.GetLabels()).To(HaveKeyWithValue("cost-center", "1234"))
+		Expect(found.GetLabels()).To(HaveKeyWithValue("user-label", "user-value"))
+		Expect(found.GetAnnotations()).To(HaveKeyWithValue("owner", "team-a"))
+		Expect(found.GetAnnotations()).To(HaveKeyWithValue("user-annotation", "user-value"))
+	})
+
+	It("should keep fields set by others", func() {
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newTestResource(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &v1.Service{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		found.Spec.SessionAffinity = v1.ServiceAffinityClientIP
+		found.Spec.Ports[0].Name = "changed-name"
+		Expect(request.Client.Update(request.Context, found)).ToNot(HaveOccurred())
+
+		_, err = createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		updated := &v1.Service{}
+		Expect(request.Client.Get(request.Context, key, updated)).ToNot(HaveOccurred())
+		Expect(updated.Spec.SessionAffinity).To(Equal(v1.ServiceAffinityClientIP))
+		Expect(updated.Spec.Ports[0].Name).To(Equal("webhook"))
+	})
+
+	It("should keep values copied from found resource by update func", func() {
+		resource := newTestResource(namespace)
+		resource.Spec.Ports[0].Port = 8443
+		Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+		_, err := CreateOrUpdate(&request).
+			NamespacedResource(newTestResource(namespace)).
+			UpdateFunc(func(expected, found controllerutil.Object) {
+				expected.(*v1.Service).Spec.Ports[0].Port = found.(*v1.Service).Spec.Ports[0].Port
+			}).
+			Reconcile()
+		Expect(err).ToNot(HaveOccurred())
+		expectEqualResourceExists(resource, &request)
+	})
+
+	Context("concurrent reconciliation", func() {
+		BeforeEach(func() {
+			request.MaxConcurrentResources = 4
+		})
+
+		newFuncs := func(count int, failing int) []ReconcileFunc {
+			funcs := make([]ReconcileFunc, 0, count)
+			for i := 0; i < count; i++ {
+				index := i
+				funcs = append(funcs, func(*Request) (ResourceStatus, error) {
+					if index == failing {
+						return ResourceStatus{}, fmt.Errorf("test error %d", index)
+					}
+					msg := strconv.Itoa(index)
+					return ResourceStatus{Progressing: &msg}, nil
+				})
+			}
+			return funcs
+		}
+
+		It("should return statuses in the order of functions", func() {
+			statuses, err := CollectResourceStatusConcurrently(&request, newFuncs(20, -1)...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statuses).To(HaveLen(20))
+			for i, status := range statuses {
+				Expect(*status.Progressing).To(Equal(strconv.Itoa(i)))
+			}
+		})
+
+		It("should return error if a function fails", func() {
+			_, err := CollectResourceStatusConcurrently(&request, newFuncs(20, 7)...)
+			Expect(err).To(MatchError("test error 7"))
+		})
+	})
+
+	Context("drift", func() {
+		It("should emit event when reverting modified fields", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newTestResource(namespace))
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &v1.Service{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			found.Spec.Ports[0].Name = "changed-name"
+			found.Labels["test-label"] = "changed-value"
+			found.ManagedFields = []metav1.ManagedFieldsEntry{{
+				Manager:   FieldManager,
+				Operation: metav1.ManagedFieldsOperationApply,
+			}, {
+				Manager:   "kubectl-edit",
+				Operation: metav1.ManagedFieldsOperationUpdate,
+			}}
+			Expect(request.Client.Update(request.Context, found)).ToNot(HaveOccurred())
+
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			recorder := request.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(HaveLen(1))
+			event := <-recorder.Events
+			Expect(event).To(HavePrefix(v1.EventTypeWarning + " " + DriftRevertedReason))
+			Expect(event).To(ContainSubstring(`"kubectl-edit"`))
+			Expect(event).To(ContainSubstring("metadata.labels.test-label, spec.ports[0].name"))
+		})
+
+		It("should not emit event for resources not applied before", func() {
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(request.Recorder.(*record.FakeRecorder).Events).To(BeEmpty())
+		})
+
+		It("should not emit event when only the rendered resource changed", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			resource := newTestResource(namespace)
+			resource.Labels["test-label"] = "new-value"
+			_, err = CreateOrUpdate(&request).
+				NamespacedResource(resource).
+				Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(request.Recorder.(*record.FakeRecorder).Events).To(BeEmpty())
+		})
+
+		It("should not report fields changed by the rendered resource", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newTestResource(namespace))
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &v1.Service{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			found.Spec.Ports[0].Name = "changed-name"
+			Expect(request.Client.Update(request.Context, found)).ToNot(HaveOccurred())
+
+			resource := newTestResource(namespace)
+			resource.Labels["test-label"] = "new-value"
+			_, err = CreateOrUpdate(&request).
+				NamespacedResource(resource).
+				Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			recorder := request.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(HaveLen(1))
+			event := <-recorder.Events
+			Expect(event).To(HaveSuffix("spec.ports[0].name"))
+		})
+
+		It("should not emit event if fields set by the operator were not modified", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newTestResource(namespace))
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &v1.Service{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			found.Spec.SessionAffinity = v1.ServiceAffinityClientIP
+			Expect(request.Client.Update(request.Context, found)).ToNot(HaveOccurred())
+
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(request.Recorder.(*record.FakeRecorder).Events).To(BeEmpty())
+		})
+	})
+
+	Context("adoption", func() {
+		var existing *v1.Service
+
+		BeforeEach(func() {
+			existing = newTestResource(namespace)
+			existing.Spec.Ports[0].Name = "manual-name"
+			Expect(request.Client.Create(request.Context, existing)).ToNot(HaveOccurred())
+		})
+
+		It("should adopt unowned resource by default", func() {
+			status, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Degraded).To(BeNil())
+
+			found := getTestResource()
+			Expect(found.Spec.Ports[0].Name).To(Equal("webhook"))
+			Expect(metav1.GetControllerOf(found)).ToNot(BeNil())
+			Expect(metav1.GetControllerOf(found).Name).To(Equal(name))
+		})
+
+		It("should not update unowned resource with Refuse policy", func() {
+			request.Instance.Spec.AdoptionPolicy = ssp.AdoptionPolicyRefuse
+
+			status, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Degraded).ToNot(BeNil())
+			Expect(*status.Degraded).To(ContainSubstring("not owned by the SSP CR"))
+			Expect(status.NotAvailable).ToNot(BeNil())
+
+			found := getTestResource()
+			Expect(found.Spec.Ports[0].Name).To(Equal("manual-name"))
+			Expect(metav1.GetControllerOf(found)).To(BeNil())
+
+			recorder := request.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(HavePrefix(v1.EventTypeWarning + " " + AdoptionRefusedReason))
+		})
+
+		It("should update owned resource with Refuse policy", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Instance.Spec.AdoptionPolicy = ssp.AdoptionPolicyRefuse
+			request.Instance.Spec.CommonLabels = map[string]string{"cost-center": "1234"}
+
+			status, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Degraded).To(BeNil())
+			Expect(getTestResource().GetLabels()).To(HaveKeyWithValue("cost-center", "1234"))
+		})
+
+		It("should not adopt resource controlled by another object", func() {
+			found := getTestResource()
+			found.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "other-controller",
+				UID:        "other-uid",
+				Controller: pointer.BoolPtr(true),
+			}}
+			Expect(request.Client.Update(request.Context, found)).ToNot(HaveOccurred())
+
+			status, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Degraded).ToNot(BeNil())
+			Expect(*status.Degraded).To(ContainSubstring("controlled by Deployment other-controller"))
+			Expect(getTestResource().Spec.Ports[0].Name).To(Equal("manual-name"))
+		})
+	})
+
+	Context("immutable fields", func() {
+		BeforeEach(func() {
+			request.Client = &immutableClusterIPClient{Client: request.Client}
+
+			resource := newTestResource(namespace)
+			resource.Spec.ClusterIP = "10.0.0.1"
+			Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+		})
+
+		It("should recreate resource if an immutable field changed", func() {
+			status, err := CreateOrUpdate(&request).
+				NamespacedResource(newTestResource(namespace)).
+				Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Operation).To(Equal(controllerutil.OperationResultCreated))
+			Expect(getTestResource().Spec.ClusterIP).To(BeEmpty())
+		})
+
+		It("should not recreate resource if other fields changed", func() {
+			resource := newTestResource(namespace)
+			resource.Spec.ClusterIP = "10.0.0.1"
+			resource.Spec.Ports[0].Name = "changed-name"
+			status, err := CreateOrUpdate(&request).
+				NamespacedResource(resource).
+				Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Operation).To(Equal(controllerutil.OperationResultUpdated))
+			Expect(getTestResource().Spec.Ports[0].Name).To(Equal("changed-name"))
+		})
+	})
+
+	Context("customize patches", func() {
+		It("should apply patch to matching resource and keep it", func() {
+			request.Instance.Spec.CustomizePatches = []ssp.CustomizePatch{{
+				Kind:  "Service",
+				Name:  "testservice",
+				Patch: `[{"op": "replace", "path": "/spec/ports/0/port", "value": 8443}]`,
+			}}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Spec.Ports[0].Port).To(Equal(int32(8443)))
+
+			request.VersionCache = NewVersionCache()
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Spec.Ports[0].Port).To(Equal(int32(8443)))
+		})
+
+		It("should not apply patch to other resources", func() {
+			request.Instance.Spec.CustomizePatches = []ssp.CustomizePatch{{
+				Kind:  "Service",
+				Name:  "other-service",
+				Patch: `[{"op": "replace", "path": "/spec/ports/0/port", "value": 8443}]`,
+			}, {
+				Kind:      "Service",
+				Name:      "testservice",
+				Namespace: "other-namespace",
+				Patch:     `[{"op": "replace", "path": "/spec/ports/0/port", "value": 8443}]`,
+			}}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Spec.Ports[0].Port).To(Equal(int32(443)))
+		})
+
+		It("should fail if the patch cannot be applied", func() {
+			request.Instance.Spec.CustomizePatches = []ssp.CustomizePatch{{
+				Kind:  "Service",
+				Name:  "testservice",
+				Patch: `[{"op": "replace", "path": "/spec/missing/field", "value": 1}]`,
+			}}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).To(HaveOccurred())
+
+			resourceErr, ok := err.(*ResourceError)
+			Expect(ok).To(BeTrue())
+			Expect(resourceErr.Resource.GetName()).To(Equal("testservice"))
+		})
+
+		It("should fail if the patch renames the resource", func() {
+			request.Instance.Spec.CustomizePatches = []ssp.CustomizePatch{{
+				Kind:  "Service",
+				Name:  "testservice",
+				Patch: `[{"op": "replace", "path": "/metadata/name", "value": "renamed"}]`,
+			}}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ignored fields", func() {
+		It("should keep ignored annotation set by another component", func() {
+			request.Instance.Spec.IgnoredFields = []ssp.IgnoredFields{{
+				Kind:  "Service",
+				Name:  "testservice",
+				Paths: []string{"/metadata/annotations/test-annotation"},
+			}}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Annotations).ToNot(HaveKey("test-annotation"))
+
+			found := getTestResource()
+			found.Annotations["test-annotation"] = "other-value"
+			Expect(request.Client.Update(request.Context, found)).To(Succeed())
+
+			request.VersionCache = NewVersionCache()
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Annotations).To(HaveKeyWithValue("test-annotation", "other-value"))
+		})
+
+		It("should keep ignored spec field set by another component", func() {
+			request.Instance.Spec.IgnoredFields = []ssp.IgnoredFields{{
+				Kind:  "Service",
+				Name:  "testservice",
+				Paths: []string{"/spec/sessionAffinity"},
+			}}
+			resource := newTestResource(namespace)
+			resource.Spec.SessionAffinity = v1.ServiceAffinityNone
+
+			_, err := CreateOrUpdate(&request).NamespacedResource(resource).Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			found := getTestResource()
+			found.Spec.SessionAffinity = v1.ServiceAffinityClientIP
+			Expect(request.Client.Update(request.Context, found)).To(Succeed())
+
+			request.VersionCache = NewVersionCache()
+			_, err = CreateOrUpdate(&request).NamespacedResource(resource).Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Spec.SessionAffinity).To(Equal(v1.ServiceAffinityClientIP))
+		})
+
+		It("should fail if an ignored field is required", func() {
+			request.Instance.Spec.IgnoredFields = []ssp.IgnoredFields{{
+				Kind:  "HorizontalPodAutoscaler",
+				Name:  "test-autoscaler",
+				Paths: []string{"/spec/maxReplicas"},
+			}}
+			autoscaler := &autoscaling.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-autoscaler",
+					Namespace: namespace,
+				},
+				Spec: autoscaling.HorizontalPodAutoscalerSpec{
+					MaxReplicas: 5,
+				},
+			}
+
+			_, err := CreateOrUpdate(&request).NamespacedResource(autoscaler).Reconcile()
+			Expect(err).To(MatchError(ContainSubstring("/spec/maxReplicas")))
+		})
+
+		It("should not ignore fields of other resources", func() {
+			request.Instance.Spec.IgnoredFields = []ssp.IgnoredFields{{
+				Kind:      "Service",
+				Name:      "testservice",
+				Namespace: "other-namespace",
+				Paths:     []string{"/metadata/annotations/test-annotation"},
+			}}
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Annotations).To(HaveKeyWithValue("test-annotation", "value2"))
+		})
+	})
+
+	Context("dry-run", func() {
+		var dryRunClient *DryRunClient
+
+		BeforeEach(func() {
+			dryRunClient = NewDryRunClient(request.Client, request.Scheme)
+		})
+
+		It("should record created resource without creating it", func() {
+			realClient := request.Client
+			request.Client = dryRunClient
+			status, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Operation).To(Equal(controllerutil.OperationResultCreated))
+			Expect(dryRunClient.Changes()).To(ConsistOf(Change{
+				Operation: ChangeCreate,
+				Kind:      "Service",
+				Namespace: namespace,
+				Name:      "testservice",
+			}))
+
+			err = realClient.Get(request.Context, client.ObjectKey{Namespace: namespace, Name: "testservice"}, &v1.Service{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should record changed fields without updating resource", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Client = dryRunClient
+			request.VersionCache = NewVersionCache()
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			resource.Labels["new-label"] = "value"
+			_, err = CreateOrUpdate(&request).NamespacedResource(resource).Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			changes := dryRunClient.Changes()
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].Operation).To(Equal(ChangeUpdate))
+			Expect(changes[0].Fields).To(ContainElements("metadata.labels.new-label", "spec.ports[0].name"))
+			Expect(getTestResource().Spec.Ports[0].Name).To(Equal("webhook"))
+			Expect(getTestResource().Labels).ToNot(HaveKey("new-label"))
+		})
+
+		It("should not record unchanged resource", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Client = dryRunClient
+			request.VersionCache = NewVersionCache()
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dryRunClient.Changes()).To(BeEmpty())
+		})
+
+		It("should record deleted resource without deleting it", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(dryRunClient.Delete(request.Context, newTestResource(namespace))).To(Succeed())
+			Expect(dryRunClient.Changes()).To(ConsistOf(Change{
+				Operation: ChangeDelete,
+				Kind:      "Service",
+				Namespace: namespace,
+				Name:      "testservice",
+			}))
+			getTestResource()
+		})
+	})
+
+	Context("audit", func() {
+		var auditClient *AuditClient
+
+		BeforeEach(func() {
+			auditClient = NewAuditClient(request.Client, request.Scheme, "test-reconcile")
+			request.Client = auditClient
+		})
+
+		It("should record created resource", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			entries := auditClient.Entries()
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].ReconcileID).To(Equal("test-reconcile"))
+			Expect(entries[0].Change).To(Equal(Change{
+				Operation: ChangeCreate,
+				Kind:      "Service",
+				Namespace: namespace,
+				Name:      "testservice",
+			}))
+			getTestResource()
+		})
+
+		It("should record changed fields of updated resource", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.VersionCache = NewVersionCache()
+			resource := newTestResource(namespace)
+			resource.Spec.Ports[0].Name = "changed-name"
+			_, err = CreateOrUpdate(&request).NamespacedResource(resource).Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			entries := auditClient.Entries()
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[1].Operation).To(Equal(ChangeUpdate))
+			Expect(entries[1].Fields).To(ContainElement("spec.ports[0].name"))
+			Expect(getTestResource().Spec.Ports[0].Name).To(Equal("changed-name"))
+		})
+
+		It("should not record unchanged resource", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.VersionCache = NewVersionCache()
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auditClient.Entries()).To(HaveLen(1))
+		})
+
+		It("should not record dry-run changes", func() {
+			request.Client = NewDryRunClient(auditClient, request.Scheme)
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auditClient.Entries()).To(BeEmpty())
+		})
+	})
+
 	It("should set owner reference", func() {
 		_, err := createOrUpdateTestResource(&request)
 		Expect(err).ToNot(HaveOccurred())
@@ -100,9 +685,6 @@ var _ = Describe("Create or update resource", func() {
 	It("should set owner annotations", func() {
 		_, err := CreateOrUpdate(&request).
 			ClusterResource(newTestResource("")).
-			UpdateFunc(func(expected, found controllerutil.Object) {
-				found.(*v1.Service).Spec = expected.(*v1.Service).Spec
-			}).
 			Reconcile()
 
 		Expect(err).ToNot(HaveOccurred())
@@ -118,55 +700,292 @@ var _ = Describe("Create or update resource", func() {
 	})
 
 	It("should not update resource with cached version", func() {
-		resource := newTestResource(namespace)
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		resource := getTestResource()
 		resource.Spec.Ports[0].Name = "changed-name"
-		Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+		Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
 
 		request.VersionCache.Add(resource)
 
-		_, err := createOrUpdateTestResource(&request)
+		_, err = createOrUpdateTestResource(&request)
 		Expect(err).ToNot(HaveOccurred())
 		expectEqualResourceExists(resource, &request)
 	})
 
 	It("should not update resource with cached generation", func() {
-		resource := newTestResource(namespace)
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		resource := getTestResource()
 		resource.Generation = 1
 		resource.Spec.Ports[0].Name = "changed-name"
-		Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+		Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
 
 		request.VersionCache.Add(resource)
 
 		resource.Spec.Ports[0].Name = "changed-name-2"
 		Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
 
-		_, err := createOrUpdateTestResource(&request)
+		_, err = createOrUpdateTestResource(&request)
 		Expect(err).ToNot(HaveOccurred())
 		expectEqualResourceExists(resource, &request)
 	})
 
 	It("should update resource with different version in cache", func() {
-		resource := newTestResource(namespace)
+		_, err := createOrUpdateTestResource(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		resource := getTestResource()
 		resource.Spec.Ports[0].Name = "changed-name"
-		Expect(request.Client.Create(request.Context, resource)).ToNot(HaveOccurred())
+		Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
 
 		request.VersionCache.Add(resource)
 
 		resource.Spec.Ports[0].Name = "changed-name-2"
 		Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
 
-		_, err := createOrUpdateTestResource(&request)
+		_, err = createOrUpdateTestResource(&request)
 		Expect(err).ToNot(HaveOccurred())
 		expectEqualResourceExists(newTestResource(namespace), &request)
 	})
+
+	Context("resource hash", func() {
+		const appliedFields = `{
+			"f:metadata": {
+				"f:labels": {"f:test-label": {}},
+				"f:annotations": {"f:test-annotation": {}, "f:ssp.kubevirt.io/resource-hash": {}},
+				"f:ownerReferences": {"k:{\"uid\":\"\"}": {}}
+			},
+			"f:spec": {
+				"f:ports": {"k:{\"port\":443,\"protocol\":\"TCP\"}": {".": {}, "f:name": {}, "f:port": {}, "f:targetPort": {}}},
+				"f:selector": {"f:kubevirtIo": {}}
+			}
+		}`
+
+		var appliedTime metav1.Time
+
+		appliedEntry := func(fields string) metav1.ManagedFieldsEntry {
+			return metav1.ManagedFieldsEntry{
+				Manager:   FieldManager,
+				Operation: metav1.ManagedFieldsOperationApply,
+				Time:      &appliedTime,
+				FieldsV1:  &metav1.FieldsV1{Raw: []byte(fields)},
+			}
+		}
+
+		setManagedFields := func(entries ...metav1.ManagedFieldsEntry) {
+			resource := getTestResource()
+			resource.ManagedFields = entries
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Annotations).To(HaveKey(ResourceHashAnnotation))
+
+			appliedTime = metav1.Now()
+			setManagedFields(appliedEntry(appliedFields), metav1.ManagedFieldsEntry{
+				Manager:   "kube-controller-manager",
+				Operation: metav1.ManagedFieldsOperationUpdate,
+				Time:      &metav1.Time{Time: appliedTime.Add(time.Minute)},
+				FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:status":{}}`)},
+			})
+
+			// Simulate restart of the operator
+			request.VersionCache = NewVersionCache()
+		})
+
+		It("should not apply resource with the same hash", func() {
+			resourceVersion := getTestResource().ResourceVersion
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().ResourceVersion).To(Equal(resourceVersion))
+		})
+
+		It("should apply resource with a different hash", func() {
+			resourceVersion := getTestResource().ResourceVersion
+
+			request.Instance.Spec.CommonLabels = map[string]string{"cost-center": "1234"}
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().ResourceVersion).ToNot(Equal(resourceVersion))
+		})
+
+		It("should apply resource modified by others", func() {
+			resource := getTestResource()
+			resource.Spec.Ports[0].Name = "changed-name"
+			resource.ManagedFields = append(resource.ManagedFields, metav1.ManagedFieldsEntry{
+				Manager:   "kubectl-edit",
+				Operation: metav1.ManagedFieldsOperationUpdate,
+				Time:      &metav1.Time{Time: appliedTime.Add(time.Minute)},
+				FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)},
+			})
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Spec.Ports[0].Name).To(Equal("webhook"))
+		})
+
+		It("should apply resource modified by others in the same second as it was applied", func() {
+			resource := getTestResource()
+			resource.Labels["test-label"] = "changed-value"
+			resource.ManagedFields = []metav1.ManagedFieldsEntry{
+				appliedEntry(strings.Replace(appliedFields, `"f:test-label": {}`, "", 1)),
+				{
+					Manager:   "kubectl-edit",
+					Operation: metav1.ManagedFieldsOperationUpdate,
+					Time:      &appliedTime,
+					FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:test-label":{}}}}`)},
+				},
+			}
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Labels).To(HaveKeyWithValue("test-label", "value1"))
+		})
+
+		It("should apply resource with a field removed by others", func() {
+			resource := getTestResource()
+			delete(resource.Annotations, "test-annotation")
+			resource.ManagedFields = []metav1.ManagedFieldsEntry{
+				appliedEntry(strings.Replace(appliedFields, `"f:test-annotation": {}, `, "", 1)),
+			}
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().Annotations).To(HaveKeyWithValue("test-annotation", "value2"))
+		})
+
+		It("should not apply resource, if others set only other fields", func() {
+			resource := getTestResource()
+			resource.Annotations["other-annotation"] = "other"
+			resource.Spec.SessionAffinity = v1.ServiceAffinityClientIP
+			resource.ManagedFields = append(resource.ManagedFields, metav1.ManagedFieldsEntry{
+				Manager:   "kubectl-edit",
+				Operation: metav1.ManagedFieldsOperationUpdate,
+				Time:      &metav1.Time{Time: appliedTime.Add(time.Minute)},
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(
+					`{"f:metadata":{"f:annotations":{".":{},"f:other-annotation":{}}},"f:spec":{"f:sessionAffinity":{}}}`)},
+			})
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+			resourceVersion := getTestResource().ResourceVersion
+
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getTestResource().ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+
+	Context("managed fields migration", func() {
+		var fieldsTime metav1.Time
+
+		legacyEntry := func(fields string) metav1.ManagedFieldsEntry {
+			return metav1.ManagedFieldsEntry{
+				Manager:    LegacyFieldManager,
+				Operation:  metav1.ManagedFieldsOperationUpdate,
+				APIVersion: "v1",
+				Time:       &fieldsTime,
+				FieldsType: "FieldsV1",
+				FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+			}
+		}
+
+		appliedEntry := func(fields string) metav1.ManagedFieldsEntry {
+			return metav1.ManagedFieldsEntry{
+				Manager:    FieldManager,
+				Operation:  metav1.ManagedFieldsOperationApply,
+				APIVersion: "v1",
+				Time:       &fieldsTime,
+				FieldsType: "FieldsV1",
+				FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+			}
+		}
+
+		BeforeEach(func() {
+			fieldsTime = metav1.Now()
+		})
+
+		It("should move fields of the legacy field manager to the apply field manager", func() {
+			_, err := createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			resource := getTestResource()
+			statusEntry := metav1.ManagedFieldsEntry{
+				Manager:   "kube-controller-manager",
+				Operation: metav1.ManagedFieldsOperationUpdate,
+				Time:      &fieldsTime,
+				FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:status":{}}`)},
+			}
+			resource.ManagedFields = []metav1.ManagedFieldsEntry{
+				legacyEntry(`{"f:metadata":{"f:labels":{"f:test-label":{}}},"f:status":{".":{}}}`),
+				statusEntry,
+			}
+			Expect(request.Client.Update(request.Context, resource)).ToNot(HaveOccurred())
+			request.VersionCache = NewVersionCache()
+
+			_, err = createOrUpdateTestResource(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			entries := getTestResource().ManagedFields
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Manager).To(Equal(statusEntry.Manager))
+			Expect(entries[1].Manager).To(Equal(FieldManager))
+			Expect(entries[1].Operation).To(Equal(metav1.ManagedFieldsOperationApply))
+			Expect(entries[1].APIVersion).To(Equal("v1"))
+			Expect(entries[1].FieldsV1.Raw).To(MatchJSON(`{"f:metadata":{"f:labels":{"f:test-label":{}}}}`))
+		})
+
+		It("should merge fields of the legacy field manager with the applied fields", func() {
+			entries, migrated, err := migratedManagedFields([]metav1.ManagedFieldsEntry{
+				appliedEntry(`{"f:metadata":{"f:labels":{"f:a":{}}},"f:spec":{"f:ports":{}}}`),
+				legacyEntry(`{"f:metadata":{"f:labels":{"f:b":{}},"f:annotations":{"f:c":{}}}}`),
+			}, "v1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated).To(BeTrue())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Manager).To(Equal(FieldManager))
+			Expect(entries[0].FieldsV1.Raw).To(MatchJSON(
+				`{"f:metadata":{"f:labels":{"f:a":{},"f:b":{}},"f:annotations":{"f:c":{}}},"f:spec":{"f:ports":{}}}`))
+		})
+
+		It("should not migrate resources without the legacy field manager", func() {
+			original := []metav1.ManagedFieldsEntry{appliedEntry(`{"f:metadata":{}}`)}
+			entries, migrated, err := migratedManagedFields(original, "v1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated).To(BeFalse())
+			Expect(entries).To(Equal(original))
+		})
+	})
 })
 
+// immutableClusterIPClient fails to apply a Service with a different cluster IP,
+// like the API server does
+type immutableClusterIPClient struct {
+	client.Client
+}
+
+func (c *immutableClusterIPClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	service := obj.(*v1.Service)
+	found := &v1.Service{}
+	err := c.Client.Get(ctx, client.ObjectKey{Namespace: service.Namespace, Name: service.Name}, found)
+	if err == nil && found.Spec.ClusterIP != service.Spec.ClusterIP {
+		return errors.NewInvalid(schema.GroupKind{Kind: "Service"}, service.Name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "clusterIP"), service.Spec.ClusterIP, "field is immutable"),
+		})
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
 func createOrUpdateTestResource(request *Request) (ResourceStatus, error) {
 	return CreateOrUpdate(request).
 		NamespacedResource(newTestResource(namespace)).
-		UpdateFunc(func(expected, found controllerutil.Object) {
-			found.(*v1.Service).Spec = expected.(*v1.Service).Spec
-		}).
 		Reconcile()
 }
 
@@ -209,6 +1028,9 @@ func expectEqualResourceExists(resource controllerutil.Object, request *Request)
 	resource.SetGeneration(found.GetGeneration())
 	resource.SetResourceVersion(found.GetResourceVersion())
 	resource.SetOwnerReferences(found.GetOwnerReferences())
+	if hash, ok := found.GetAnnotations()[ResourceHashAnnotation]; ok {
+		resource.GetAnnotations()[ResourceHashAnnotation] = hash
+	}
 
 	ExpectWithOffset(1, found).To(Equal(resource))
 }
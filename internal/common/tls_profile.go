@@ -0,0 +1,107 @@
+package common
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	ocpv1 "github.com/openshift/api/config/v1"
+)
+
+// openSSLToIANACipherSuites maps the OpenSSL cipher names used by the
+// TLS security profiles to the IANA names understood by Go TLS servers.
+// TLS 1.3 ciphers already use IANA names and are not configurable in Go.
+var openSSLToIANACipherSuites = map[string]string{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256":   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384": "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384":   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-ECDSA-CHACHA20-POLY1305": "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-RSA-CHACHA20-POLY1305":   "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-ECDSA-AES128-SHA256":     "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+	"ECDHE-RSA-AES128-SHA256":       "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+	"ECDHE-ECDSA-AES128-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-RSA-AES128-SHA":          "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-ECDSA-AES256-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	"ECDHE-RSA-AES256-SHA":          "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	"AES128-GCM-SHA256":             "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	"AES256-GCM-SHA384":             "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	"AES128-SHA256":                 "TLS_RSA_WITH_AES_128_CBC_SHA256",
+	"AES128-SHA":                    "TLS_RSA_WITH_AES_128_CBC_SHA",
+	"AES256-SHA":                    "TLS_RSA_WITH_AES_256_CBC_SHA",
+	"DES-CBC3-SHA":                  "TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+}
+
+// GetTLSProfileSpec returns the TLS settings of the given profile.
+// The Intermediate profile is used if the profile is not set.
+func GetTLSProfileSpec(profile *ocpv1.TLSSecurityProfile) *ocpv1.TLSProfileSpec {
+	if profile == nil || profile.Type == "" {
+		return ocpv1.TLSProfiles[ocpv1.TLSProfileIntermediateType]
+	}
+	if profile.Type == ocpv1.TLSProfileCustomType {
+		if profile.Custom == nil {
+			return ocpv1.TLSProfiles[ocpv1.TLSProfileIntermediateType]
+		}
+		return &profile.Custom.TLSProfileSpec
+	}
+	if spec, ok := ocpv1.TLSProfiles[profile.Type]; ok {
+		return spec
+	}
+	return ocpv1.TLSProfiles[ocpv1.TLSProfileIntermediateType]
+}
+
+// CipherSuitesIANANames converts OpenSSL cipher names to IANA names.
+// Ciphers that are not supported by Go TLS servers, or that Go considers insecure, are skipped.
+func CipherSuitesIANANames(ciphers []string) []string {
+	var names []string
+	for _, cipher := range ciphers {
+		if name, ok := openSSLToIANACipherSuites[cipher]; ok && !isInsecureCipherSuite(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+var tlsVersions = map[ocpv1.TLSProtocolVersion]uint16{
+	ocpv1.VersionTLS10: tls.VersionTLS10,
+	ocpv1.VersionTLS11: tls.VersionTLS11,
+	ocpv1.VersionTLS12: tls.VersionTLS12,
+	ocpv1.VersionTLS13: tls.VersionTLS13,
+}
+
+// TLSVersion returns the Go TLS version of a TLS security profile version, e.g. VersionTLS12
+func TLSVersion(version ocpv1.TLSProtocolVersion) (uint16, error) {
+	if id, ok := tlsVersions[version]; ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version: %s", version)
+}
+
+// CipherSuiteIDs returns the IDs of the cipher suites with the given IANA names.
+// Cipher suites that Go considers insecure are rejected.
+func CipherSuiteIDs(names []string) ([]uint16, error) {
+	suites := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			if isInsecureCipherSuite(name) {
+				return nil, fmt.Errorf("insecure cipher suite: %s", name)
+			}
+			return nil, fmt.Errorf("unknown cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func isInsecureCipherSuite(name string) bool {
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return true
+		}
+	}
+	return false
+}
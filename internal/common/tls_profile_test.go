@@ -0,0 +1,53 @@
+package common
+
+import (
+	"crypto/tls"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ocpv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("TLS profile", func() {
+	It("should convert TLS versions", func() {
+		Expect(TLSVersion(ocpv1.VersionTLS12)).To(Equal(uint16(tls.VersionTLS12)))
+		Expect(TLSVersion(ocpv1.VersionTLS13)).To(Equal(uint16(tls.VersionTLS13)))
+
+		_, err := TLSVersion("TLSv1.2")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should convert cipher suite names to IDs", func() {
+		ids, err := CipherSuiteIDs([]string{
+			"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+			"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids).To(Equal([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}))
+
+		_, err = CipherSuiteIDs([]string{"ECDHE-RSA-AES128-GCM-SHA256"})
+		Expect(err).To(MatchError("unknown cipher suite: ECDHE-RSA-AES128-GCM-SHA256"))
+	})
+
+	It("should reject insecure cipher suites", func() {
+		_, err := CipherSuiteIDs([]string{
+			"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+			"TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+		})
+		Expect(err).To(MatchError("insecure cipher suite: TLS_RSA_WITH_3DES_EDE_CBC_SHA"))
+	})
+
+	It("should skip insecure ciphers of profiles", func() {
+		Expect(CipherSuitesIANANames([]string{"ECDHE-RSA-AES128-GCM-SHA256", "DES-CBC3-SHA"})).
+			To(Equal([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}))
+	})
+
+	It("should convert ciphers of all predefined profiles", func() {
+		for profileType, spec := range ocpv1.TLSProfiles {
+			_, err := CipherSuiteIDs(CipherSuitesIANANames(spec.Ciphers))
+			Expect(err).ToNot(HaveOccurred(), string(profileType))
+			_, err = TLSVersion(spec.MinTLSVersion)
+			Expect(err).ToNot(HaveOccurred(), string(profileType))
+		}
+	})
+})
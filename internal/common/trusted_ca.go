@@ -0,0 +1,46 @@
+package common
+
+import (
+	core "k8s.io/api/core/v1"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+const (
+	trustedCABundleVolumeName = "trusted-ca-bundle"
+	trustedCABundleMountPath  = "/etc/pki/ca-trust/extracted/pem"
+	trustedCABundleFileName   = "tls-ca-bundle.pem"
+)
+
+// AddTrustedCABundle mounts the CA bundle from the ConfigMap as the system trust store
+// of all containers and init containers in the pod spec
+func AddTrustedCABundle(podSpec *core.PodSpec, bundle *ssp.TrustedCABundle) {
+	if bundle == nil {
+		return
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, core.Volume{
+		Name: trustedCABundleVolumeName,
+		VolumeSource: core.VolumeSource{
+			ConfigMap: &core.ConfigMapVolumeSource{
+				LocalObjectReference: core.LocalObjectReference{Name: bundle.Name},
+				Items: []core.KeyToPath{{
+					Key:  bundle.GetKey(),
+					Path: trustedCABundleFileName,
+				}},
+			},
+		},
+	})
+
+	mount := core.VolumeMount{
+		Name:      trustedCABundleVolumeName,
+		MountPath: trustedCABundleMountPath,
+		ReadOnly:  true,
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].VolumeMounts = append(podSpec.InitContainers[i].VolumeMounts, mount)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, mount)
+	}
+}
@@ -0,0 +1,110 @@
+package admission_policies
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+
+	"kubevirt.io/ssp-operator/internal/common"
+	"kubevirt.io/ssp-operator/internal/operands"
+)
+
+// Define RBAC rules needed by this operand:
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingadmissionpolicies;validatingadmissionpolicybindings,verbs=get;list;watch;create;update;patch;delete
+
+type admissionPolicies struct{}
+
+func (a *admissionPolicies) Name() string {
+	return operandName
+}
+
+func (a *admissionPolicies) AddWatchTypesToScheme(*runtime.Scheme) error {
+	return nil
+}
+
+func (a *admissionPolicies) WatchTypes() []runtime.Object {
+	return nil
+}
+
+func (a *admissionPolicies) WatchClusterTypes() []runtime.Object {
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(ValidatingAdmissionPolicyGVK)
+	binding := &unstructured.Unstructured{}
+	binding.SetGroupVersionKind(ValidatingAdmissionPolicyBindingGVK)
+	return []runtime.Object{policy, binding}
+}
+
+func (a *admissionPolicies) RequiredAPIs() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{{
+		Group:    "admissionregistration.k8s.io",
+		Version:  "v1",
+		Resource: "validatingadmissionpolicies",
+	}, {
+		Group:    "admissionregistration.k8s.io",
+		Version:  "v1",
+		Resource: "validatingadmissionpolicybindings",
+	}}
+}
+
+func (a *admissionPolicies) Enabled(request *common.Request) bool {
+	policies := request.Instance.Spec.AdmissionPolicies
+	return policies != nil && pointer.BoolPtrDerefOr(policies.Enabled, false)
+}
+
+// Version is empty, the policies are versioned with the operator
+func (a *admissionPolicies) Version(*common.Request) string {
+	return ""
+}
+
+func (a *admissionPolicies) Reconcile(request *common.Request) ([]common.ResourceStatus, error) {
+	var funcs []common.ReconcileFunc
+	for _, policy := range newPolicies(request) {
+		funcs = append(funcs, reconcileFunc(policy), reconcileFunc(newPolicyBinding(policy.GetName())))
+	}
+	return common.CollectResourceStatus(request, funcs...)
+}
+
+func (a *admissionPolicies) Cleanup(request *common.Request) error {
+	for _, policy := range newPolicies(request) {
+		for _, obj := range []*unstructured.Unstructured{newPolicyBinding(policy.GetName()), policy} {
+			err := request.Client.Delete(request.Context, obj)
+			if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s\": %s", obj.GetName(), err))
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var _ operands.Operand = &admissionPolicies{}
+
+func GetOperand() operands.Operand {
+	return &admissionPolicies{}
+}
+
+const (
+	operandName      = "admission-policies"
+	operandComponent = common.AppComponentTemplating
+)
+
+func newPolicies(request *common.Request) []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		newSingletonPolicy(request.Instance),
+		newTemplatesNamespacePolicy(),
+	}
+}
+
+func reconcileFunc(obj *unstructured.Unstructured) common.ReconcileFunc {
+	return func(request *common.Request) (common.ResourceStatus, error) {
+		return common.CreateOrUpdate(request).
+			ClusterResource(obj).
+			WithAppLabels(operandName, operandComponent).
+			Reconcile()
+	}
+}
@@ -0,0 +1,128 @@
+package admission_policies
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	. "kubevirt.io/ssp-operator/internal/test-utils"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+)
+
+var log = logf.Log.WithName("admission_policies_operand")
+
+var _ = Describe("Admission policies operand", func() {
+	const (
+		namespace = "kubevirt"
+		name      = "test-ssp"
+	)
+
+	var (
+		request common.Request
+		operand = GetOperand()
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(ssp.AddToScheme(s)).ToNot(HaveOccurred())
+		Expect(operand.AddWatchTypesToScheme(s)).ToNot(HaveOccurred())
+		// The fake client needs to know the policy kinds, even for unstructured objects
+		for _, gvk := range []schema.GroupVersionKind{ValidatingAdmissionPolicyGVK, ValidatingAdmissionPolicyBindingGVK} {
+			s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+		}
+
+		request = common.Request{
+			Request: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: namespace,
+					Name:      name,
+				},
+			},
+			Client:  fake.NewFakeClientWithScheme(s),
+			Scheme:  s,
+			Context: context.Background(),
+			Instance: &ssp.SSP{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "SSP",
+					APIVersion: ssp.GroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: ssp.SSPSpec{
+					AdmissionPolicies: &ssp.AdmissionPolicies{
+						Enabled: pointer.BoolPtr(true),
+					},
+				},
+			},
+			Logger:       log,
+			VersionCache: common.NewVersionCache(),
+		}
+	})
+
+	It("should be disabled by default", func() {
+		request.Instance.Spec.AdmissionPolicies = nil
+		Expect(operand.Enabled(&request)).To(BeFalse())
+
+		request.Instance.Spec.AdmissionPolicies = &ssp.AdmissionPolicies{}
+		Expect(operand.Enabled(&request)).To(BeFalse())
+	})
+
+	It("should create policies and bindings", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		ExpectResourceExists(newSingletonPolicy(request.Instance), request)
+		ExpectResourceExists(newPolicyBinding(SingletonPolicyName), request)
+		ExpectResourceExists(newTemplatesNamespacePolicy(), request)
+		ExpectResourceExists(newPolicyBinding(TemplatesNamespacePolicyName), request)
+	})
+
+	It("should allow only the existing SSP CR to be created", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		policy := newSingletonPolicy(request.Instance)
+		ExpectResourceExists(policy, request)
+		validations, _, err := unstructured.NestedSlice(policy.Object, "spec", "validations")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(validations).To(HaveLen(1))
+		Expect(validations[0]).To(HaveKeyWithValue("expression",
+			"object.metadata.namespace == 'kubevirt' && object.metadata.name == 'test-ssp'"))
+	})
+
+	It("should remove policies and bindings on cleanup", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(operand.Cleanup(&request)).To(Succeed())
+
+		ExpectResourceNotExists(newSingletonPolicy(request.Instance), request)
+		ExpectResourceNotExists(newPolicyBinding(SingletonPolicyName), request)
+		ExpectResourceNotExists(newTemplatesNamespacePolicy(), request)
+		ExpectResourceNotExists(newPolicyBinding(TemplatesNamespacePolicyName), request)
+	})
+
+	It("should ignore missing policies on cleanup", func() {
+		Expect(operand.Cleanup(&request)).To(Succeed())
+	})
+})
+
+func TestAdmissionPolicies(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Admission Policies Suite")
+}
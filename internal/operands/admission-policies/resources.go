@@ -0,0 +1,91 @@
+package admission_policies
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
+// The ValidatingAdmissionPolicy types are newer than the vendored Kubernetes API,
+// so the policies and their bindings are handled as unstructured objects.
+var (
+	ValidatingAdmissionPolicyGVK = schema.GroupVersionKind{
+		Group:   "admissionregistration.k8s.io",
+		Version: "v1",
+		Kind:    "ValidatingAdmissionPolicy",
+	}
+	ValidatingAdmissionPolicyBindingGVK = schema.GroupVersionKind{
+		Group:   "admissionregistration.k8s.io",
+		Version: "v1",
+		Kind:    "ValidatingAdmissionPolicyBinding",
+	}
+)
+
+const (
+	SingletonPolicyName          = "ssp-singleton.ssp.kubevirt.io"
+	TemplatesNamespacePolicyName = "ssp-templates-namespace.ssp.kubevirt.io"
+)
+
+// newSingletonPolicy returns a policy that rejects the creation of any SSP CR other than the existing one
+func newSingletonPolicy(sspObj *ssp.SSP) *unstructured.Unstructured {
+	return newPolicy(SingletonPolicyName, "CREATE", map[string]interface{}{
+		"expression": fmt.Sprintf("object.metadata.namespace == '%s' && object.metadata.name == '%s'",
+			sspObj.Namespace, sspObj.Name),
+		"message": fmt.Sprintf("creation failed, an SSP CR already exists in namespace %s: %s. Only one SSP CR is supported in the cluster",
+			sspObj.Namespace, sspObj.Name),
+	})
+}
+
+// newTemplatesNamespacePolicy returns a policy that rejects changes of spec.commonTemplates.namespace,
+// unless the migration annotation is set to a true value.
+// The values are the ones accepted by strconv.ParseBool, as in the webhook.
+func newTemplatesNamespacePolicy() *unstructured.Unstructured {
+	annotation := ssp.MigrateTemplatesNamespaceAnnotation
+	return newPolicy(TemplatesNamespacePolicyName, "UPDATE", map[string]interface{}{
+		"expression": fmt.Sprintf("object.spec.commonTemplates.namespace == oldObject.spec.commonTemplates.namespace || "+
+			"(has(object.metadata.annotations) && '%[1]s' in object.metadata.annotations && "+
+			"object.metadata.annotations['%[1]s'] in ['1', 't', 'T', 'TRUE', 'true', 'True'])", annotation),
+		"message": fmt.Sprintf("commonTemplates.namespace cannot be changed. "+
+			"Set the %s annotation to \"true\" to move the templates to the new namespace", annotation),
+	})
+}
+
+// newPolicy returns a policy for SSP CRs with a single validation.
+// Requests for other API versions are converted to v1beta1, so the expressions use the v1beta1 fields.
+func newPolicy(name string, operation string, validation map[string]interface{}) *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"failurePolicy": "Fail",
+			"matchConstraints": map[string]interface{}{
+				"matchPolicy": "Equivalent",
+				"resourceRules": []interface{}{
+					map[string]interface{}{
+						"apiGroups":   []interface{}{ssp.GroupVersion.Group},
+						"apiVersions": []interface{}{ssp.GroupVersion.Version},
+						"operations":  []interface{}{operation},
+						"resources":   []interface{}{"ssps"},
+					},
+				},
+			},
+			"validations": []interface{}{validation},
+		},
+	}}
+	policy.SetGroupVersionKind(ValidatingAdmissionPolicyGVK)
+	policy.SetName(name)
+	return policy
+}
+
+func newPolicyBinding(policyName string) *unstructured.Unstructured {
+	binding := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"policyName":        policyName,
+			"validationActions": []interface{}{"Deny"},
+		},
+	}}
+	binding.SetGroupVersionKind(ValidatingAdmissionPolicyBindingGVK)
+	binding.SetName(policyName)
+	return binding
+}
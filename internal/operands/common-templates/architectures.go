@@ -0,0 +1,56 @@
+package common_templates
+
+import (
+	"strings"
+
+	templatev1 "github.com/openshift/api/template/v1"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// workloadArchitectures returns the set of architectures from spec.cluster.workloadArchitectures,
+// or nil if all architectures should be supported
+func workloadArchitectures(request *common.Request) map[ssp.Architecture]struct{} {
+	cluster := request.Instance.Spec.Cluster
+	if cluster == nil || len(cluster.WorkloadArchitectures) == 0 {
+		return nil
+	}
+	architectures := make(map[ssp.Architecture]struct{}, len(cluster.WorkloadArchitectures))
+	for _, architecture := range cluster.WorkloadArchitectures {
+		architectures[architecture] = struct{}{}
+	}
+	return architectures
+}
+
+// defaultArchitecture returns the default architecture configured in KubeVirt,
+// or ssp.DefaultArchitecture if there is none
+func defaultArchitecture(kubeVirt *common.KubeVirtConfig) ssp.Architecture {
+	if kubeVirt != nil && kubeVirt.DefaultArchitecture != "" {
+		return ssp.Architecture(kubeVirt.DefaultArchitecture)
+	}
+	return ssp.DefaultArchitecture
+}
+
+// templateArchitecture returns the architecture of the VMs created from the template
+func templateArchitecture(template *templatev1.Template, defaultArchitecture ssp.Architecture) ssp.Architecture {
+	if architecture := template.Annotations[ssp.TemplateArchitectureAnnotation]; architecture != "" {
+		return ssp.Architecture(architecture)
+	}
+	return defaultArchitecture
+}
+
+// supportsWorkloadArchitecture returns true if the DataImportCron template
+// supports at least one of the workload architectures
+func supportsWorkloadArchitecture(cronTemplate *ssp.DataImportCronTemplate, architectures map[ssp.Architecture]struct{}) bool {
+	supported := cronTemplate.Annotations[ssp.DataImportCronArchitecturesAnnotation]
+	if architectures == nil || supported == "" {
+		return true
+	}
+	for _, architecture := range strings.Split(supported, ",") {
+		if _, ok := architectures[ssp.Architecture(strings.TrimSpace(architecture))]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package common_templates
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=dataimportcrons,verbs=get;list;watch;create;update;patch;delete
+
+// DataImportCronGVK is the GroupVersionKind of the CDI DataImportCron.
+// CDI types are not vendored, so DataImportCrons are handled as unstructured objects.
+var DataImportCronGVK = schema.GroupVersionKind{
+	Group:   "cdi.kubevirt.io",
+	Version: "v1beta1",
+	Kind:    "DataImportCron",
+}
+
+// newWatchedDataImportCron returns the DataImportCron type watched by the operator.
+// It is watched only if CDI is installed.
+func newWatchedDataImportCron() *unstructured.Unstructured {
+	dataImportCron := &unstructured.Unstructured{}
+	dataImportCron.SetGroupVersionKind(DataImportCronGVK)
+	return dataImportCron
+}
+
+func reconcileDataImportCronsFuncs(request *common.Request) ([]common.ReconcileFunc, error) {
+	cronTemplates := dataImportCronTemplates(request)
+	funcs := make([]common.ReconcileFunc, 0, len(cronTemplates))
+	for i := range cronTemplates {
+		dataImportCron, err := newDataImportCron(&cronTemplates[i], bootSourceNamespace(request),
+			request.Instance.Spec.CommonTemplates.BootSourceStorage)
+		if err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, func(request *common.Request) (common.ResourceStatus, error) {
+			status, err := common.CreateOrUpdate(request).
+				ClusterResource(dataImportCron).
+				WithAppLabels(operandName, operandComponent).
+				Reconcile()
+			if meta.IsNoMatchError(err) {
+				// CDI is not installed, the DataImportCron is created once its CRD exists
+				message := "DataImportCron CRD is not installed, the boot source is not imported"
+				return common.ResourceStatus{Resource: dataImportCron, Degraded: &message}, nil
+			}
+			return status, err
+		})
+	}
+	return funcs, nil
+}
+
+// dataImportCronTemplates returns the DataImportCron templates that should be deployed.
+// No DataImportCrons are deployed if the boot image import is disabled.
+// Templates that do not support any of the workload architectures are skipped.
+func dataImportCronTemplates(request *common.Request) []ssp.DataImportCronTemplate {
+	if !pointer.BoolPtrDerefOr(request.Instance.Spec.CommonTemplates.EnableCommonBootImageImport, true) {
+		return nil
+	}
+	architectures := workloadArchitectures(request)
+	var result []ssp.DataImportCronTemplate
+	cronTemplates := request.Instance.Spec.CommonTemplates.DataImportCronTemplates
+	for i := range cronTemplates {
+		if supportsWorkloadArchitecture(&cronTemplates[i], architectures) {
+			result = append(result, cronTemplates[i])
+		}
+	}
+	return result
+}
+
+// removeStaleDataImportCrons deletes DataImportCrons owned by the SSP CR
+// that are no longer listed in spec.commonTemplates.dataImportCronTemplates,
+// or all of them if the boot image import is disabled.
+// DataSources and volumes imported by the DataImportCrons are not removed.
+func removeStaleDataImportCrons(request *common.Request) error {
+	cronTemplates := dataImportCronTemplates(request)
+
+	ownedCrons, err := listOwnedDataImportCrons(request)
+	if err != nil {
+		return err
+	}
+
+	expectedCrons := map[client.ObjectKey]struct{}{}
+	for i := range cronTemplates {
+		expectedCrons[client.ObjectKey{
+			Namespace: dataImportCronNamespace(&cronTemplates[i], bootSourceNamespace(request)),
+			Name:      cronTemplates[i].Name,
+		}] = struct{}{}
+	}
+
+	for i := range ownedCrons {
+		cron := &ownedCrons[i]
+		if _, ok := expectedCrons[client.ObjectKey{Namespace: cron.GetNamespace(), Name: cron.GetName()}]; ok {
+			continue
+		}
+		err = request.Client.Delete(request.Context, cron)
+		if err != nil && !errors.IsNotFound(err) {
+			request.Logger.Error(err, fmt.Sprintf("Error deleting DataImportCron \"%s/%s\": %s", cron.GetNamespace(), cron.GetName(), err))
+			return err
+		}
+	}
+	return nil
+}
+
+func newDataImportCron(cronTemplate *ssp.DataImportCronTemplate, defaultNamespace string, storage *ssp.BootSourceStorage) (*unstructured.Unstructured, error) {
+	dataImportCron := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if len(cronTemplate.Spec.Raw) > 0 {
+		spec := map[string]interface{}{}
+		err := json.Unmarshal(cronTemplate.Spec.Raw, &spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse spec of DataImportCron template %s: %w", cronTemplate.Name, err)
+		}
+		dataImportCron.Object["spec"] = spec
+	}
+	err := setStorageDefaults(dataImportCron, storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set storage of DataImportCron template %s: %w", cronTemplate.Name, err)
+	}
+	dataImportCron.SetGroupVersionKind(DataImportCronGVK)
+	dataImportCron.SetName(cronTemplate.Name)
+	dataImportCron.SetNamespace(dataImportCronNamespace(cronTemplate, defaultNamespace))
+	dataImportCron.SetLabels(copyStringMap(cronTemplate.Labels))
+	dataImportCron.SetAnnotations(copyStringMap(cronTemplate.Annotations))
+	return dataImportCron, nil
+}
+
+// setStorageDefaults sets the storage of the DataVolume template of the DataImportCron,
+// if it is not already set. The pvc section of the DataVolume is used if it is present,
+// otherwise the storage section.
+func setStorageDefaults(dataImportCron *unstructured.Unstructured, storage *ssp.BootSourceStorage) error {
+	if storage == nil {
+		return nil
+	}
+	dataVolumeSpec := []string{"spec", "template", "spec"}
+	storageField := append(dataVolumeSpec, "storage")
+	if _, found, _ := unstructured.NestedMap(dataImportCron.Object, append(dataVolumeSpec, "pvc")...); found {
+		storageField = append(dataVolumeSpec, "pvc")
+	}
+
+	setDefault := func(value interface{}, fields ...string) error {
+		path := append(append([]string{}, storageField...), fields...)
+		if _, found, _ := unstructured.NestedFieldNoCopy(dataImportCron.Object, path...); found {
+			return nil
+		}
+		return unstructured.SetNestedField(dataImportCron.Object, value, path...)
+	}
+
+	if storage.StorageClassName != nil {
+		if err := setDefault(*storage.StorageClassName, "storageClassName"); err != nil {
+			return err
+		}
+	}
+	if storage.VolumeMode != nil {
+		if err := setDefault(string(*storage.VolumeMode), "volumeMode"); err != nil {
+			return err
+		}
+	}
+	if storage.Size != nil {
+		if err := setDefault(storage.Size.String(), "resources", "requests", "storage"); err != nil {
+			return err
+		}
+	}
+	if len(storage.AccessModes) > 0 {
+		accessModes := make([]interface{}, 0, len(storage.AccessModes))
+		for _, accessMode := range storage.AccessModes {
+			accessModes = append(accessModes, string(accessMode))
+		}
+		if err := setDefault(accessModes, "accessModes"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOwnedDataImportCrons returns the DataImportCrons created by the operator for the SSP CR.
+// No DataImportCrons are returned if CDI is not installed.
+func listOwnedDataImportCrons(request *common.Request) ([]unstructured.Unstructured, error) {
+	return ssp.ListOwnedDataImportCrons(request.Context, request.Client, request.Instance, client.MatchingLabels{
+		common.AppKubernetesNameLabel:      operandName,
+		common.AppKubernetesManagedByLabel: "ssp-operator",
+	})
+}
+
+func dataImportCronNamespace(cronTemplate *ssp.DataImportCronTemplate, defaultNamespace string) string {
+	if cronTemplate.Namespace != "" {
+		return cronTemplate.Namespace
+	}
+	return defaultNamespace
+}
+
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for key, val := range in {
+		out[key] = val
+	}
+	return out
+}
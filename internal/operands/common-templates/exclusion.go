@@ -0,0 +1,114 @@
+package common_templates
+
+import (
+	"fmt"
+	"strings"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateExclusion selects bundle templates that should not be deployed
+type templateExclusion struct {
+	names    map[string]struct{}
+	selector labels.Selector
+	// architectures are the allowed template architectures, nil allows all of them
+	architectures map[ssp.Architecture]struct{}
+	// defaultArchitecture is the architecture of templates without the architecture annotation
+	defaultArchitecture ssp.Architecture
+	// kubeVirt is used to check the required feature gates, nil allows all of them
+	kubeVirt *common.KubeVirtConfig
+}
+
+func newTemplateExclusion(exclude *ssp.TemplatesExclusion, architectures map[ssp.Architecture]struct{}, kubeVirt *common.KubeVirtConfig) (*templateExclusion, error) {
+	exclusion := &templateExclusion{
+		names:               map[string]struct{}{},
+		selector:            labels.Nothing(),
+		architectures:       architectures,
+		defaultArchitecture: defaultArchitecture(kubeVirt),
+		kubeVirt:            kubeVirt,
+	}
+	if exclude == nil {
+		return exclusion, nil
+	}
+	for _, name := range exclude.Names {
+		exclusion.names[name] = struct{}{}
+	}
+	if exclude.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(exclude.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commonTemplates.exclude.selector: %w", err)
+		}
+		exclusion.selector = selector
+	}
+	return exclusion, nil
+}
+
+func (e *templateExclusion) excludes(template *templatev1.Template) bool {
+	if _, ok := e.names[template.Name]; ok {
+		return true
+	}
+	if e.architectures != nil {
+		if _, ok := e.architectures[templateArchitecture(template, e.defaultArchitecture)]; !ok {
+			return true
+		}
+	}
+	if !e.hasRequiredFeatureGates(template) {
+		return true
+	}
+	return e.selector.Matches(labels.Set(template.Labels))
+}
+
+func (e *templateExclusion) hasRequiredFeatureGates(template *templatev1.Template) bool {
+	required := template.Annotations[ssp.TemplateRequiredFeatureGatesAnnotation]
+	if e.kubeVirt == nil || required == "" {
+		return true
+	}
+	for _, featureGate := range strings.Split(required, ",") {
+		featureGate = strings.TrimSpace(featureGate)
+		if featureGate != "" && !e.kubeVirt.HasFeatureGate(featureGate) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeExcludedTemplates deletes previously deployed templates that are now excluded.
+// Templates owned by another SSP CR are not deleted.
+func removeExcludedTemplates(request *common.Request, exclusion *templateExclusion) error {
+	owner := request.Instance.Namespace + "/" + request.Instance.Name
+	for _, namespace := range templateNamespaces(request) {
+		managedTemplates := &templatev1.TemplateList{}
+		err := request.Client.List(request.Context, managedTemplates,
+			client.InNamespace(namespace),
+			client.MatchingLabels{
+				common.AppKubernetesNameLabel:      operandName,
+				common.AppKubernetesManagedByLabel: "ssp-operator",
+			})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		for i := range managedTemplates.Items {
+			template := &managedTemplates.Items[i]
+			if !exclusion.excludes(template) {
+				continue
+			}
+			if template.GetAnnotations()[libhandler.NamespacedNameAnnotation] != owner {
+				continue
+			}
+			err = request.Client.Delete(request.Context, template)
+			if err != nil && !errors.IsNotFound(err) {
+				request.Logger.Error(err, fmt.Sprintf("Error deleting excluded template \"%s/%s\": %s", namespace, template.Name, err))
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package common_templates
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kubevirt.io/ssp-operator/internal/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=cdis,verbs=list;patch
+
+// CDIGVK is the GroupVersionKind of the CDI CR, that configures the CDI installation.
+var CDIGVK = schema.GroupVersionKind{
+	Group:   "cdi.kubevirt.io",
+	Version: "v1beta1",
+	Kind:    "CDI",
+}
+
+var importProxyField = []string{"spec", "config", "importProxy"}
+
+// reconcileImportProxy sets spec.proxy as the import proxy of CDI, so the importer pods
+// of the DataImportCrons download boot sources through the proxy.
+// The import proxy is a cluster-wide CDI setting. It is only set while the SSP CR
+// configures a proxy and boot sources are imported, otherwise it is released.
+func reconcileImportProxy(request *common.Request) error {
+	proxy := request.Instance.Spec.Proxy
+	if proxy == nil || len(dataImportCronTemplates(request)) == 0 {
+		return releaseImportProxy(request)
+	}
+
+	cdis, err := listCDIs(request)
+	if err != nil {
+		return err
+	}
+	for i := range cdis {
+		applied := newAppliedCDI(cdis[i].GetName())
+		applied.Object["spec"] = map[string]interface{}{
+			"config": map[string]interface{}{
+				"importProxy": map[string]interface{}{
+					"HTTPProxy":  proxy.HTTPProxy,
+					"HTTPSProxy": proxy.HTTPSProxy,
+					"noProxy":    proxy.NoProxy,
+				},
+			},
+		}
+		err = applyCDI(request, applied)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseImportProxy applies an empty configuration to the CDI CRs that have an import proxy,
+// so the import proxy set by the operator is removed. An import proxy set by others is kept.
+func releaseImportProxy(request *common.Request) error {
+	cdis, err := listCDIs(request)
+	if err != nil {
+		return err
+	}
+	for i := range cdis {
+		if _, found, _ := unstructured.NestedFieldNoCopy(cdis[i].Object, importProxyField...); !found {
+			continue
+		}
+		err = applyCDI(request, newAppliedCDI(cdis[i].GetName()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listCDIs returns the CDI CRs. None are returned if CDI is not installed.
+func listCDIs(request *common.Request) ([]unstructured.Unstructured, error) {
+	cdis := &unstructured.UnstructuredList{}
+	cdis.SetGroupVersionKind(CDIGVK.GroupVersion().WithKind(CDIGVK.Kind + "List"))
+	err := request.Client.List(request.Context, cdis)
+	if err != nil {
+		if meta.IsNoMatchError(err) || errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cdis.Items, nil
+}
+
+func newAppliedCDI(name string) *unstructured.Unstructured {
+	cdi := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	cdi.SetGroupVersionKind(CDIGVK)
+	cdi.SetName(name)
+	return cdi
+}
+
+func applyCDI(request *common.Request, cdi *unstructured.Unstructured) error {
+	err := request.Client.Patch(request.Context, cdi, client.Apply,
+		client.FieldOwner(common.FieldManager), client.ForceOwnership)
+	if err != nil {
+		request.Logger.Error(err, fmt.Sprintf("Error applying import proxy to CDI \"%s\": %s", cdi.GetName(), err))
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package common_templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+// ProtectionWebhookPath is the path of the webhook protecting the common templates.
+// The webhook configuration only selects templates with the labels of the operand.
+const ProtectionWebhookPath = "/validate-common-templates"
+
+// Users of the controllers that remove templates when their namespace is removed
+var protectionExemptUsers = map[string]struct{}{
+	"system:kube-controller-manager":                              {},
+	"system:serviceaccount:kube-system:namespace-controller":      {},
+	"system:serviceaccount:kube-system:generic-garbage-collector": {},
+}
+
+// NewProtectionWebhook returns a webhook that rejects modification and deletion of the common
+// templates, unless the template has the AllowTemplateModificationAnnotation annotation.
+// Requests of the operator service account are allowed, so the operator can update
+// the templates. The webhook allows all requests if it is not enabled.
+func NewProtectionWebhook(operatorNamespace, operatorServiceAccount string, enabled bool) *webhook.Admission {
+	return &webhook.Admission{Handler: &protectionHandler{
+		enabled:          enabled,
+		operatorUsername: "system:serviceaccount:" + operatorNamespace + ":" + operatorServiceAccount,
+	}}
+}
+
+type protectionHandler struct {
+	enabled          bool
+	operatorUsername string
+}
+
+var _ admission.Handler = &protectionHandler{}
+
+func (h *protectionHandler) Handle(_ context.Context, req admission.Request) admission.Response {
+	if !h.enabled {
+		return admission.Allowed("")
+	}
+	if req.Operation != admissionv1beta1.Update && req.Operation != admissionv1beta1.Delete {
+		return admission.Allowed("")
+	}
+	if _, exempt := protectionExemptUsers[req.UserInfo.Username]; exempt {
+		return admission.Allowed("")
+	}
+	if req.UserInfo.Username == h.operatorUsername {
+		return admission.Allowed("")
+	}
+
+	oldTemplate := &metav1.PartialObjectMetadata{}
+	err := json.Unmarshal(req.OldObject.Raw, oldTemplate)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !isManagedTemplate(oldTemplate) {
+		return admission.Allowed("")
+	}
+
+	// The annotation can be added by the same update that modifies the template
+	annotatedTemplate := oldTemplate
+	action := "delete"
+	if req.Operation == admissionv1beta1.Update {
+		annotatedTemplate = &metav1.PartialObjectMetadata{}
+		err = json.Unmarshal(req.Object.Raw, annotatedTemplate)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		action = "modify"
+	}
+	if isTemplateModificationAllowed(annotatedTemplate) {
+		return admission.Allowed("")
+	}
+
+	return admission.Denied(fmt.Sprintf("template %s/%s is managed by the SSP operator, which restores it. "+
+		"Set the %s annotation to \"true\" to %s it, or exclude it in the SSP CR",
+		oldTemplate.Namespace, oldTemplate.Name, ssp.AllowTemplateModificationAnnotation, action))
+}
+
+func isManagedTemplate(template *metav1.PartialObjectMetadata) bool {
+	labels := template.GetLabels()
+	return labels[common.AppKubernetesNameLabel] == operandName &&
+		labels[common.AppKubernetesManagedByLabel] == "ssp-operator"
+}
+
+func isTemplateModificationAllowed(template *metav1.PartialObjectMetadata) bool {
+	allowed, err := strconv.ParseBool(template.GetAnnotations()[ssp.AllowTemplateModificationAnnotation])
+	return err == nil && allowed
+}
@@ -0,0 +1,130 @@
+package common_templates
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	templatev1 "github.com/openshift/api/template/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+var _ = Describe("Template protection webhook", func() {
+	const templatesNamespace = "kubevirt-templates"
+
+	var (
+		protectionWebhook *webhook.Admission
+		template          *templatev1.Template
+	)
+
+	rawTemplate := func(template *templatev1.Template) runtime.RawExtension {
+		raw, err := json.Marshal(template)
+		Expect(err).ToNot(HaveOccurred())
+		return runtime.RawExtension{Raw: raw}
+	}
+
+	newRequest := func(operation admissionv1beta1.Operation, oldTemplate, newTemplate *templatev1.Template) admission.Request {
+		request := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: operation,
+			Namespace: oldTemplate.Namespace,
+			Name:      oldTemplate.Name,
+			OldObject: rawTemplate(oldTemplate),
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user",
+				Groups:   []string{"system:authenticated"},
+			},
+		}}
+		if newTemplate != nil {
+			request.Object = rawTemplate(newTemplate)
+		}
+		return request
+	}
+
+	BeforeEach(func() {
+		protectionWebhook = NewProtectionWebhook(namespace, "ssp-operator", true)
+		template = &templatev1.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-template",
+				Namespace: templatesNamespace,
+				Labels: map[string]string{
+					common.AppKubernetesNameLabel:      operandName,
+					common.AppKubernetesManagedByLabel: "ssp-operator",
+				},
+			},
+		}
+	})
+
+	It("should reject deletion of a common template", func() {
+		response := protectionWebhook.Handle(context.Background(), newRequest(admissionv1beta1.Delete, template, nil))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(ContainSubstring(ssp.AllowTemplateModificationAnnotation))
+	})
+
+	It("should reject modification of a common template", func() {
+		updated := template.DeepCopy()
+		updated.Labels["custom-label"] = "value"
+		response := protectionWebhook.Handle(context.Background(), newRequest(admissionv1beta1.Update, template, updated))
+		Expect(response.Allowed).To(BeFalse())
+	})
+
+	It("should allow modification that adds the annotation", func() {
+		updated := template.DeepCopy()
+		updated.Annotations = map[string]string{ssp.AllowTemplateModificationAnnotation: "true"}
+		response := protectionWebhook.Handle(context.Background(), newRequest(admissionv1beta1.Update, template, updated))
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should allow deletion of an annotated template", func() {
+		template.Annotations = map[string]string{ssp.AllowTemplateModificationAnnotation: "true"}
+		response := protectionWebhook.Handle(context.Background(), newRequest(admissionv1beta1.Delete, template, nil))
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should allow deletion of a template not managed by the operator", func() {
+		delete(template.Labels, common.AppKubernetesManagedByLabel)
+		response := protectionWebhook.Handle(context.Background(), newRequest(admissionv1beta1.Delete, template, nil))
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should allow requests of the operator", func() {
+		request := newRequest(admissionv1beta1.Delete, template, nil)
+		request.UserInfo = authenticationv1.UserInfo{
+			Username: "system:serviceaccount:" + namespace + ":ssp-operator",
+			Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace},
+		}
+		response := protectionWebhook.Handle(context.Background(), request)
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should reject requests of other service accounts in the operator namespace", func() {
+		request := newRequest(admissionv1beta1.Delete, template, nil)
+		request.UserInfo = authenticationv1.UserInfo{
+			Username: "system:serviceaccount:" + namespace + ":default",
+			Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace},
+		}
+		response := protectionWebhook.Handle(context.Background(), request)
+		Expect(response.Allowed).To(BeFalse())
+	})
+
+	It("should allow removal of the template namespace", func() {
+		request := newRequest(admissionv1beta1.Delete, template, nil)
+		request.UserInfo.Username = "system:serviceaccount:kube-system:namespace-controller"
+		response := protectionWebhook.Handle(context.Background(), request)
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should allow all requests when disabled", func() {
+		protectionWebhook = NewProtectionWebhook(namespace, "ssp-operator", false)
+		response := protectionWebhook.Handle(context.Background(), newRequest(admissionv1beta1.Delete, template, nil))
+		Expect(response.Allowed).To(BeTrue())
+	})
+})
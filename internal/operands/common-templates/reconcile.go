@@ -4,16 +4,24 @@ import (
 	"fmt"
 	"strings"
 
+	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"sync"
 
 	templatev1 "github.com/openshift/api/template/v1"
+	libhandler "github.com/operator-framework/operator-lib/handler"
 	core "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/utils/pointer"
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
 	"kubevirt.io/ssp-operator/internal/common"
 	"kubevirt.io/ssp-operator/internal/operands"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -21,8 +29,9 @@ import (
 )
 
 var (
-	loadTemplatesOnce sync.Once
-	templatesBundle   []templatev1.Template
+	// Bundles shipped with the operator are only read once
+	loadedBundlesLock sync.Mutex
+	loadedBundles     = map[string][]templatev1.Template{}
 )
 
 // Define RBAC rules needed by this operand:
@@ -62,6 +71,7 @@ func (c *commonTemplates) WatchClusterTypes() []runtime.Object {
 		&rbac.RoleBinding{},
 		&core.Namespace{},
 		&templatev1.Template{},
+		newWatchedDataImportCron(),
 	}
 }
 
@@ -69,6 +79,27 @@ func (c *commonTemplates) WatchTypes() []runtime.Object {
 	return nil
 }
 
+func (c *commonTemplates) RequiredAPIs() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{{
+		Group:    "template.openshift.io",
+		Version:  "v1",
+		Resource: "templates",
+	}}
+}
+
+func (c *commonTemplates) Enabled(request *common.Request) bool {
+	return pointer.BoolPtrDerefOr(request.Instance.Spec.CommonTemplates.Enabled, true)
+}
+
+// Version returns the version of the built-in templates bundle.
+// It is empty if a custom bundle is used, because its content is not versioned.
+func (c *commonTemplates) Version(request *common.Request) string {
+	if request.Instance.Spec.CommonTemplates.BundleRef != nil {
+		return ""
+	}
+	return templatesVersion(request)
+}
+
 func (c *commonTemplates) Reconcile(request *common.Request) ([]common.ResourceStatus, error) {
 	funcs := []common.ReconcileFunc{
 		reconcileGoldenImagesNS,
@@ -77,68 +108,144 @@ func (c *commonTemplates) Reconcile(request *common.Request) ([]common.ResourceS
 		reconcileEditRole,
 	}
 
+	exclusion, err := newTemplateExclusion(request.Instance.Spec.CommonTemplates.Exclude, workloadArchitectures(request), request.KubeVirt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = removeStaleBootSourceRoles(request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = removeExcludedTemplates(request, exclusion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = removeStaleTemplates(request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = removeStaleDataImportCrons(request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = reconcileImportProxy(request)
+	if err != nil {
+		return nil, err
+	}
+
 	oldTemplateFuncs, err := reconcileOlderTemplates(request)
 	if err != nil {
 		return nil, err
 	}
 
-	funcs = append(funcs, oldTemplateFuncs...)
-	funcs = append(funcs, reconcileTemplatesFuncs(request)...)
+	templateFuncs, err := reconcileTemplatesFuncs(request, exclusion)
+	if err != nil {
+		return nil, err
+	}
+
+	dataImportCronFuncs, err := reconcileDataImportCronsFuncs(request)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := common.CollectResourceStatus(request, funcs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older templates are updated before the templates of the current version are deployed,
+	// so both phases run one after the other. Each phase is reconciled concurrently.
+	oldTemplateStatuses, err := common.CollectResourceStatusConcurrently(request, oldTemplateFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	statuses = append(statuses, oldTemplateStatuses...)
 
-	return common.CollectResourceStatus(request, funcs...)
+	// Templates and DataImportCrons do not depend on each other
+	var independentFuncs []common.ReconcileFunc
+	independentFuncs = append(independentFuncs, templateFuncs...)
+	independentFuncs = append(independentFuncs, dataImportCronFuncs...)
+	independentStatuses, err := common.CollectResourceStatusConcurrently(request, independentFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Templates are removed from previous namespaces after they are deployed
+	// to the current ones, so they are always available while moving them
+	err = removeTemplatesFromStaleNamespaces(request)
+	if err != nil {
+		return nil, err
+	}
+	return append(statuses, independentStatuses...), nil
 }
 
 func (c *commonTemplates) Cleanup(request *common.Request) error {
 	objects := []controllerutil.Object{
-		newGoldenImagesNS(GoldenImagesNSname),
-		newViewRole(GoldenImagesNSname),
-		newViewRoleBinding(GoldenImagesNSname),
+		newGoldenImagesNS(bootSourceNamespace(request)),
+		newViewRole(bootSourceNamespace(request)),
+		newViewRoleBinding(bootSourceNamespace(request)),
 		newEditRole(),
 	}
-	namespace := request.Instance.Spec.CommonTemplates.Namespace
-	for index := range templatesBundle {
-		templatesBundle[index].ObjectMeta.Namespace = namespace
-		objects = append(objects, &templatesBundle[index])
+	bundle, err := loadTemplatesBundle(request)
+	if err != nil {
+		// The custom bundle may have been removed already, other resources are still cleaned up
+		request.Logger.Error(err, "Error loading templates bundle, templates will not be removed")
+	}
+	for _, namespace := range templateNamespaces(request) {
+		for index := range bundle {
+			objects = append(objects, templateInNamespace(&bundle[index], namespace))
+		}
+	}
+	olderTemplates, err := listOlderTemplates(request, bundle)
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	owner := request.Instance.Namespace + "/" + request.Instance.Name
+	for i := range olderTemplates {
+		if olderTemplates[i].GetAnnotations()[libhandler.NamespacedNameAnnotation] == owner {
+			objects = append(objects, &olderTemplates[i])
+		}
+	}
+	for i := range request.Instance.Spec.CommonTemplates.DataImportCronTemplates {
+		dataImportCron, err := newDataImportCron(&request.Instance.Spec.CommonTemplates.DataImportCronTemplates[i], bootSourceNamespace(request), nil)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, dataImportCron)
 	}
 	for _, obj := range objects {
 		err := request.Client.Delete(request.Context, obj)
-		if err != nil && !errors.IsNotFound(err) {
+		if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
 			request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s\": %s", obj.GetName(), err))
 			return err
 		}
 	}
-	return nil
+	return releaseImportProxy(request)
 }
 
 func reconcileGoldenImagesNS(request *common.Request) (common.ResourceStatus, error) {
 	return common.CreateOrUpdate(request).
-		ClusterResource(newGoldenImagesNS(GoldenImagesNSname)).
+		ClusterResource(newGoldenImagesNS(bootSourceNamespace(request))).
 		WithAppLabels(operandName, operandComponent).
 		Reconcile()
 }
 
 func reconcileViewRole(request *common.Request) (common.ResourceStatus, error) {
 	return common.CreateOrUpdate(request).
-		ClusterResource(newViewRole(GoldenImagesNSname)).
+		ClusterResource(newViewRole(bootSourceNamespace(request))).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRole := foundRes.(*rbac.Role)
-			newRole := newRes.(*rbac.Role)
-			foundRole.Rules = newRole.Rules
-		}).
 		Reconcile()
 }
 
 func reconcileViewRoleBinding(request *common.Request) (common.ResourceStatus, error) {
 	return common.CreateOrUpdate(request).
-		ClusterResource(newViewRoleBinding(GoldenImagesNSname)).
+		ClusterResource(newViewRoleBinding(bootSourceNamespace(request))).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			newBinding := newRes.(*rbac.RoleBinding)
-			foundBinding := foundRes.(*rbac.RoleBinding)
-			foundBinding.Subjects = newBinding.Subjects
-			foundBinding.RoleRef = newBinding.RoleRef
-		}).
 		Reconcile()
 }
 
@@ -146,16 +253,101 @@ func reconcileEditRole(request *common.Request) (common.ResourceStatus, error) {
 	return common.CreateOrUpdate(request).
 		ClusterResource(newEditRole()).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			newRole := newRes.(*rbac.ClusterRole)
-			foundRole := foundRes.(*rbac.ClusterRole)
-			foundRole.Rules = newRole.Rules
-		}).
 		Reconcile()
 }
 
+// bootSourceNamespace returns the namespace where the boot sources are imported
+func bootSourceNamespace(request *common.Request) string {
+	if namespace := request.Instance.Spec.CommonTemplates.BootSourceNamespace; namespace != "" {
+		return namespace
+	}
+	return GoldenImagesNSname
+}
+
+// removeStaleBootSourceRoles deletes the view role and role binding owned by the SSP CR
+// from a previous boot source namespace. The namespace itself is kept,
+// because it contains the already imported boot sources.
+func removeStaleBootSourceRoles(request *common.Request) error {
+	namespace := bootSourceNamespace(request)
+	owner := request.Instance.Namespace + "/" + request.Instance.Name
+	matchingLabels := client.MatchingLabels{
+		common.AppKubernetesNameLabel:      operandName,
+		common.AppKubernetesManagedByLabel: "ssp-operator",
+	}
+
+	roles := &rbac.RoleList{}
+	err := request.Client.List(request.Context, roles, matchingLabels)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	var staleObjects []controllerutil.Object
+	for i := range roles.Items {
+		staleObjects = append(staleObjects, &roles.Items[i])
+	}
+
+	roleBindings := &rbac.RoleBindingList{}
+	err = request.Client.List(request.Context, roleBindings, matchingLabels)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	for i := range roleBindings.Items {
+		staleObjects = append(staleObjects, &roleBindings.Items[i])
+	}
+
+	for _, obj := range staleObjects {
+		if obj.GetNamespace() == namespace || obj.GetName() != ViewRoleName {
+			continue
+		}
+		if obj.GetAnnotations()[libhandler.NamespacedNameAnnotation] != owner {
+			continue
+		}
+		err = request.Client.Delete(request.Context, obj)
+		if err != nil && !errors.IsNotFound(err) {
+			request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s/%s\": %s", obj.GetNamespace(), obj.GetName(), err))
+			return err
+		}
+		request.VersionCache.RemoveObj(obj)
+	}
+	return nil
+}
+
 func reconcileOlderTemplates(request *common.Request) ([]common.ReconcileFunc, error) {
+	bundle, err := loadTemplatesBundle(request)
+	if err != nil {
+		return nil, err
+	}
+
 	// Append functions to take ownership of previously deployed templates during an upgrade
+	existingTemplates, err := listOlderTemplates(request, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	existingTemplates, err = removeDeprecatedTemplates(request, existingTemplates, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := make([]common.ReconcileFunc, 0, len(existingTemplates))
+	for i := range existingTemplates {
+		template := &existingTemplates[i]
+		funcs = append(funcs, func(*common.Request) (common.ResourceStatus, error) {
+			return reconcileOlderTemplate(request, template)
+		})
+	}
+
+	return funcs, nil
+}
+
+// listOlderTemplates returns the common templates of previous versions in the template namespaces.
+// Templates with the name of a template in the active bundle are not older templates,
+// even if their version label differs, because they are reconciled from the bundle.
+func listOlderTemplates(request *common.Request, bundle []templatev1.Template) ([]templatev1.Template, error) {
+	bundleNames := map[string]struct{}{}
+	for i := range bundle {
+		bundleNames[bundle[i].Name] = struct{}{}
+	}
+
 	templatesSelector := func() labels.Selector {
 		baseRequirement, err := labels.NewRequirement("template.kubevirt.io/type", selection.Equals, []string{"base"})
 		if err != nil {
@@ -163,7 +355,7 @@ func reconcileOlderTemplates(request *common.Request) ([]common.ReconcileFunc, e
 		}
 
 		// Only fetching older templates  to prevent duplication of API calls
-		versionRequirement, err := labels.NewRequirement("template.kubevirt.io/version", selection.NotEquals, []string{Version})
+		versionRequirement, err := labels.NewRequirement(templateVersionLabel, selection.NotEquals, []string{templatesVersion(request)})
 		if err != nil {
 			panic("Failed creating label selector for 'template.kubevirt.io/version")
 		}
@@ -171,74 +363,308 @@ func reconcileOlderTemplates(request *common.Request) ([]common.ReconcileFunc, e
 		return labels.NewSelector().Add(*baseRequirement, *versionRequirement)
 	}()
 
-	existingTemplates := &templatev1.TemplateList{}
-	err := request.Client.List(request.Context, existingTemplates, &client.ListOptions{
-		LabelSelector: templatesSelector,
-		Namespace:     request.Instance.Spec.CommonTemplates.Namespace,
-	})
+	var existingTemplates []templatev1.Template
+	for _, namespace := range templateNamespaces(request) {
+		templateList := &templatev1.TemplateList{}
+		err := request.Client.List(request.Context, templateList, &client.ListOptions{
+			LabelSelector: templatesSelector,
+			Namespace:     namespace,
+		})
 
-	// There might not be any templates (in case of a fresh deployment), so a NotFound error is accepted
-	if err != nil && !errors.IsNotFound(err) {
-		return nil, err
+		// There might not be any templates (in case of a fresh deployment), so a NotFound error is accepted
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		for i := range templateList.Items {
+			if _, ok := bundleNames[templateList.Items[i].Name]; ok {
+				continue
+			}
+			existingTemplates = append(existingTemplates, templateList.Items[i])
+		}
 	}
+	return existingTemplates, nil
+}
 
-	funcs := make([]common.ReconcileFunc, 0, len(existingTemplates.Items))
-	for i := range existingTemplates.Items {
-		template := &existingTemplates.Items[i]
-		funcs = append(funcs, func(*common.Request) (common.ResourceStatus, error) {
-			return common.CreateOrUpdate(request).
-				ClusterResource(template).
-				WithAppLabels(operandName, operandComponent).
-				UpdateFunc(func(_, foundRes controllerutil.Object) {
-					foundTemplate := foundRes.(*templatev1.Template)
-					for key := range foundTemplate.Labels {
-						if strings.HasPrefix(key, "os.template.kubevirt.io/") ||
-							strings.HasPrefix(key, "flavor.template.kubevirt.io/") ||
-							strings.HasPrefix(key, "workload.template.kubevirt.io/") {
-							delete(foundTemplate.Labels, key)
-						}
-					}
-				}).
-				Reconcile()
-		})
+// reconcileOlderTemplate removes the labels used to find templates from an older template
+// and replaces its owner reference with owner annotations.
+// Older templates are updated instead of applied, because the labels
+// to remove are owned by the field manager of the previous operator version.
+func reconcileOlderTemplate(request *common.Request, template *templatev1.Template) (common.ResourceStatus, error) {
+	updated := template.DeepCopy()
+	for key := range updated.Labels {
+		if strings.HasPrefix(key, "os.template.kubevirt.io/") ||
+			strings.HasPrefix(key, "flavor.template.kubevirt.io/") ||
+			strings.HasPrefix(key, "workload.template.kubevirt.io/") {
+			delete(updated.Labels, key)
+		}
+	}
+	common.AddCommonMetadata(request.Instance, updated)
+	common.AddAppLabels(request.Instance, operandName, operandComponent, updated)
+	updated.SetOwnerReferences(nil)
+	err := libhandler.SetOwnerAnnotations(request.Instance, updated)
+	if err != nil {
+		return common.ResourceStatus{}, err
 	}
 
-	return funcs, nil
+	if !reflect.DeepEqual(template.ObjectMeta, updated.ObjectMeta) {
+		err = request.Client.Update(request.Context, updated)
+		if err != nil && !errors.IsNotFound(err) {
+			return common.ResourceStatus{}, err
+		}
+	}
+	return common.ResourceStatus{Resource: updated}, nil
+}
+
+// loadTemplatesBundle returns the templates from the ConfigMap referenced
+// by spec.commonTemplates.bundleRef, or the built-in bundle if it is not set.
+func loadTemplatesBundle(request *common.Request) ([]templatev1.Template, error) {
+	if bundleRef := request.Instance.Spec.CommonTemplates.BundleRef; bundleRef != nil {
+		return loadCustomTemplatesBundle(request, bundleRef)
+	}
+	return loadBuiltInTemplatesBundle(request)
 }
 
-func reconcileTemplatesFuncs(request *common.Request) []common.ReconcileFunc {
-	loadTemplates := func() {
-		var err error
-		filename := filepath.Join(BundleDir, "common-templates-"+Version+".yaml")
-		templatesBundle, err = ReadTemplates(filename)
+func loadCustomTemplatesBundle(request *common.Request, bundleRef *ssp.TemplatesBundleReference) ([]templatev1.Template, error) {
+	configMap := &core.ConfigMap{}
+	err := request.Client.Get(request.Context, client.ObjectKey{
+		Namespace: request.Instance.Namespace,
+		Name:      bundleRef.ConfigMapName,
+	}, configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates bundle ConfigMap %s: %w", bundleRef.ConfigMapName, err)
+	}
+
+	// Sort keys, so the templates are always reconciled in the same order
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var bundle []templatev1.Template
+	for _, key := range keys {
+		templates, err := ParseTemplates([]byte(configMap.Data[key]))
 		if err != nil {
-			request.Logger.Error(err, fmt.Sprintf("Error reading from template bundle, %v", err))
-			panic(err)
-		}
-		if len(templatesBundle) == 0 {
-			panic("No templates could be found in the installed bundle")
-		}
-	}
-	// Only load templates Once
-	loadTemplatesOnce.Do(loadTemplates)
-
-	namespace := request.Instance.Spec.CommonTemplates.Namespace
-	funcs := make([]common.ReconcileFunc, 0, len(templatesBundle))
-	for i := range templatesBundle {
-		template := &templatesBundle[i]
-		template.ObjectMeta.Namespace = namespace
-		funcs = append(funcs, func(request *common.Request) (common.ResourceStatus, error) {
-			return common.CreateOrUpdate(request).
-				ClusterResource(template).
-				WithAppLabels(operandName, operandComponent).
-				UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-					newTemplate := newRes.(*templatev1.Template)
-					foundTemplate := foundRes.(*templatev1.Template)
-					foundTemplate.Objects = newTemplate.Objects
-					foundTemplate.Parameters = newTemplate.Parameters
-				}).
-				Reconcile()
-		})
+			return nil, fmt.Errorf("failed to parse key %s of templates bundle ConfigMap %s: %w", key, bundleRef.ConfigMapName, err)
+		}
+		bundle = append(bundle, templates...)
+	}
+	return bundle, nil
+}
+
+// loadBuiltInTemplatesBundle reads the templates bundle of the requested version
+// shipped with the operator, each version is only read once
+func loadBuiltInTemplatesBundle(request *common.Request) ([]templatev1.Template, error) {
+	version := templatesVersion(request)
+
+	loadedBundlesLock.Lock()
+	defer loadedBundlesLock.Unlock()
+	if bundle, ok := loadedBundles[version]; ok {
+		return bundle, nil
+	}
+
+	filename := bundleFilename(version)
+	bundle, err := ReadTemplates(filename)
+	if err != nil {
+		request.Logger.Error(err, fmt.Sprintf("Error reading from template bundle, %v", err))
+		return nil, fmt.Errorf("failed to read templates bundle version %s: %w", version, err)
+	}
+	if len(bundle) == 0 {
+		return nil, fmt.Errorf("no templates could be found in the installed bundle version %s", version)
+	}
+	loadedBundles[version] = bundle
+	return bundle, nil
+}
+
+func bundleFilename(version string) string {
+	return filepath.Join(BundleDir, "common-templates-"+version+".yaml")
+}
+
+// HasBuiltInBundle returns true if the templates bundle of the version is shipped with the operator
+func HasBuiltInBundle(version string) bool {
+	_, err := os.Stat(bundleFilename(version))
+	return err == nil
+}
+
+// templatesVersion returns the version of the bundled templates to deploy
+func templatesVersion(request *common.Request) string {
+	if version := request.Instance.Spec.CommonTemplates.Version; version != "" {
+		return version
+	}
+	return Version
+}
+
+func reconcileTemplatesFuncs(request *common.Request, exclusion *templateExclusion) ([]common.ReconcileFunc, error) {
+	bundle, err := loadTemplatesBundle(request)
+	if err != nil {
+		return nil, err
+	}
+	// Content of a custom bundle can change without a change of the SSP CR,
+	// so the templates have to be compared with the bundle on every reconciliation.
+	isCustomBundle := request.Instance.Spec.CommonTemplates.BundleRef != nil
+
+	namespaces := templateNamespaces(request)
+	funcs := make([]common.ReconcileFunc, 0, len(namespaces)*len(bundle))
+	for _, namespace := range namespaces {
+		for i := range bundle {
+			if exclusion.excludes(&bundle[i]) {
+				continue
+			}
+			template := templateInNamespace(&bundle[i], namespace)
+			setBootSourceNamespace(template, bootSourceNamespace(request))
+			setProviderAnnotations(template, request.Instance.Spec.CommonTemplates.Provider)
+			if isCustomBundle {
+				request.VersionCache.RemoveObj(template)
+			}
+			funcs = append(funcs, func(request *common.Request) (common.ResourceStatus, error) {
+				return common.CreateOrUpdate(request).
+					ClusterResource(template).
+					WithAppLabels(operandName, operandComponent).
+					Reconcile()
+			})
+		}
+	}
+	return funcs, nil
+}
+
+// removeTemplatesFromStaleNamespaces deletes templates owned by the SSP CR
+// from namespaces that are no longer listed in spec.commonTemplates.
+func removeTemplatesFromStaleNamespaces(request *common.Request) error {
+	managedTemplates := &templatev1.TemplateList{}
+	err := request.Client.List(request.Context, managedTemplates, client.MatchingLabels{
+		common.AppKubernetesNameLabel:      operandName,
+		common.AppKubernetesManagedByLabel: "ssp-operator",
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	targetNamespaces := map[string]struct{}{}
+	for _, namespace := range templateNamespaces(request) {
+		targetNamespaces[namespace] = struct{}{}
+	}
+
+	owner := request.Instance.Namespace + "/" + request.Instance.Name
+	for i := range managedTemplates.Items {
+		template := &managedTemplates.Items[i]
+		if _, ok := targetNamespaces[template.Namespace]; ok {
+			continue
+		}
+		if template.GetAnnotations()[libhandler.NamespacedNameAnnotation] != owner {
+			continue
+		}
+		err = request.Client.Delete(request.Context, template)
+		if err != nil && !errors.IsNotFound(err) {
+			request.Logger.Error(err, fmt.Sprintf("Error deleting template \"%s/%s\": %s", template.Namespace, template.Name, err))
+			return err
+		}
+	}
+	return nil
+}
+
+// removeStaleTemplates deletes templates owned by the SSP CR, that have a version
+// of the current templates bundle, but are no longer part of the bundle.
+// Templates of other versions are handled as older templates.
+func removeStaleTemplates(request *common.Request) error {
+	bundle, err := loadTemplatesBundle(request)
+	if err != nil {
+		return err
+	}
+	bundleNames := map[string]struct{}{}
+	bundleVersions := map[string]struct{}{}
+	for i := range bundle {
+		bundleNames[bundle[i].Name] = struct{}{}
+		bundleVersions[bundle[i].Labels[templateVersionLabel]] = struct{}{}
+	}
+
+	owner := request.Instance.Namespace + "/" + request.Instance.Name
+	for _, namespace := range templateNamespaces(request) {
+		managedTemplates := &templatev1.TemplateList{}
+		err := request.Client.List(request.Context, managedTemplates,
+			client.InNamespace(namespace),
+			client.MatchingLabels{
+				common.AppKubernetesNameLabel:      operandName,
+				common.AppKubernetesManagedByLabel: "ssp-operator",
+			})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		for i := range managedTemplates.Items {
+			template := &managedTemplates.Items[i]
+			if _, ok := bundleNames[template.Name]; ok {
+				continue
+			}
+			if _, ok := bundleVersions[template.Labels[templateVersionLabel]]; !ok {
+				continue
+			}
+			if template.GetAnnotations()[libhandler.NamespacedNameAnnotation] != owner {
+				continue
+			}
+			err = request.Client.Delete(request.Context, template)
+			if err != nil && !errors.IsNotFound(err) {
+				request.Logger.Error(err, fmt.Sprintf("Error deleting stale template \"%s/%s\": %s", namespace, template.Name, err))
+				return err
+			}
+			request.VersionCache.RemoveObj(template)
+		}
+	}
+	return nil
+}
+
+// templateNamespaces returns all namespaces where the templates should be deployed
+func templateNamespaces(request *common.Request) []string {
+	spec := request.Instance.Spec.CommonTemplates
+	namespaces := []string{spec.Namespace}
+	for _, namespace := range spec.AdditionalNamespaces {
+		if !containsString(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// setBootSourceNamespace changes the default namespace of the template boot source
+func setBootSourceNamespace(template *templatev1.Template, namespace string) {
+	for i := range template.Parameters {
+		if template.Parameters[i].Name == sourcePVCNamespaceParameter {
+			template.Parameters[i].Value = namespace
+		}
+	}
+}
+
+// setProviderAnnotations overrides the provider annotations of the template
+// with the values set in spec.commonTemplates.provider
+func setProviderAnnotations(template *templatev1.Template, provider *ssp.TemplatesProvider) {
+	if provider == nil {
+		return
+	}
+	for key, value := range map[string]string{
+		providerAnnotation:             provider.Name,
+		providerURLAnnotation:          provider.URL,
+		providerSupportLevelAnnotation: provider.SupportLevel,
+	} {
+		if value == "" {
+			continue
+		}
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[key] = value
+	}
+}
+
+func templateInNamespace(template *templatev1.Template, namespace string) *templatev1.Template {
+	copied := template.DeepCopy()
+	copied.ObjectMeta.Namespace = namespace
+	return copied
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
 	}
-	return funcs
+	return false
 }
@@ -10,11 +10,17 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	templatev1 "github.com/openshift/api/template/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	. "kubevirt.io/ssp-operator/internal/test-utils"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -41,12 +47,20 @@ func TestTemplates(t *testing.T) {
 
 var _ = Describe("Common-Templates operand", func() {
 
-	var request common.Request
+	var (
+		request         common.Request
+		templatesBundle []templatev1.Template
+	)
 
 	BeforeEach(func() {
 		s := scheme.Scheme
 		Expect(ssp.AddToScheme(s)).ToNot(HaveOccurred())
 		Expect(operand.AddWatchTypesToScheme(s)).ToNot(HaveOccurred())
+		// The fake client needs to know CDI kinds, even for unstructured objects
+		s.AddKnownTypeWithName(DataImportCronGVK, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(DataImportCronGVK.GroupVersion().WithKind(DataImportCronGVK.Kind+"List"), &unstructured.UnstructuredList{})
+		s.AddKnownTypeWithName(CDIGVK, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(CDIGVK.GroupVersion().WithKind(CDIGVK.Kind+"List"), &unstructured.UnstructuredList{})
 
 		client := fake.NewFakeClientWithScheme(s)
 		request = common.Request{
@@ -75,8 +89,12 @@ var _ = Describe("Common-Templates operand", func() {
 				},
 			},
 			Logger:       log,
-			VersionCache: common.VersionCache{},
+			VersionCache: common.NewVersionCache(),
 		}
+
+		var err error
+		templatesBundle, err = loadBuiltInTemplatesBundle(&request)
+		Expect(err).ToNot(HaveOccurred())
 	})
 
 	It("should create golden-images namespace", func() {
@@ -93,6 +111,16 @@ var _ = Describe("Common-Templates operand", func() {
 			ExpectResourceExists(&template, request)
 		}
 	})
+	It("should create common-template resources concurrently", func() {
+		request.MaxConcurrentResources = 8
+		statuses, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statuses).To(HaveLen(4 + len(templatesBundle)))
+		for _, template := range templatesBundle {
+			template.Namespace = namespace
+			ExpectResourceExists(&template, request)
+		}
+	})
 	It("should create view role", func() {
 		_, err := operand.Reconcile(&request)
 		Expect(err).ToNot(HaveOccurred())
@@ -109,6 +137,755 @@ var _ = Describe("Common-Templates operand", func() {
 		ExpectResourceExists(newEditRole(), request)
 	})
 
+	Context("boot source namespace", func() {
+		const bootSourceNS = "custom-os-images"
+
+		It("should create boot source resources in configured namespace", func() {
+			request.Instance.Spec.CommonTemplates.BootSourceNamespace = bootSourceNS
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceExists(newGoldenImagesNS(bootSourceNS), request)
+			ExpectResourceExists(newViewRole(bootSourceNS), request)
+			ExpectResourceExists(newViewRoleBinding(bootSourceNS), request)
+			ExpectResourceNotExists(newGoldenImagesNS(GoldenImagesNSname), request)
+		})
+
+		It("should set boot source namespace in templates", func() {
+			request.Instance.Spec.CommonTemplates.BootSourceNamespace = bootSourceNS
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			template := &templatev1.Template{}
+			key := client.ObjectKey{Namespace: namespace, Name: templatesBundle[0].Name}
+			Expect(request.Client.Get(request.Context, key, template)).ToNot(HaveOccurred())
+			for _, parameter := range template.Parameters {
+				if parameter.Name == sourcePVCNamespaceParameter {
+					Expect(parameter.Value).To(Equal(bootSourceNS))
+				}
+			}
+		})
+
+		It("should remove roles from previous boot source namespace", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceExists(newViewRole(GoldenImagesNSname), request)
+
+			request.Instance.Spec.CommonTemplates.BootSourceNamespace = bootSourceNS
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceNotExists(newViewRole(GoldenImagesNSname), request)
+			ExpectResourceNotExists(newViewRoleBinding(GoldenImagesNSname), request)
+			ExpectResourceExists(newViewRole(bootSourceNS), request)
+			ExpectResourceExists(newViewRoleBinding(bootSourceNS), request)
+			// Already imported boot sources are kept in the previous namespace
+			ExpectResourceExists(newGoldenImagesNS(GoldenImagesNSname), request)
+		})
+	})
+
+	Context("additional namespaces", func() {
+		const additionalNamespace = "additional-templates-ns"
+
+		BeforeEach(func() {
+			request.Instance.Spec.CommonTemplates.AdditionalNamespaces = []string{additionalNamespace}
+		})
+
+		It("should create common-template resources in all namespaces", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			for _, ns := range []string{namespace, additionalNamespace} {
+				for _, template := range templatesBundle {
+					template.Namespace = ns
+					ExpectResourceExists(&template, request)
+				}
+			}
+		})
+
+		It("should remove templates from namespace removed from the list", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Instance.Spec.CommonTemplates.AdditionalNamespaces = nil
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				template.Namespace = namespace
+				ExpectResourceExists(&template, request)
+
+				template.Namespace = additionalNamespace
+				ExpectResourceNotExists(&template, request)
+			}
+		})
+
+		It("should move templates when the namespace is changed", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Instance.Spec.CommonTemplates.Namespace = "new-templates-ns"
+			request.Instance.Spec.CommonTemplates.AdditionalNamespaces = nil
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				template.Namespace = "new-templates-ns"
+				ExpectResourceExists(&template, request)
+
+				template.Namespace = namespace
+				ExpectResourceNotExists(&template, request)
+			}
+		})
+
+		It("should not remove templates owned by a different SSP", func() {
+			otherTemplate := &templatev1.Template{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-template",
+					Namespace: "other-ns",
+					Labels: map[string]string{
+						common.AppKubernetesNameLabel:      operandName,
+						common.AppKubernetesManagedByLabel: "ssp-operator",
+					},
+					Annotations: map[string]string{
+						libhandler.NamespacedNameAnnotation: "other-ns/other-ssp",
+					},
+				},
+			}
+			Expect(request.Client.Create(request.Context, otherTemplate)).ToNot(HaveOccurred())
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceExists(otherTemplate, request)
+		})
+
+		It("should remove templates from all namespaces on cleanup", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(operand.Cleanup(&request)).ToNot(HaveOccurred())
+			for _, ns := range []string{namespace, additionalNamespace} {
+				for _, template := range templatesBundle {
+					template.Namespace = ns
+					ExpectResourceNotExists(&template, request)
+				}
+			}
+		})
+	})
+
+	Context("pinned templates version", func() {
+		const pinnedVersion = "v0.12.2"
+
+		BeforeEach(func() {
+			request.Instance.Spec.CommonTemplates.Version = pinnedVersion
+		})
+
+		It("should create templates of the pinned version", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			pinnedBundle, err := loadBuiltInTemplatesBundle(&request)
+			Expect(err).ToNot(HaveOccurred())
+			for _, template := range pinnedBundle {
+				Expect(template.Labels["template.kubevirt.io/version"]).To(Equal(pinnedVersion))
+				template.Namespace = namespace
+				ExpectResourceExists(&template, request)
+			}
+		})
+
+		It("should fail if the pinned version is not shipped", func() {
+			request.Instance.Spec.CommonTemplates.Version = "v0.0.1"
+			_, err := operand.Reconcile(&request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to read templates bundle version v0.0.1"))
+		})
+	})
+
+	Context("custom templates bundle", func() {
+		const (
+			configMapName = "custom-bundle"
+			customBundle  = `
+apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: custom-template
+  labels:
+    template.kubevirt.io/type: base
+objects: []
+---
+apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: other-custom-template
+objects: []
+`
+		)
+
+		BeforeEach(func() {
+			request.Instance.Spec.CommonTemplates.BundleRef = &ssp.TemplatesBundleReference{
+				ConfigMapName: configMapName,
+			}
+		})
+
+		It("should fail if the bundle ConfigMap does not exist", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should create templates from the bundle ConfigMap", func() {
+			Expect(request.Client.Create(request.Context, &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      configMapName,
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					"templates.yaml": customBundle,
+				},
+			})).ToNot(HaveOccurred())
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, name := range []string{"custom-template", "other-custom-template"} {
+				ExpectResourceExists(&templatev1.Template{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+					},
+				}, request)
+			}
+			for _, template := range templatesBundle {
+				template.Namespace = namespace
+				ExpectResourceNotExists(&template, request)
+			}
+		})
+
+		It("should not list templates of the bundle as older templates", func() {
+			Expect(request.Client.Create(request.Context, &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      configMapName,
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					"templates.yaml": customBundle,
+				},
+			})).ToNot(HaveOccurred())
+
+			bundle, err := loadTemplatesBundle(&request)
+			Expect(err).ToNot(HaveOccurred())
+			for i := range bundle {
+				template := templateInNamespace(&bundle[i], namespace)
+				template.Labels = map[string]string{
+					templateVersionLabel:        "v0.1.0",
+					"template.kubevirt.io/type": "base",
+				}
+				Expect(request.Client.Create(request.Context, template)).To(Succeed())
+			}
+
+			olderTemplates, err := listOlderTemplates(&request, bundle)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(olderTemplates).To(BeEmpty())
+		})
+	})
+
+	Context("workload architectures", func() {
+		It("should only create templates for workload architectures", func() {
+			const armBundle = `
+apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: amd64-template
+objects: []
+---
+apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: arm64-template
+  annotations:
+    template.kubevirt.io/architecture: arm64
+objects: []
+`
+			Expect(request.Client.Create(request.Context, &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "arm-bundle",
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					"templates.yaml": armBundle,
+				},
+			})).ToNot(HaveOccurred())
+			request.Instance.Spec.CommonTemplates.BundleRef = &ssp.TemplatesBundleReference{
+				ConfigMapName: "arm-bundle",
+			}
+			request.Instance.Spec.Cluster = &ssp.Cluster{
+				WorkloadArchitectures: []ssp.Architecture{ssp.ArchitectureARM64},
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceExists(&templatev1.Template{
+				ObjectMeta: metav1.ObjectMeta{Name: "arm64-template", Namespace: namespace},
+			}, request)
+			ExpectResourceNotExists(&templatev1.Template{
+				ObjectMeta: metav1.ObjectMeta{Name: "amd64-template", Namespace: namespace},
+			}, request)
+		})
+
+		It("should create all templates if workload architectures are not set", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				template.Namespace = namespace
+				ExpectResourceExists(&template, request)
+			}
+		})
+
+		It("should use default architecture from KubeVirt for templates without architecture", func() {
+			request.Instance.Spec.Cluster = &ssp.Cluster{
+				WorkloadArchitectures: []ssp.Architecture{ssp.ArchitectureARM64},
+			}
+			request.KubeVirt = &common.KubeVirtConfig{DefaultArchitecture: string(ssp.ArchitectureARM64)}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				template.Namespace = namespace
+				ExpectResourceExists(&template, request)
+			}
+		})
+	})
+
+	Context("KubeVirt feature gates", func() {
+		const featureGatesBundle = `
+apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: plain-template
+objects: []
+---
+apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: feature-gate-template
+  annotations:
+    template.kubevirt.io/required-feature-gates: "Sidecar, Snapshot"
+objects: []
+`
+		featureGateTemplate := &templatev1.Template{
+			ObjectMeta: metav1.ObjectMeta{Name: "feature-gate-template", Namespace: namespace},
+		}
+
+		BeforeEach(func() {
+			Expect(request.Client.Create(request.Context, &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "feature-gates-bundle",
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					"templates.yaml": featureGatesBundle,
+				},
+			})).ToNot(HaveOccurred())
+			request.Instance.Spec.CommonTemplates.BundleRef = &ssp.TemplatesBundleReference{
+				ConfigMapName: "feature-gates-bundle",
+			}
+		})
+
+		It("should create templates if KubeVirt is not installed", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceExists(featureGateTemplate, request)
+		})
+
+		It("should create templates if required feature gates are enabled", func() {
+			request.KubeVirt = &common.KubeVirtConfig{FeatureGates: []string{"Snapshot", "Sidecar"}}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceExists(featureGateTemplate, request)
+		})
+
+		It("should remove templates when a required feature gate is disabled", func() {
+			request.KubeVirt = &common.KubeVirtConfig{FeatureGates: []string{"Snapshot", "Sidecar"}}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceExists(featureGateTemplate, request)
+
+			request.KubeVirt = &common.KubeVirtConfig{FeatureGates: []string{"Snapshot"}}
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceNotExists(featureGateTemplate, request)
+			ExpectResourceExists(&templatev1.Template{
+				ObjectMeta: metav1.ObjectMeta{Name: "plain-template", Namespace: namespace},
+			}, request)
+		})
+	})
+
+	Context("excluded templates", func() {
+		It("should not create templates excluded by name", func() {
+			excluded := templatesBundle[0].DeepCopy()
+			request.Instance.Spec.CommonTemplates.Exclude = &ssp.TemplatesExclusion{
+				Names: []string{excluded.Name},
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			excluded.Namespace = namespace
+			ExpectResourceNotExists(excluded, request)
+			for _, template := range templatesBundle[1:] {
+				template.Namespace = namespace
+				ExpectResourceExists(&template, request)
+			}
+		})
+
+		It("should remove templates excluded by label selector", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			const osLabel = "os.template.kubevirt.io/"
+			var excludedLabel string
+			for key := range templatesBundle[0].Labels {
+				if strings.HasPrefix(key, osLabel) {
+					excludedLabel = key
+					break
+				}
+			}
+			Expect(excludedLabel).ToNot(BeEmpty())
+
+			request.Instance.Spec.CommonTemplates.Exclude = &ssp.TemplatesExclusion{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{excludedLabel: "true"},
+				},
+			}
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				template.Namespace = namespace
+				if template.Labels[excludedLabel] == "true" {
+					ExpectResourceNotExists(&template, request)
+				} else {
+					ExpectResourceExists(&template, request)
+				}
+			}
+		})
+
+		It("should not remove excluded templates owned by another SSP CR", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			otherTemplate := templatesBundle[0].DeepCopy()
+			otherTemplate.Namespace = namespace
+			ExpectResourceExists(otherTemplate, request)
+			otherTemplate.Annotations[libhandler.NamespacedNameAnnotation] = "other-namespace/other-ssp"
+			Expect(request.Client.Update(request.Context, otherTemplate)).To(Succeed())
+
+			request.Instance.Spec.CommonTemplates.Exclude = &ssp.TemplatesExclusion{
+				Names: []string{otherTemplate.Name},
+			}
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceExists(otherTemplate, request)
+		})
+	})
+
+	Context("DataImportCron templates", func() {
+		var cronTemplate ssp.DataImportCronTemplate
+
+		BeforeEach(func() {
+			cronTemplate = ssp.DataImportCronTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-cron",
+				},
+				Spec: runtime.RawExtension{
+					Raw: []byte(`{"schedule":"0 */12 * * *","managedDataSource":"test-source"}`),
+				},
+			}
+			request.Instance.Spec.CommonTemplates.DataImportCronTemplates = []ssp.DataImportCronTemplate{cronTemplate}
+		})
+
+		getDataImportCron := func(namespace, name string) (*unstructured.Unstructured, error) {
+			cron := &unstructured.Unstructured{}
+			cron.SetGroupVersionKind(DataImportCronGVK)
+			err := request.Client.Get(request.Context, client.ObjectKey{Namespace: namespace, Name: name}, cron)
+			return cron, err
+		}
+
+		It("should create DataImportCron in golden images namespace", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			cron, err := getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(err).ToNot(HaveOccurred())
+			schedule, _, err := unstructured.NestedString(cron.Object, "spec", "schedule")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule).To(Equal("0 */12 * * *"))
+			Expect(cron.GetLabels()[common.AppKubernetesNameLabel]).To(Equal(operandName))
+		})
+
+		It("should create DataImportCron in configured namespace", func() {
+			request.Instance.Spec.CommonTemplates.DataImportCronTemplates[0].Namespace = namespace
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getDataImportCron(namespace, cronTemplate.Name)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should remove DataImportCron when its template is removed", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Instance.Spec.CommonTemplates.DataImportCronTemplates = nil
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report DataImportCrons that are importing", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			importing, err := ssp.ImportingDataImportCrons(request.Context, request.Client, request.Instance)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(importing).To(BeEmpty())
+
+			cron, err := getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unstructured.SetNestedSlice(cron.Object, []interface{}{
+				map[string]interface{}{"type": "UpToDate", "status": "False"},
+				map[string]interface{}{"type": "Progressing", "status": "True"},
+			}, "status", "conditions")).To(Succeed())
+			Expect(request.Client.Update(request.Context, cron)).To(Succeed())
+
+			importing, err = ssp.ImportingDataImportCrons(request.Context, request.Client, request.Instance)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(importing).To(ConsistOf(GoldenImagesNSname + "/" + cronTemplate.Name))
+		})
+
+		It("should not create DataImportCron when boot image import is disabled", func() {
+			request.Instance.Spec.CommonTemplates.EnableCommonBootImageImport = pointer.BoolPtr(false)
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should remove DataImportCron when boot image import is disabled", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Instance.Spec.CommonTemplates.EnableCommonBootImageImport = pointer.BoolPtr(false)
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should only create DataImportCrons supporting workload architectures", func() {
+			s390xCron := cronTemplate
+			s390xCron.Name = "s390x-cron"
+			s390xCron.Annotations = map[string]string{ssp.DataImportCronArchitecturesAnnotation: "s390x"}
+			multiArchCron := cronTemplate
+			multiArchCron.Name = "multi-arch-cron"
+			multiArchCron.Annotations = map[string]string{ssp.DataImportCronArchitecturesAnnotation: "amd64, arm64"}
+			request.Instance.Spec.CommonTemplates.DataImportCronTemplates = []ssp.DataImportCronTemplate{
+				cronTemplate, s390xCron, multiArchCron,
+			}
+			request.Instance.Spec.Cluster = &ssp.Cluster{
+				WorkloadArchitectures: []ssp.Architecture{ssp.ArchitectureARM64},
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = getDataImportCron(GoldenImagesNSname, multiArchCron.Name)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = getDataImportCron(GoldenImagesNSname, s390xCron.Name)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should move DataImportCron to new boot source namespace", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			request.Instance.Spec.CommonTemplates.BootSourceNamespace = "custom-os-images"
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			_, err = getDataImportCron("custom-os-images", cronTemplate.Name)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("boot source storage", func() {
+			BeforeEach(func() {
+				storageClass := "local-ssd"
+				volumeMode := core.PersistentVolumeBlock
+				size := resource.MustParse("30Gi")
+				request.Instance.Spec.CommonTemplates.BootSourceStorage = &ssp.BootSourceStorage{
+					StorageClassName: &storageClass,
+					VolumeMode:       &volumeMode,
+					Size:             &size,
+				}
+			})
+
+			getStorage := func(section string) map[string]interface{} {
+				cron, err := getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+				Expect(err).ToNot(HaveOccurred())
+				storage, found, err := unstructured.NestedMap(cron.Object, "spec", "template", "spec", section)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				return storage
+			}
+
+			It("should set storage defaults", func() {
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getStorage("storage")).To(Equal(map[string]interface{}{
+					"storageClassName": "local-ssd",
+					"volumeMode":       "Block",
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{
+							"storage": "30Gi",
+						},
+					},
+				}))
+			})
+
+			It("should set access modes", func() {
+				request.Instance.Spec.CommonTemplates.BootSourceStorage.AccessModes = []core.PersistentVolumeAccessMode{
+					core.ReadWriteOnce,
+				}
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getStorage("storage")).To(HaveKeyWithValue("accessModes", []interface{}{"ReadWriteOnce"}))
+			})
+
+			It("should not override access modes of the template", func() {
+				request.Instance.Spec.CommonTemplates.BootSourceStorage.AccessModes = []core.PersistentVolumeAccessMode{
+					core.ReadWriteOnce,
+				}
+				request.Instance.Spec.CommonTemplates.DataImportCronTemplates[0].Spec.Raw = []byte(
+					`{"template":{"spec":{"storage":{"accessModes":["ReadWriteMany"]}}}}`)
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getStorage("storage")).To(HaveKeyWithValue("accessModes", []interface{}{"ReadWriteMany"}))
+			})
+
+			It("should not override storage of the template", func() {
+				request.Instance.Spec.CommonTemplates.DataImportCronTemplates[0].Spec.Raw = []byte(
+					`{"template":{"spec":{"pvc":{"storageClassName":"nfs","resources":{"requests":{"storage":"10Gi"}}}}}}`)
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getStorage("pvc")).To(Equal(map[string]interface{}{
+					"storageClassName": "nfs",
+					"volumeMode":       "Block",
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{
+							"storage": "10Gi",
+						},
+					},
+				}))
+			})
+		})
+
+		Context("import proxy", func() {
+			const cdiName = "cdi"
+
+			getImportProxy := func() (map[string]interface{}, bool) {
+				cdi := &unstructured.Unstructured{}
+				cdi.SetGroupVersionKind(CDIGVK)
+				Expect(request.Client.Get(request.Context, client.ObjectKey{Name: cdiName}, cdi)).To(Succeed())
+				importProxy, found, err := unstructured.NestedMap(cdi.Object, "spec", "config", "importProxy")
+				Expect(err).ToNot(HaveOccurred())
+				return importProxy, found
+			}
+
+			BeforeEach(func() {
+				cdi := &unstructured.Unstructured{}
+				cdi.SetGroupVersionKind(CDIGVK)
+				cdi.SetName(cdiName)
+				Expect(request.Client.Create(request.Context, cdi)).To(Succeed())
+
+				request.Instance.Spec.Proxy = &ssp.Proxy{
+					HTTPProxy:  "http://proxy.example.com:3128",
+					HTTPSProxy: "https://proxy.example.com:3129",
+					NoProxy:    ".cluster.local",
+				}
+			})
+
+			It("should set proxy as CDI import proxy", func() {
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				importProxy, found := getImportProxy()
+				Expect(found).To(BeTrue())
+				Expect(importProxy).To(Equal(map[string]interface{}{
+					"HTTPProxy":  "http://proxy.example.com:3128",
+					"HTTPSProxy": "https://proxy.example.com:3129",
+					"noProxy":    ".cluster.local",
+				}))
+			})
+
+			It("should remove CDI import proxy when proxy is removed", func() {
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				request.Instance.Spec.Proxy = nil
+				_, err = operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, found := getImportProxy()
+				Expect(found).To(BeFalse())
+			})
+
+			It("should not set CDI import proxy when boot image import is disabled", func() {
+				request.Instance.Spec.CommonTemplates.EnableCommonBootImageImport = pointer.BoolPtr(false)
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, found := getImportProxy()
+				Expect(found).To(BeFalse())
+			})
+
+			It("should remove CDI import proxy on cleanup", func() {
+				_, err := operand.Reconcile(&request)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(operand.Cleanup(&request)).To(Succeed())
+				_, found := getImportProxy()
+				Expect(found).To(BeFalse())
+			})
+		})
+
+		It("should remove DataImportCron on cleanup", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(operand.Cleanup(&request)).ToNot(HaveOccurred())
+			_, err = getDataImportCron(GoldenImagesNSname, cronTemplate.Name)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	Context("old templates", func() {
 		var (
 			parentTpl, oldTpl *templatev1.Template
@@ -224,4 +1001,206 @@ var _ = Describe("Common-Templates operand", func() {
 			}
 		})
 	})
+
+	It("should remove templates dropped from the bundle", func() {
+		newDroppedTemplate := func(name string) *templatev1.Template {
+			template := &templatev1.Template{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: request.Instance.Spec.CommonTemplates.Namespace,
+					Labels: map[string]string{
+						templateVersionLabel: templatesBundle[0].Labels[templateVersionLabel],
+					},
+				},
+			}
+			common.AddAppLabels(request.Instance, operandName, operandComponent, template)
+			return template
+		}
+
+		droppedTpl := newDroppedTemplate("test-dropped-tpl")
+		Expect(libhandler.SetOwnerAnnotations(request.Instance, droppedTpl)).To(Succeed())
+		Expect(request.Client.Create(request.Context, droppedTpl)).To(Succeed())
+
+		notOwnedTpl := newDroppedTemplate("test-not-owned-tpl")
+		Expect(request.Client.Create(request.Context, notOwnedTpl)).To(Succeed())
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+		ExpectResourceNotExists(droppedTpl, request)
+		ExpectResourceExists(notOwnedTpl, request)
+	})
+
+	It("should remove older templates on cleanup", func() {
+		oldTpl := &templatev1.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-old-tpl",
+				Namespace: request.Instance.Spec.CommonTemplates.Namespace,
+				Labels: map[string]string{
+					"template.kubevirt.io/version": "not-latest",
+					"template.kubevirt.io/type":    "base",
+				},
+			},
+		}
+		libhandler.SetOwnerAnnotations(request.Instance, oldTpl)
+		Expect(request.Client.Create(request.Context, oldTpl)).ToNot(HaveOccurred())
+
+		Expect(operand.Cleanup(&request)).ToNot(HaveOccurred())
+		ExpectResourceNotExists(oldTpl, request)
+	})
+
+	It("should not remove older templates not owned by the SSP on cleanup", func() {
+		oldTpl := &templatev1.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-old-tpl",
+				Namespace: request.Instance.Spec.CommonTemplates.Namespace,
+				Labels: map[string]string{
+					"template.kubevirt.io/version": "not-latest",
+					"template.kubevirt.io/type":    "base",
+				},
+				Annotations: map[string]string{
+					libhandler.NamespacedNameAnnotation: "other-namespace/other-ssp",
+				},
+			},
+		}
+		Expect(request.Client.Create(request.Context, oldTpl)).ToNot(HaveOccurred())
+
+		Expect(operand.Cleanup(&request)).ToNot(HaveOccurred())
+		ExpectResourceExists(oldTpl, request)
+	})
+
+	Context("templates provider", func() {
+		It("should set provider annotations on templates", func() {
+			request.Instance.Spec.CommonTemplates.Provider = &ssp.TemplatesProvider{
+				Name:         "Example Vendor",
+				SupportLevel: "Full",
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				found := &templatev1.Template{}
+				Expect(request.Client.Get(request.Context, client.ObjectKey{Namespace: namespace, Name: template.Name}, found)).To(Succeed())
+				Expect(found.Annotations).To(HaveKeyWithValue("template.kubevirt.io/provider", "Example Vendor"))
+				Expect(found.Annotations).To(HaveKeyWithValue("template.kubevirt.io/provider-support-level", "Full"))
+				Expect(found.Annotations).ToNot(HaveKey("template.kubevirt.io/provider-url"))
+			}
+		})
+	})
+
+	Context("deprecated templates retention", func() {
+		var deprecatedTemplates []*templatev1.Template
+
+		BeforeEach(func() {
+			deprecatedTemplates = nil
+			for _, version := range []string{"v0.1.0", "v0.3.0", "v0.2.0"} {
+				template := &templatev1.Template{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "deprecated-template-" + version,
+						Namespace: request.Instance.Spec.CommonTemplates.Namespace,
+						Labels: map[string]string{
+							"template.kubevirt.io/version": version,
+							"template.kubevirt.io/type":    "base",
+						},
+					},
+				}
+				libhandler.SetOwnerAnnotations(request.Instance, template)
+				Expect(request.Client.Create(request.Context, template)).To(Succeed())
+				deprecatedTemplates = append(deprecatedTemplates, template)
+			}
+		})
+
+		templateExists := func(template *templatev1.Template) bool {
+			err := request.Client.Get(request.Context, client.ObjectKey{Namespace: template.Namespace, Name: template.Name}, &templatev1.Template{})
+			if errors.IsNotFound(err) {
+				return false
+			}
+			Expect(err).ToNot(HaveOccurred())
+			return true
+		}
+
+		It("should keep deprecated templates by default", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range deprecatedTemplates {
+				Expect(templateExists(template)).To(BeTrue(), template.Name)
+			}
+		})
+
+		It("should delete deprecated templates", func() {
+			request.Instance.Spec.CommonTemplates.DeprecatedTemplatesRetention = &ssp.DeprecatedTemplatesRetention{
+				Policy: ssp.DeprecatedTemplatesRetentionDelete,
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range deprecatedTemplates {
+				Expect(templateExists(template)).To(BeFalse(), template.Name)
+			}
+		})
+
+		It("should keep deprecated templates of the latest versions", func() {
+			request.Instance.Spec.CommonTemplates.DeprecatedTemplatesRetention = &ssp.DeprecatedTemplatesRetention{
+				Policy:   ssp.DeprecatedTemplatesRetentionKeepVersions,
+				Versions: pointer.Int32Ptr(2),
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(templateExists(deprecatedTemplates[0])).To(BeFalse(), deprecatedTemplates[0].Name)
+			Expect(templateExists(deprecatedTemplates[1])).To(BeTrue(), deprecatedTemplates[1].Name)
+			Expect(templateExists(deprecatedTemplates[2])).To(BeTrue(), deprecatedTemplates[2].Name)
+		})
+
+		It("should not delete deprecated templates owned by another SSP", func() {
+			request.Instance.Spec.CommonTemplates.DeprecatedTemplatesRetention = &ssp.DeprecatedTemplatesRetention{
+				Policy: ssp.DeprecatedTemplatesRetentionDelete,
+			}
+			notOwned := deprecatedTemplates[0]
+			Expect(request.Client.Get(request.Context, client.ObjectKey{Namespace: notOwned.Namespace, Name: notOwned.Name}, notOwned)).To(Succeed())
+			notOwned.Annotations[libhandler.NamespacedNameAnnotation] = "other-namespace/other-ssp"
+			Expect(request.Client.Update(request.Context, notOwned)).To(Succeed())
+
+			retained, err := removeDeprecatedTemplates(&request, []templatev1.Template{*notOwned}, templatesBundle)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(retained).To(HaveLen(1))
+			Expect(templateExists(notOwned)).To(BeTrue())
+		})
+
+		It("should not delete deprecated templates with the name of a bundle template", func() {
+			request.Instance.Spec.CommonTemplates.DeprecatedTemplatesRetention = &ssp.DeprecatedTemplatesRetention{
+				Policy: ssp.DeprecatedTemplatesRetentionDelete,
+			}
+			bundleTemplate := templatesBundle[0].DeepCopy()
+			bundleTemplate.Namespace = namespace
+			bundleTemplate.Labels = map[string]string{
+				"template.kubevirt.io/version": "v0.1.0",
+				"template.kubevirt.io/type":    "base",
+			}
+			libhandler.SetOwnerAnnotations(request.Instance, bundleTemplate)
+			Expect(request.Client.Create(request.Context, bundleTemplate)).To(Succeed())
+
+			retained, err := removeDeprecatedTemplates(&request, []templatev1.Template{*bundleTemplate}, templatesBundle)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(retained).To(HaveLen(1))
+			Expect(templateExists(bundleTemplate)).To(BeTrue())
+		})
+
+		It("should not delete templates of the current version", func() {
+			request.Instance.Spec.CommonTemplates.DeprecatedTemplatesRetention = &ssp.DeprecatedTemplatesRetention{
+				Policy: ssp.DeprecatedTemplatesRetentionDelete,
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, template := range templatesBundle {
+				template.Namespace = namespace
+				ExpectResourceExists(&template, request)
+			}
+		})
+	})
 })
@@ -10,27 +10,40 @@ import (
 	rbac "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
 )
 
 const (
-	GoldenImagesNSname  = "kubevirt-os-images"
+	GoldenImagesNSname  = ssp.DefaultBootSourceNamespace
 	BundleDir           = "data/common-templates-bundle/"
 	ViewRoleName        = "os-images.kubevirt.io:view"
 	EditClusterRoleName = "os-images.kubevirt.io:edit"
 	Version             = "v0.13.1"
+
+	sourcePVCNamespaceParameter = "SRC_PVC_NAMESPACE"
+
+	providerAnnotation             = "template.kubevirt.io/provider"
+	providerURLAnnotation          = "template.kubevirt.io/provider-url"
+	providerSupportLevelAnnotation = "template.kubevirt.io/provider-support-level"
 )
 
 // ReadTemplates from the combined yaml file and return the list of its templates
 func ReadTemplates(filename string) ([]templatev1.Template, error) {
-	var bundle []templatev1.Template
 	file, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(file), 1024)
+	return ParseTemplates(file)
+}
+
+// ParseTemplates from the combined yaml data and return the list of its templates
+func ParseTemplates(data []byte) ([]templatev1.Template, error) {
+	var bundle []templatev1.Template
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 1024)
 	for {
 		template := templatev1.Template{}
-		err = decoder.Decode(&template)
+		err := decoder.Decode(&template)
 		if err == io.EOF {
 			return bundle, nil
 		}
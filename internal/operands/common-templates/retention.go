@@ -0,0 +1,96 @@
+package common_templates
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver"
+	templatev1 "github.com/openshift/api/template/v1"
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+const templateVersionLabel = "template.kubevirt.io/version"
+
+// removeDeprecatedTemplates deletes the templates of previous bundle versions that are not
+// retained by spec.commonTemplates.deprecatedTemplatesRetention, and returns the retained templates.
+// Templates without a version label, templates not owned by the SSP CR
+// and templates with the name of a template in the active bundle are always retained.
+func removeDeprecatedTemplates(request *common.Request, templates []templatev1.Template, bundle []templatev1.Template) ([]templatev1.Template, error) {
+	retention := request.Instance.Spec.CommonTemplates.DeprecatedTemplatesRetention
+	policy := retention.GetPolicy()
+	if policy == ssp.DeprecatedTemplatesRetentionKeep {
+		return templates, nil
+	}
+
+	retainedVersions := map[string]struct{}{"": {}}
+	if policy == ssp.DeprecatedTemplatesRetentionKeepVersions {
+		for _, version := range latestTemplateVersions(templates, int(retention.GetVersions())) {
+			retainedVersions[version] = struct{}{}
+		}
+	}
+
+	bundleNames := map[string]struct{}{}
+	for i := range bundle {
+		bundleNames[bundle[i].Name] = struct{}{}
+	}
+	owner := request.Instance.Namespace + "/" + request.Instance.Name
+
+	var retained []templatev1.Template
+	for i := range templates {
+		template := &templates[i]
+		_, versionRetained := retainedVersions[template.Labels[templateVersionLabel]]
+		_, inBundle := bundleNames[template.Name]
+		owned := template.Annotations[libhandler.NamespacedNameAnnotation] == owner
+		if versionRetained || inBundle || !owned {
+			retained = append(retained, *template)
+			continue
+		}
+		err := request.Client.Delete(request.Context, template)
+		if err != nil && !errors.IsNotFound(err) {
+			request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s/%s\": %s", template.Namespace, template.Name, err))
+			return nil, err
+		}
+		request.VersionCache.RemoveObj(template)
+	}
+	return retained, nil
+}
+
+// latestTemplateVersions returns up to count latest versions of the templates.
+// Versions that cannot be parsed are considered older than all others.
+func latestTemplateVersions(templates []templatev1.Template, count int) []string {
+	uniqueVersions := map[string]struct{}{}
+	for i := range templates {
+		if version := templates[i].Labels[templateVersionLabel]; version != "" {
+			uniqueVersions[version] = struct{}{}
+		}
+	}
+	versions := make([]string, 0, len(uniqueVersions))
+	for version := range uniqueVersions {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return isNewerVersion(versions[i], versions[j])
+	})
+	if len(versions) > count {
+		versions = versions[:count]
+	}
+	return versions
+}
+
+func isNewerVersion(version, other string) bool {
+	parsed, err := semver.ParseTolerant(version)
+	parsedOther, errOther := semver.ParseTolerant(other)
+	switch {
+	case err != nil && errOther != nil:
+		return version > other
+	case err != nil:
+		return false
+	case errOther != nil:
+		return true
+	}
+	return parsed.GT(parsedOther)
+}
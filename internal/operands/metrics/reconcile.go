@@ -3,7 +3,7 @@ package metrics
 import (
 	promv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"kubevirt.io/ssp-operator/internal/common"
 	"kubevirt.io/ssp-operator/internal/operands"
@@ -30,6 +30,23 @@ func (m *metrics) WatchClusterTypes() []runtime.Object {
 	return nil
 }
 
+func (m *metrics) RequiredAPIs() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "prometheusrules",
+	}}
+}
+
+func (m *metrics) Enabled(*common.Request) bool {
+	return true
+}
+
+// Version is empty, the alerting rules are versioned with the operator
+func (m *metrics) Version(*common.Request) string {
+	return ""
+}
+
 func (m *metrics) Reconcile(request *common.Request) ([]common.ResourceStatus, error) {
 	return common.CollectResourceStatus(request,
 		reconcilePrometheusRule,
@@ -55,8 +72,5 @@ func reconcilePrometheusRule(request *common.Request) (common.ResourceStatus, er
 	return common.CreateOrUpdate(request).
 		NamespacedResource(newPrometheusRule(request.Namespace)).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRes.(*promv1.PrometheusRule).Spec = newRes.(*promv1.PrometheusRule).Spec
-		}).
 		Reconcile()
 }
@@ -10,7 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	. "kubevirt.io/ssp-operator/internal/test-utils"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -58,7 +58,7 @@ var _ = Describe("Metrics operand", func() {
 				},
 			},
 			Logger:       log,
-			VersionCache: common.VersionCache{},
+			VersionCache: common.NewVersionCache(),
 		}
 
 		_, err := operand.Reconcile(&request)
@@ -10,6 +10,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -55,6 +57,24 @@ func (nl *nodeLabeller) WatchClusterTypes() []runtime.Object {
 	}
 }
 
+func (nl *nodeLabeller) RequiredAPIs() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{{
+		Group:    "security.openshift.io",
+		Version:  "v1",
+		Resource: "securitycontextconstraints",
+	}}
+}
+
+func (nl *nodeLabeller) Enabled(request *common.Request) bool {
+	return pointer.BoolPtrDerefOr(request.Instance.Spec.NodeLabeller.Enabled, true)
+}
+
+// Version returns the image ID of the node-labeller container of the running pods.
+// The configured node-labeller image is returned if the running image is not known.
+func (nl *nodeLabeller) Version(request *common.Request) string {
+	return common.RunningImageID(request, podLabels(), kubevirtNodeLabeller, getNodeLabellerImages().nodeLabeller)
+}
+
 func (nl *nodeLabeller) Reconcile(request *common.Request) ([]common.ResourceStatus, error) {
 	return common.CollectResourceStatus(request,
 		reconcileClusterRole,
@@ -68,15 +88,19 @@ func (nl *nodeLabeller) Reconcile(request *common.Request) ([]common.ResourceSta
 
 func (nl *nodeLabeller) Cleanup(request *common.Request) error {
 	for _, obj := range []controllerutil.Object{
-		newClusterRole(),
-		newClusterRoleBinding(request.Namespace),
+		newDaemonSet(request.Namespace),
+		newConfigMap(request.Namespace),
 		newSecurityContextConstraint(request.Namespace),
+		newClusterRoleBinding(request.Namespace),
+		newServiceAccount(request.Namespace),
+		newClusterRole(),
 	} {
 		err := request.Client.Delete(request.Context, obj)
 		if err != nil && !errors.IsNotFound(err) {
 			request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s\": %s", obj.GetName(), err))
 			return err
 		}
+		request.VersionCache.RemoveObj(obj)
 	}
 	return nil
 }
@@ -96,9 +120,6 @@ func reconcileClusterRole(request *common.Request) (common.ResourceStatus, error
 	return common.CreateOrUpdate(request).
 		ClusterResource(newClusterRole()).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRes.(*rbac.ClusterRole).Rules = newRes.(*rbac.ClusterRole).Rules
-		}).
 		Reconcile()
 }
 
@@ -113,12 +134,6 @@ func reconcileClusterRoleBinding(request *common.Request) (common.ResourceStatus
 	return common.CreateOrUpdate(request).
 		ClusterResource(newClusterRoleBinding(request.Namespace)).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			newBinding := newRes.(*rbac.ClusterRoleBinding)
-			foundBinding := foundRes.(*rbac.ClusterRoleBinding)
-			foundBinding.RoleRef = newBinding.RoleRef
-			foundBinding.Subjects = newBinding.Subjects
-		}).
 		Reconcile()
 }
 
@@ -126,16 +141,20 @@ func reconcileConfigMap(request *common.Request) (common.ResourceStatus, error)
 	return common.CreateOrUpdate(request).
 		NamespacedResource(newConfigMap(request.Namespace)).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRes.(*v1.ConfigMap).Data = newRes.(*v1.ConfigMap).Data
-		}).
 		Reconcile()
 }
 
 func reconcileDaemonSet(request *common.Request) (common.ResourceStatus, error) {
-	nodeLabellerSpec := request.Instance.Spec.NodeLabeller
 	daemonSet := newDaemonSet(request.Namespace)
-	addPlacementFields(daemonSet, nodeLabellerSpec.Placement)
+	common.AddPlacementFields(&daemonSet.Spec.Template.Spec, getPlacement(request))
+	common.AddResourceRequirements(&daemonSet.Spec.Template.Spec, request.Instance.Spec.NodeLabeller.Resources)
+	common.AddProxyEnv(&daemonSet.Spec.Template.Spec, common.GetProxy(request))
+	common.AddTrustedCABundle(&daemonSet.Spec.Template.Spec, request.Instance.Spec.TrustedCABundle)
+	common.AddDNSConfig(&daemonSet.Spec.Template.Spec, request)
+	daemonSet.Spec.Template.Spec.PriorityClassName = request.Instance.Spec.NodeLabeller.PriorityClassName
+	daemonSet.Spec.Template.Spec.RuntimeClassName = request.Instance.Spec.RuntimeClassName
+	daemonSet.Spec.Template.Spec.ImagePullSecrets = request.Instance.Spec.NodeLabeller.ImagePullSecrets
+	overrideImages(&daemonSet.Spec.Template.Spec, request.Instance.Spec.NodeLabeller.Images)
 	status, err := createOrUpdateDaemonSet(request, daemonSet)
 	if errors.IsInvalid(err) {
 		return recreateDaemonSet(request, daemonSet)
@@ -147,9 +166,6 @@ func createOrUpdateDaemonSet(request *common.Request, daemonSet *apps.DaemonSet)
 	return common.CreateOrUpdate(request).
 		NamespacedResource(daemonSet).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRes.(*apps.DaemonSet).Spec = newRes.(*apps.DaemonSet).Spec
-		}).
 		StatusFunc(func(res controllerutil.Object) common.ResourceStatus {
 			ds := res.(*apps.DaemonSet)
 			status := common.ResourceStatus{}
@@ -173,28 +189,18 @@ func recreateDaemonSet(request *common.Request, daemonSet *apps.DaemonSet) (comm
 	return createOrUpdateDaemonSet(request, daemonSet)
 }
 
-func addPlacementFields(daemonset *apps.DaemonSet, nodePlacement *lifecycleapi.NodePlacement) {
-	if nodePlacement == nil {
-		return
+// getPlacement returns the node-labeller placement, falling back
+// to the workloads placement if the node-labeller does not define one.
+func getPlacement(request *common.Request) *lifecycleapi.NodePlacement {
+	if placement := request.Instance.Spec.NodeLabeller.Placement; placement != nil {
+		return placement
 	}
-
-	podSpec := &daemonset.Spec.Template.Spec
-	podSpec.Affinity = nodePlacement.Affinity
-	podSpec.NodeSelector = nodePlacement.NodeSelector
-	podSpec.Tolerations = nodePlacement.Tolerations
+	return request.Instance.Spec.Workloads.NodePlacement
 }
 
 func reconcileSecurityContextConstraint(request *common.Request) (common.ResourceStatus, error) {
 	return common.CreateOrUpdate(request).
 		ClusterResource(newSecurityContextConstraint(request.Namespace)).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundScc := foundRes.(*secv1.SecurityContextConstraints)
-			newScc := newRes.(*secv1.SecurityContextConstraints)
-			foundScc.AllowPrivilegedContainer = newScc.AllowPrivilegedContainer
-			foundScc.RunAsUser = newScc.RunAsUser
-			foundScc.SELinuxContext = newScc.SELinuxContext
-			foundScc.Users = newScc.Users
-		}).
 		Reconcile()
 }
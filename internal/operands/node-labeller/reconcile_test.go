@@ -7,11 +7,17 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	secv1 "github.com/openshift/api/security/v1"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 	. "kubevirt.io/ssp-operator/internal/test-utils"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -58,7 +64,8 @@ var _ = Describe("Node Labeller operand", func() {
 					Namespace: namespace,
 				},
 			},
-			Logger: log,
+			Logger:       log,
+			VersionCache: common.NewVersionCache(),
 		}
 	})
 
@@ -74,19 +81,171 @@ var _ = Describe("Node Labeller operand", func() {
 		ExpectResourceExists(newSecurityContextConstraint(namespace), request)
 	})
 
-	It("should remove cluster resources on cleanup", func() {
+	It("should set placement on daemon set", func() {
+		placement := &lifecycleapi.NodePlacement{
+			NodeSelector: map[string]string{
+				"testKey": "testValue",
+			},
+			Tolerations: []core.Toleration{{
+				Key:      "testKey",
+				Operator: core.TolerationOpExists,
+				Effect:   core.TaintEffectNoExecute,
+			}},
+		}
+		request.Instance.Spec.NodeLabeller.Placement = placement
+
 		_, err := operand.Reconcile(&request)
 		Expect(err).ToNot(HaveOccurred())
 
-		ExpectResourceExists(newClusterRole(), request)
-		ExpectResourceExists(newClusterRoleBinding(namespace), request)
-		ExpectResourceExists(newSecurityContextConstraint(namespace), request)
+		key, err := client.ObjectKeyFromObject(newDaemonSet(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		daemonSet := &apps.DaemonSet{}
+		Expect(request.Client.Get(request.Context, key, daemonSet)).ToNot(HaveOccurred())
+
+		podSpec := daemonSet.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(placement.NodeSelector))
+		Expect(podSpec.Tolerations).To(Equal(placement.Tolerations))
+		Expect(podSpec.Affinity).To(BeNil())
+	})
+
+	It("should use workloads placement if operand placement is not set", func() {
+		placement := &lifecycleapi.NodePlacement{
+			NodeSelector: map[string]string{
+				"testKey": "testValue",
+			},
+		}
+		request.Instance.Spec.Workloads.NodePlacement = placement
+		request.Instance.Spec.Infra.NodePlacement = &lifecycleapi.NodePlacement{
+			NodeSelector: map[string]string{
+				"otherKey": "otherValue",
+			},
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDaemonSet(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.DaemonSet{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.NodeSelector).To(Equal(placement.NodeSelector))
+	})
+
+	It("should set resource requirements on all daemon set containers", func() {
+		resources := &core.ResourceRequirements{
+			Requests: core.ResourceList{
+				core.ResourceCPU:    resource.MustParse("10m"),
+				core.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		}
+		request.Instance.Spec.NodeLabeller.Resources = resources
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDaemonSet(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		daemonSet := &apps.DaemonSet{}
+		Expect(request.Client.Get(request.Context, key, daemonSet)).ToNot(HaveOccurred())
+
+		podSpec := daemonSet.Spec.Template.Spec
+		containers := append(podSpec.InitContainers, podSpec.Containers...)
+		Expect(containers).ToNot(BeEmpty())
+		for _, container := range containers {
+			Expect(container.Resources.Requests.Cpu().Equal(resources.Requests[core.ResourceCPU])).To(BeTrue())
+			Expect(container.Resources.Requests.Memory().Equal(resources.Requests[core.ResourceMemory])).To(BeTrue())
+		}
+	})
+
+	It("should set priority class name", func() {
+		const priorityClassName = "system-cluster-critical"
+		request.Instance.Spec.NodeLabeller.PriorityClassName = priorityClassName
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDaemonSet(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.DaemonSet{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.PriorityClassName).To(Equal(priorityClassName))
+	})
+
+	It("should set DNS policy and config", func() {
+		dnsConfig := &core.PodDNSConfig{
+			Nameservers: []string{"10.0.0.10"},
+			Searches:    []string{"internal.example.com"},
+		}
+		request.Instance.Spec.DNSPolicy = core.DNSNone
+		request.Instance.Spec.DNSConfig = dnsConfig
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDaemonSet(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.DaemonSet{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.DNSPolicy).To(Equal(core.DNSNone))
+		Expect(found.Spec.Template.Spec.DNSConfig).To(Equal(dnsConfig))
+	})
+
+	It("should override images and set pull secrets", func() {
+		const mirror = "mirror.example.com/kubevirt/"
+		pullSecrets := []core.LocalObjectReference{{Name: "mirror-pull-secret"}}
+		request.Instance.Spec.NodeLabeller.ImagePullSecrets = pullSecrets
+		request.Instance.Spec.NodeLabeller.Images = &ssp.NodeLabellerImages{
+			NodeLabeller: mirror + "node-labeller:test",
+			VirtLauncher: mirror + "virt-launcher:test",
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDaemonSet(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.DaemonSet{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.ImagePullSecrets).To(Equal(pullSecrets))
+
+		images := map[string]string{}
+		for _, container := range append(found.Spec.Template.Spec.InitContainers, found.Spec.Template.Spec.Containers...) {
+			images[container.Name] = container.Image
+		}
+		Expect(images["kubevirt-node-labeller"]).To(Equal(mirror + "node-labeller:test"))
+		Expect(images["kubevirt-node-labeller-sleeper"]).To(Equal(mirror + "node-labeller:test"))
+		Expect(images["libvirt"]).To(Equal(mirror + "virt-launcher:test"))
+		Expect(images["kvm-info-nfd-plugin"]).To(Equal(getNodeLabellerImages().kvmInfoNFD))
+		Expect(images["kubevirt-cpu-nfd-plugin"]).To(Equal(getNodeLabellerImages().cpuNFD))
+	})
+
+	It("should remove all resources on cleanup", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		resources := []controllerutil.Object{
+			newDaemonSet(namespace),
+			newConfigMap(namespace),
+			newServiceAccount(namespace),
+			newClusterRole(),
+			newClusterRoleBinding(namespace),
+			newSecurityContextConstraint(namespace),
+		}
+		for _, resource := range resources {
+			ExpectResourceExists(resource, request)
+		}
 
 		Expect(operand.Cleanup(&request)).ToNot(HaveOccurred())
 
-		ExpectResourceNotExists(newClusterRole(), request)
-		ExpectResourceNotExists(newClusterRoleBinding(namespace), request)
-		ExpectResourceNotExists(newSecurityContextConstraint(namespace), request)
+		for _, resource := range resources {
+			ExpectResourceNotExists(resource, request)
+		}
 	})
 })
 
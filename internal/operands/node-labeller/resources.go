@@ -6,6 +6,7 @@ import (
 	core "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
 	"kubevirt.io/ssp-operator/internal/common"
 )
 
@@ -25,6 +26,12 @@ const (
 	SecurityContextName      = kubevirtNodeLabeller
 )
 
+func podLabels() map[string]string {
+	return map[string]string{
+		"app": kubevirtNodeLabeller,
+	}
+}
+
 type nodeLabellerImages struct {
 	nodeLabeller string
 	sleeper      string
@@ -43,6 +50,55 @@ func getNodeLabellerImages() nodeLabellerImages {
 	}
 }
 
+// Images returns the node-labeller images deployed for the spec
+func Images(spec *ssp.SSPSpec) ssp.NodeLabellerImages {
+	defaults := getNodeLabellerImages()
+	images := ssp.NodeLabellerImages{
+		NodeLabeller:     defaults.nodeLabeller,
+		KvmInfoNfdPlugin: defaults.kvmInfoNFD,
+		CpuNfdPlugin:     defaults.cpuNFD,
+		VirtLauncher:     defaults.virtLauncher,
+	}
+	overrides := spec.NodeLabeller.Images
+	if overrides == nil {
+		return images
+	}
+	if overrides.NodeLabeller != "" {
+		images.NodeLabeller = overrides.NodeLabeller
+	}
+	if overrides.KvmInfoNfdPlugin != "" {
+		images.KvmInfoNfdPlugin = overrides.KvmInfoNfdPlugin
+	}
+	if overrides.CpuNfdPlugin != "" {
+		images.CpuNfdPlugin = overrides.CpuNfdPlugin
+	}
+	if overrides.VirtLauncher != "" {
+		images.VirtLauncher = overrides.VirtLauncher
+	}
+	return images
+}
+
+// overrideImages replaces the default container images with those set in the SSP CR
+func overrideImages(podSpec *core.PodSpec, images *ssp.NodeLabellerImages) {
+	if images == nil {
+		return
+	}
+	overrides := map[string]string{
+		"kubevirt-node-labeller-sleeper": images.NodeLabeller,
+		"kubevirt-node-labeller":         images.NodeLabeller,
+		"kvm-info-nfd-plugin":            images.KvmInfoNfdPlugin,
+		"kubevirt-cpu-nfd-plugin":        images.CpuNfdPlugin,
+		"libvirt":                        images.VirtLauncher,
+	}
+	for _, containers := range [][]core.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if image := overrides[containers[i].Name]; image != "" {
+				containers[i].Image = image
+			}
+		}
+	}
+}
+
 func newClusterRole() *rbac.ClusterRole {
 	return &rbac.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -185,7 +241,7 @@ func initContainerKubevirtNodeLabeller() *core.Container {
 	args := []string{"if [ ! -e /dev/kvm ] && [ $(grep '\\<kvm\\>' /proc/misc | wc -l) -eq 0 ]; then echo 'exiting due to missing kvm device'; exit 0; fi; if [ ! -e /dev/kvm ]; then mknod /dev/kvm c 10 $(grep '\\<kvm\\>' /proc/misc | cut -f 1 -d' '); fi; ./usr/sbin/node-labeller"}
 	var boolVal = true
 	return &core.Container{
-		Name:    "kubevirt-node-labeller",
+		Name:    kubevirtNodeLabeller,
 		Image:   getNodeLabellerImages().nodeLabeller,
 		Command: []string{"/bin/sh", "-c"},
 		Args:    args,
@@ -224,9 +280,7 @@ func newDaemonSet(namespace string) *apps.DaemonSet {
 		*kubevirtNodeLabellerSleeperContainer(),
 	}
 
-	commonLabels := map[string]string{
-		"app": "kubevirt-node-labeller",
-	}
+	commonLabels := podLabels()
 	return &apps.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      DaemonSetName,
@@ -2,6 +2,7 @@ package operands
 
 import (
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"kubevirt.io/ssp-operator/internal/common"
 )
@@ -17,11 +18,26 @@ type Operand interface {
 	// WatchClusterTypes returns a slice of cluster resources, that the operator should watch.
 	WatchClusterTypes() []runtime.Object
 
+	// RequiredAPIs returns the API resources that have to be served to deploy the operand,
+	// either by CRDs or by an aggregated API server. Operands with missing APIs are skipped
+	// until the APIs are available, e.g. OpenShift-only operands on Kubernetes.
+	RequiredAPIs() []schema.GroupVersionResource
+
+	// Enabled returns true if the operand should be deployed.
+	// Disabled operands are not reconciled, and their resources are removed.
+	Enabled(*common.Request) bool
+
+	// Version returns the version of the operand deployed for the request,
+	// for example the templates bundle version or the container image.
+	// It is empty if the operand has no version of its own.
+	Version(*common.Request) string
+
 	// Reconcile creates and updates resources.
 	Reconcile(*common.Request) ([]common.ResourceStatus, error)
 
-	// Cleanup removes any created cluster resources.
-	// They don't use owner references, so the garbage collector will not remove them.
+	// Cleanup removes all created resources. It is called when the operand
+	// is disabled, and when the SSP CR is deleted. Cluster resources don't use
+	// owner references, so the garbage collector will not remove them.
 	Cleanup(*common.Request) error
 
 	// Name returns the name of the operand
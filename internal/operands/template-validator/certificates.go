@@ -0,0 +1,430 @@
+package template_validator
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"time"
+
+	admission "k8s.io/api/admissionregistration/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+const (
+	CASecretName = "virt-template-validator-ca"
+
+	caBundleKey = "ca-bundle.crt"
+)
+
+// now returns the current time, it is replaced in tests
+var now = time.Now
+
+const (
+	// bundleUpdateRequeue is the delay of the next reconciliation,
+	// while the serving certificate waits for the webhook to trust a renewed CA
+	bundleUpdateRequeue = 5 * time.Second
+
+	// servingCertProbeWindow is how long after its renewal it is verified
+	// that the validator serves the new certificate
+	servingCertProbeWindow  = 10 * time.Minute
+	servingCertProbeRequeue = 15 * time.Second
+	servingCertProbeTimeout = 5 * time.Second
+)
+
+// probeServingCertificate returns the certificate served at the address, it is replaced in tests
+var probeServingCertificate = defaultProbeServingCertificate
+
+func defaultProbeServingCertificate(address string, roots *x509.CertPool, serverName string) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: servingCertProbeTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		RootCAs:    roots,
+		ServerName: serverName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+// isCertManaged returns true if the operator issues the validator certificates
+// instead of the OpenShift service CA operator
+func isCertManaged(request *common.Request) bool {
+	return request.Instance.Spec.CertConfig != nil
+}
+
+// effectiveCertConfig returns spec.certConfig, with the intervals that are not set
+// taken from the certificate rotation strategy of the KubeVirt CR.
+// The KubeVirt intervals are ignored if they are not consistent with spec.certConfig.
+func effectiveCertConfig(request *common.Request) *ssp.CertConfig {
+	certConfig := request.Instance.Spec.CertConfig
+	kubeVirt := request.KubeVirt
+	if kubeVirt == nil {
+		return certConfig
+	}
+	effective := certConfig.DeepCopy()
+	fillInterval(&effective.CARotateInterval, kubeVirt.CARotateInterval)
+	fillInterval(&effective.CAOverlapInterval, kubeVirt.CAOverlapInterval)
+	fillInterval(&effective.CertRotateInterval, kubeVirt.CertRotateInterval)
+
+	caRotateInterval := effective.GetCARotateInterval()
+	if effective.GetCAOverlapInterval() >= caRotateInterval || effective.GetCertRotateInterval() > caRotateInterval {
+		return certConfig
+	}
+	return effective
+}
+
+func fillInterval(interval **metav1.Duration, value time.Duration) {
+	if *interval == nil && value > 0 {
+		*interval = &metav1.Duration{Duration: value}
+	}
+}
+
+// reconcileCertificates issues the CA and serving certificates of the validator,
+// renews them according to spec.certConfig and requests a reconciliation
+// before the next renewal.
+//
+// A renewed CA is first added to the CA bundle of the webhook configuration.
+// The serving certificate is issued by the renewed CA only after the webhook
+// configuration trusts it, so the API server can call the webhook during the rotation.
+func reconcileCertificates(request *common.Request) (common.ResourceStatus, error) {
+	if !isCertManaged(request) {
+		return removeManagedCertificates(request)
+	}
+	certConfig := effectiveCertConfig(request)
+	currentTime := now()
+
+	caSecret, err := getSecret(request, CASecretName)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+	caCert, caKey, err := parseKeyPair(caSecret)
+	caRenewed := false
+	if err != nil || !currentTime.Before(caRenewTime(caCert, certConfig)) {
+		caCert, caKey, err = newCACertificate(currentTime, certConfig.GetCARotateInterval())
+		if err != nil {
+			return common.ResourceStatus{}, err
+		}
+		caRenewed = true
+	}
+
+	servingSecret, err := getSecret(request, secretName)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+	servingCert, servingKey, err := parseKeyPair(servingSecret)
+	renewServing := err != nil || !currentTime.Before(servingRenewTime(servingCert))
+	waitingForBundle := false
+	if !renewServing && servingCert.CheckSignatureFrom(caCert) != nil {
+		// The serving certificate was issued by the previous CA
+		trusted, err := webhookTrustsCA(request, caCert)
+		if err != nil {
+			return common.ResourceStatus{}, err
+		}
+		renewServing = trusted
+		waitingForBundle = !trusted
+	}
+	servingRenewed := false
+	if renewServing {
+		servingCert, servingKey, err = newServingCertificate(request.Namespace, caCert, caKey, currentTime, certConfig.GetCertRotateInterval())
+		if err != nil {
+			return common.ResourceStatus{}, err
+		}
+		servingRenewed = true
+	}
+
+	caSecretData, err := secretData(caCert, caKey)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+	caSecretData[caBundleKey] = appendToCABundle(caSecret.Data[caBundleKey], caCert, currentTime)
+	caBundleChanged := !bytes.Equal(caSecretData[caBundleKey], caSecret.Data[caBundleKey])
+	_, err = reconcileSecret(request, newCASecret(request.Namespace, caSecretData), caRenewed || caBundleChanged)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+
+	servingSecretData, err := secretData(servingCert, servingKey)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+	status, err := reconcileSecret(request, newServingSecret(request.Namespace, servingSecretData), servingRenewed)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+
+	nextRenewal := caRenewTime(caCert, certConfig)
+	if renewTime := servingRenewTime(servingCert); renewTime.Before(nextRenewal) {
+		nextRenewal = renewTime
+	}
+	status.RequeueAfter = nextRenewal.Sub(currentTime)
+
+	if waitingForBundle {
+		msg := "Waiting for the webhook configuration to trust the renewed CA"
+		status.Progressing = &msg
+		status.RequeueAfter = bundleUpdateRequeue
+	} else if currentTime.Sub(servingCert.NotBefore) < servingCertProbeWindow {
+		if msg := verifyServingCertificate(request.Namespace, caSecretData[caBundleKey], servingCert); msg != nil {
+			status.Progressing = msg
+			if status.RequeueAfter > servingCertProbeRequeue {
+				status.RequeueAfter = servingCertProbeRequeue
+			}
+		}
+	}
+	return status, nil
+}
+
+// webhookTrustsCA returns true if the CA bundles of the webhook configuration contain the CA certificate
+func webhookTrustsCA(request *common.Request, caCert *x509.Certificate) (bool, error) {
+	webhookConf := &admission.ValidatingWebhookConfiguration{}
+	err := request.Client.Get(request.Context, client.ObjectKey{Name: WebhookName}, webhookConf)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(webhookConf.Webhooks) == 0 {
+		return false, nil
+	}
+	for _, webhook := range webhookConf.Webhooks {
+		if !bundleContains(webhook.ClientConfig.CABundle, caCert) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func bundleContains(bundle []byte, caCert *x509.Certificate) bool {
+	certs, err := cert.ParseCertsPEM(bundle)
+	if err != nil {
+		return false
+	}
+	for _, bundleCert := range certs {
+		if bundleCert.Equal(caCert) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyServingCertificate checks that the validator service serves the serving certificate
+// and that it is trusted by the CA bundle. It returns a message if it is not served yet.
+func verifyServingCertificate(namespace string, bundle []byte, servingCert *x509.Certificate) common.StatusMessage {
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(bundle)
+	serverName := fmt.Sprintf("%s.%s.svc", ServiceName, namespace)
+	served, err := probeServingCertificate(serverName+":443", roots, serverName)
+	if err != nil {
+		msg := fmt.Sprintf("Cannot verify the certificate served by the template validator: %v", err)
+		return &msg
+	}
+	if !served.Equal(servingCert) {
+		msg := "The template validator does not serve the renewed certificate yet"
+		return &msg
+	}
+	return nil
+}
+
+// removeManagedCertificates deletes the certificates issued by the operator,
+// so they can be provided by the OpenShift service CA operator again
+func removeManagedCertificates(request *common.Request) (common.ResourceStatus, error) {
+	caSecret := newCASecret(request.Namespace, nil)
+	err := request.Client.Get(request.Context, client.ObjectKey{Namespace: request.Namespace, Name: CASecretName}, caSecret)
+	if errors.IsNotFound(err) {
+		return common.ResourceStatus{Resource: caSecret, Removed: true}, nil
+	}
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+	for _, secret := range []controllerutil.Object{newServingSecret(request.Namespace, nil), caSecret} {
+		err = request.Client.Delete(request.Context, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return common.ResourceStatus{}, err
+		}
+		request.VersionCache.RemoveObj(secret)
+	}
+	return common.ResourceStatus{Resource: caSecret, Removed: true}, nil
+}
+
+// getManagedCABundle returns the CA bundle of the certificates issued by the operator.
+// It returns nil if the certificates are provided by the OpenShift service CA operator.
+func getManagedCABundle(request *common.Request) ([]byte, error) {
+	if !isCertManaged(request) {
+		return nil, nil
+	}
+	caSecret, err := getSecret(request, CASecretName)
+	if err != nil {
+		return nil, err
+	}
+	if len(caSecret.Data[caBundleKey]) == 0 {
+		return nil, fmt.Errorf("CA bundle of the template validator is not available yet")
+	}
+	return caSecret.Data[caBundleKey], nil
+}
+
+func reconcileSecret(request *common.Request, secret *core.Secret, changed bool) (common.ResourceStatus, error) {
+	if changed {
+		// The found secret may not have changed since the last reconciliation,
+		// but the renewed certificates have to be written
+		request.VersionCache.RemoveObj(secret)
+	}
+	return common.CreateOrUpdate(request).
+		NamespacedResource(secret).
+		WithAppLabels(operandName, operandComponent).
+		Reconcile()
+}
+
+// getSecret returns the secret, or an empty secret if it does not exist
+func getSecret(request *common.Request, name string) (*core.Secret, error) {
+	secret := &core.Secret{}
+	err := request.Client.Get(request.Context, client.ObjectKey{Namespace: request.Namespace, Name: name}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func parseKeyPair(secret *core.Secret) (*x509.Certificate, crypto.Signer, error) {
+	certs, err := cert.ParseCertsPEM(secret.Data[core.TLSCertKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(secret.Data[core.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("private key of secret %s cannot be used for signing", secret.Name)
+	}
+	return certs[0], signer, nil
+}
+
+func caRenewTime(caCert *x509.Certificate, certConfig *ssp.CertConfig) time.Time {
+	return caCert.NotAfter.Add(-certConfig.GetCAOverlapInterval())
+}
+
+func servingRenewTime(servingCert *x509.Certificate) time.Time {
+	validity := servingCert.NotAfter.Sub(servingCert.NotBefore)
+	return servingCert.NotAfter.Add(-validity / 5)
+}
+
+func newCACertificate(currentTime time.Time, validity time.Duration) (*x509.Certificate, crypto.Signer, error) {
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: fmt.Sprintf("%s-ca@%d", virtTemplateValidator, currentTime.Unix()),
+		},
+		NotBefore:             currentTime,
+		NotAfter:              currentTime.Add(validity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newCertificate(template, nil, nil)
+}
+
+func newServingCertificate(namespace string, caCert *x509.Certificate, caKey crypto.Signer, currentTime time.Time, validity time.Duration) (*x509.Certificate, crypto.Signer, error) {
+	notAfter := currentTime.Add(validity)
+	if caCert.NotAfter.Before(notAfter) {
+		notAfter = caCert.NotAfter
+	}
+	serviceName := fmt.Sprintf("%s.%s.svc", ServiceName, namespace)
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: serviceName,
+		},
+		DNSNames:    []string{serviceName, serviceName + ".cluster.local"},
+		NotBefore:   currentTime,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return newCertificate(template, caCert, caKey)
+}
+
+// newCertificate generates a key and a certificate signed by the parent,
+// the certificate is self-signed if the parent is nil
+func newCertificate(template, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certificate, key, nil
+}
+
+func secretData(certificate *x509.Certificate, key crypto.Signer) (map[string][]byte, error) {
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		core.TLSCertKey:       encodeCertPEM(certificate),
+		core.TLSPrivateKeyKey: keyPEM,
+	}, nil
+}
+
+// appendToCABundle returns a bundle of the CA certificate and the not yet expired
+// certificates from the previous bundle, so certificates signed by a previous CA
+// are trusted until the previous CA expires
+func appendToCABundle(bundle []byte, caCert *x509.Certificate, currentTime time.Time) []byte {
+	result := encodeCertPEM(caCert)
+	if len(bundle) == 0 {
+		return result
+	}
+	certs, err := cert.ParseCertsPEM(bundle)
+	if err != nil {
+		// A corrupted bundle is replaced
+		return result
+	}
+	for _, previousCert := range certs {
+		if previousCert.Equal(caCert) || !currentTime.Before(previousCert.NotAfter) {
+			continue
+		}
+		result = append(result, encodeCertPEM(previousCert)...)
+	}
+	return result
+}
+
+func encodeCertPEM(certificate *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  cert.CertificateBlockType,
+		Bytes: certificate.Raw,
+	})
+}
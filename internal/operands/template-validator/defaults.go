@@ -1,5 +1,12 @@
 package template_validator
 
+import (
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+)
+
 const (
-	defaultTemplateValidatorImage = "quay.io/kubevirt/kubevirt-template-validator:v0.9.0"
+	defaultTemplateValidatorImage    = "quay.io/kubevirt/kubevirt-template-validator:v0.9.0"
+	defaultTemplateValidatorReplicas = ssp.DefaultTemplateValidatorReplicas
+	defaultLogVerbosity              = ssp.DefaultTemplateValidatorLogVerbosity
+	defaultTargetCPUUtilization      = 80
 )
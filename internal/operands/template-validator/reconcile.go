@@ -5,22 +5,33 @@ import (
 
 	admission "k8s.io/api/admissionregistration/v1"
 	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
 	"kubevirt.io/ssp-operator/internal/common"
 	"kubevirt.io/ssp-operator/internal/operands"
 )
 
 // Define RBAC rules needed by this operand:
-// +kubebuilder:rbac:groups=core,resources=services;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services;serviceaccounts;configmaps;secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;patch
 
 // RBAC for created roles
 // +kubebuilder:rbac:groups=template.openshift.io,resources=templates,verbs=get;list;watch
@@ -39,7 +50,11 @@ func (t *templateValidator) WatchTypes() []runtime.Object {
 	return []runtime.Object{
 		&v1.ServiceAccount{},
 		&v1.Service{},
+		&v1.ConfigMap{},
+		&v1.Secret{},
 		&apps.Deployment{},
+		&policy.PodDisruptionBudget{},
+		&autoscaling.HorizontalPodAutoscaler{},
 	}
 }
 
@@ -51,28 +66,62 @@ func (t *templateValidator) WatchClusterTypes() []runtime.Object {
 	}
 }
 
+func (t *templateValidator) RequiredAPIs() []schema.GroupVersionResource {
+	return nil
+}
+
+func (t *templateValidator) Enabled(request *common.Request) bool {
+	return pointer.BoolPtrDerefOr(request.Instance.Spec.TemplateValidator.Enabled, true)
+}
+
+// Version returns the image ID of the running validator pods, which contains the digest of the image.
+// The configured validator image is returned if the running image is not known.
+func (t *templateValidator) Version(request *common.Request) string {
+	return common.RunningImageID(request, commonLabels(), webhookContainerName, Image(&request.Instance.Spec))
+}
+
 func (t *templateValidator) Reconcile(request *common.Request) ([]common.ResourceStatus, error) {
+	err := reconcileNamespaceNameLabels(request)
+	if err != nil {
+		return nil, err
+	}
+
 	return common.CollectResourceStatus(request,
 		reconcileClusterRole,
 		reconcileServiceAccount,
 		reconcileClusterRoleBinding,
+		reconcileCertificates,
 		reconcileService,
+		removeRulesConfigMap,
 		reconcileDeployment,
+		reconcileHorizontalPodAutoscaler,
+		reconcilePodDisruptionBudget,
 		reconcileValidatingWebhook,
 	)
 }
 
 func (t *templateValidator) Cleanup(request *common.Request) error {
+	// The webhook is removed first, so it does not reject requests
+	// while the validator pods are being removed
 	for _, obj := range []controllerutil.Object{
-		newClusterRole(),
-		newClusterRoleBinding(request.Namespace),
 		newValidatingWebhook(request.Namespace),
+		newPodDisruptionBudget(request.Namespace, nil, nil),
+		newHorizontalPodAutoscaler(request.Namespace, nil, 0, nil),
+		newDeployment(request.Namespace, 0, "", 0),
+		newRulesConfigMap(request.Namespace),
+		newService(request.Namespace),
+		newServingSecret(request.Namespace, nil),
+		newCASecret(request.Namespace, nil),
+		newClusterRoleBinding(request.Namespace),
+		newServiceAccount(request.Namespace),
+		newClusterRole(),
 	} {
 		err := request.Client.Delete(request.Context, obj)
 		if err != nil && !errors.IsNotFound(err) {
 			request.Logger.Error(err, fmt.Sprintf("Error deleting \"%s\": %s", obj.GetName(), err))
 			return err
 		}
+		request.VersionCache.RemoveObj(obj)
 	}
 	return nil
 }
@@ -92,9 +141,6 @@ func reconcileClusterRole(request *common.Request) (common.ResourceStatus, error
 	return common.CreateOrUpdate(request).
 		ClusterResource(newClusterRole()).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRes.(*rbac.ClusterRole).Rules = newRes.(*rbac.ClusterRole).Rules
-		}).
 		Reconcile()
 }
 
@@ -109,100 +155,365 @@ func reconcileClusterRoleBinding(request *common.Request) (common.ResourceStatus
 	return common.CreateOrUpdate(request).
 		ClusterResource(newClusterRoleBinding(request.Namespace)).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			newBinding := newRes.(*rbac.ClusterRoleBinding)
-			foundBinding := foundRes.(*rbac.ClusterRoleBinding)
-			foundBinding.RoleRef = newBinding.RoleRef
-			foundBinding.Subjects = newBinding.Subjects
-		}).
 		Reconcile()
 }
 
 func reconcileService(request *common.Request) (common.ResourceStatus, error) {
+	service := newService(request.Namespace)
+	addServiceMetadata(service, request.Instance.Spec.TemplateValidator.ServiceMetadata)
+	certManaged := isCertManaged(request)
+	if certManaged {
+		delete(service.Annotations, servingCertSecretAnnotation)
+	}
 	return common.CreateOrUpdate(request).
-		NamespacedResource(newService(request.Namespace)).
+		NamespacedResource(service).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			newService := newRes.(*v1.Service)
-			foundService := foundRes.(*v1.Service)
+		Reconcile()
+}
 
-			// ClusterIP should not be updated
-			newService.Spec.ClusterIP = foundService.Spec.ClusterIP
+// addServiceMetadata adds the configured labels and annotations to the service,
+// without overriding the ones set by the operator
+func addServiceMetadata(service *v1.Service, metadata *ssp.ServiceMetadata) {
+	if metadata == nil {
+		return
+	}
+	if service.Labels == nil {
+		service.Labels = map[string]string{}
+	}
+	for key, value := range metadata.Labels {
+		if _, ok := service.Labels[key]; !ok {
+			service.Labels[key] = value
+		}
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	for key, value := range metadata.Annotations {
+		if _, ok := service.Annotations[key]; !ok {
+			service.Annotations[key] = value
+		}
+	}
+}
 
-			foundService.Spec = newService.Spec
-		}).
-		Reconcile()
+// removeRulesConfigMap removes the ConfigMap with additional validation rules
+// created by previous operator versions. The validator never loaded the rules from it.
+func removeRulesConfigMap(request *common.Request) (common.ResourceStatus, error) {
+	configMap := newRulesConfigMap(request.Namespace)
+	err := request.Client.Delete(request.Context, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return common.ResourceStatus{}, err
+	}
+	request.VersionCache.RemoveObj(configMap)
+	return common.ResourceStatus{Resource: configMap, Removed: true}, nil
 }
 
 func reconcileDeployment(request *common.Request) (common.ResourceStatus, error) {
-	validatorSpec := request.Instance.Spec.TemplateValidator
-	image := getTemplateValidatorImage()
-	deployment := newDeployment(request.Namespace, *validatorSpec.Replicas, image)
-	addPlacementFields(deployment, validatorSpec.Placement)
+	replicas := getReplicas(request)
+	autoscaled := request.Instance.Spec.TemplateValidator.Autoscaling != nil
+	image := Image(&request.Instance.Spec)
+	logVerbosity := pointer.Int32PtrDerefOr(request.Instance.Spec.TemplateValidator.LogVerbosity, defaultLogVerbosity)
+	deployment := newDeployment(request.Namespace, replicas, image, logVerbosity)
+	if autoscaled {
+		// Replicas are managed by the autoscaler, so the operator does not apply them
+		deployment.Spec.Replicas = nil
+	}
+	deployment.Spec.Template.Spec.ImagePullSecrets = request.Instance.Spec.TemplateValidator.ImagePullSecrets
+	common.AddPlacementFields(&deployment.Spec.Template.Spec, getPlacement(request))
+	common.AddResourceRequirements(&deployment.Spec.Template.Spec, request.Instance.Spec.TemplateValidator.Resources)
+	common.AddProxyEnv(&deployment.Spec.Template.Spec, common.GetProxy(request))
+	common.AddTrustedCABundle(&deployment.Spec.Template.Spec, request.Instance.Spec.TrustedCABundle)
+	common.AddDNSConfig(&deployment.Spec.Template.Spec, request)
+	deployment.Spec.Template.Spec.PriorityClassName = request.Instance.Spec.TemplateValidator.PriorityClassName
+	deployment.Spec.Template.Spec.RuntimeClassName = request.Instance.Spec.RuntimeClassName
+	deployment.Spec.Template.Spec.TopologySpreadConstraints = getTopologySpreadConstraints(request, replicas)
+	if rollingUpdate := request.Instance.Spec.TemplateValidator.RollingUpdate; rollingUpdate != nil {
+		deployment.Spec.Strategy = apps.DeploymentStrategy{
+			Type:          apps.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: rollingUpdate,
+		}
+	}
+	deployment.Spec.Template.Spec.SecurityContext = request.Instance.Spec.TemplateValidator.PodSecurityContext
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Args = append(container.Args, tlsArgs(image, request.Instance.Spec.TLSSecurityProfile)...)
+	// Older validators are not passed the TLS flags, so a profile set in the SSP CR is not applied
+	tlsProfileIgnored := request.Instance.Spec.TLSSecurityProfile != nil && !imageVersionAtLeast(image, tlsFlagsMinVersion)
+	mergeSecurityContext(container.SecurityContext, request.Instance.Spec.TemplateValidator.SecurityContext)
 	return common.CreateOrUpdate(request).
 		NamespacedResource(deployment).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			foundRes.(*apps.Deployment).Spec = newRes.(*apps.Deployment).Spec
-		}).
 		StatusFunc(func(res controllerutil.Object) common.ResourceStatus {
 			dep := res.(*apps.Deployment)
+			expectedReplicas := replicas
+			if autoscaled && dep.Spec.Replicas != nil {
+				expectedReplicas = *dep.Spec.Replicas
+			}
 			status := common.ResourceStatus{}
-			if *validatorSpec.Replicas > 0 && dep.Status.AvailableReplicas == 0 {
+			if expectedReplicas > 0 && dep.Status.AvailableReplicas == 0 {
 				msg := fmt.Sprintf("No validator pods are running. Expected: %d", dep.Status.Replicas)
 				status.NotAvailable = &msg
 			}
-			if dep.Status.AvailableReplicas != *validatorSpec.Replicas {
+			if dep.Status.AvailableReplicas != expectedReplicas {
 				msg := fmt.Sprintf(
 					"Not all template validator pods are running. Expected: %d, running: %d",
-					*validatorSpec.Replicas,
+					expectedReplicas,
 					dep.Status.AvailableReplicas,
 				)
 				status.Progressing = &msg
 				status.Degraded = &msg
 			}
+			if tlsProfileIgnored && status.Degraded == nil {
+				msg := fmt.Sprintf("The TLS security profile is not applied, because the template validator image %s "+
+					"is older than v%s", image, tlsFlagsMinVersion)
+				status.Degraded = &msg
+			}
 			return status
 		}).
 		Reconcile()
 }
 
-func addPlacementFields(deployment *apps.Deployment, nodePlacement *lifecycleapi.NodePlacement) {
-	if nodePlacement == nil {
+// mergeSecurityContext overrides the fields of the security context
+// that are set in the override
+func mergeSecurityContext(securityContext *v1.SecurityContext, override *v1.SecurityContext) {
+	if override == nil {
 		return
 	}
+	if override.Capabilities != nil {
+		securityContext.Capabilities = override.Capabilities
+	}
+	if override.Privileged != nil {
+		securityContext.Privileged = override.Privileged
+	}
+	if override.SELinuxOptions != nil {
+		securityContext.SELinuxOptions = override.SELinuxOptions
+	}
+	if override.WindowsOptions != nil {
+		securityContext.WindowsOptions = override.WindowsOptions
+	}
+	if override.RunAsUser != nil {
+		securityContext.RunAsUser = override.RunAsUser
+	}
+	if override.RunAsGroup != nil {
+		securityContext.RunAsGroup = override.RunAsGroup
+	}
+	if override.RunAsNonRoot != nil {
+		securityContext.RunAsNonRoot = override.RunAsNonRoot
+	}
+	if override.ReadOnlyRootFilesystem != nil {
+		securityContext.ReadOnlyRootFilesystem = override.ReadOnlyRootFilesystem
+	}
+	if override.AllowPrivilegeEscalation != nil {
+		securityContext.AllowPrivilegeEscalation = override.AllowPrivilegeEscalation
+	}
+	if override.ProcMount != nil {
+		securityContext.ProcMount = override.ProcMount
+	}
+	if override.SeccompProfile != nil {
+		securityContext.SeccompProfile = override.SeccompProfile
+	}
+}
 
-	podSpec := &deployment.Spec.Template.Spec
-	podSpec.Affinity = nodePlacement.Affinity
-	podSpec.NodeSelector = nodePlacement.NodeSelector
-	podSpec.Tolerations = nodePlacement.Tolerations
+func reconcileHorizontalPodAutoscaler(request *common.Request) (common.ResourceStatus, error) {
+	config := request.Instance.Spec.TemplateValidator.Autoscaling
+	if config == nil {
+		hpa := newHorizontalPodAutoscaler(request.Namespace, nil, 0, nil)
+		err := request.Client.Delete(request.Context, hpa)
+		if err != nil && !errors.IsNotFound(err) {
+			return common.ResourceStatus{}, err
+		}
+		request.VersionCache.RemoveObj(hpa)
+		return common.ResourceStatus{Resource: hpa, Removed: true}, nil
+	}
+	hpa := newHorizontalPodAutoscaler(request.Namespace, config.MinReplicas, config.MaxReplicas, getAutoscalerMetrics(config))
+	return common.CreateOrUpdate(request).
+		NamespacedResource(hpa).
+		WithAppLabels(operandName, operandComponent).
+		Reconcile()
 }
 
-func reconcileValidatingWebhook(request *common.Request) (common.ResourceStatus, error) {
+// getAutoscalerMetrics returns the metrics of the validator autoscaler.
+// The CPU utilization target is used if no other metric is configured.
+func getAutoscalerMetrics(config *ssp.Autoscaling) []autoscaling.MetricSpec {
+	metrics := append([]autoscaling.MetricSpec{}, config.Metrics...)
+	targetCPU := config.TargetCPUUtilizationPercentage
+	if targetCPU == nil && len(metrics) == 0 {
+		targetCPU = pointer.Int32Ptr(defaultTargetCPUUtilization)
+	}
+	if targetCPU != nil {
+		metrics = append(metrics, autoscaling.MetricSpec{
+			Type: autoscaling.ResourceMetricSourceType,
+			Resource: &autoscaling.ResourceMetricSource{
+				Name: v1.ResourceCPU,
+				Target: autoscaling.MetricTarget{
+					Type:               autoscaling.UtilizationMetricType,
+					AverageUtilization: targetCPU,
+				},
+			},
+		})
+	}
+	return metrics
+}
+
+func reconcilePodDisruptionBudget(request *common.Request) (common.ResourceStatus, error) {
+	minAvailable, maxUnavailable, enabled := getDisruptionBudget(request)
+	pdb := newPodDisruptionBudget(request.Namespace, minAvailable, maxUnavailable)
+	if !enabled {
+		err := request.Client.Delete(request.Context, pdb)
+		if err != nil && !errors.IsNotFound(err) {
+			return common.ResourceStatus{}, err
+		}
+		request.VersionCache.RemoveObj(pdb)
+		return common.ResourceStatus{Resource: pdb, Removed: true}, nil
+	}
 	return common.CreateOrUpdate(request).
-		ClusterResource(newValidatingWebhook(request.Namespace)).
+		NamespacedResource(pdb).
 		WithAppLabels(operandName, operandComponent).
-		UpdateFunc(func(newRes, foundRes controllerutil.Object) {
-			newWebhookConf := newRes.(*admission.ValidatingWebhookConfiguration)
-			foundWebhookConf := foundRes.(*admission.ValidatingWebhookConfiguration)
+		Reconcile()
+}
 
-			// Copy CA Bundle from the found webhook,
-			// so it will not be overwritten
-			copyFoundCaBundles(newWebhookConf.Webhooks, foundWebhookConf.Webhooks)
+// getDisruptionBudget returns the configured disruption budget of the validator pods.
+// If no budget is configured, minAvailable=1 is used when there is more than one replica
+// and more than one node, because on a single node the budget would block node drains.
+func getDisruptionBudget(request *common.Request) (minAvailable, maxUnavailable *intstr.IntOrString, enabled bool) {
+	pdb := request.Instance.Spec.TemplateValidator.PodDisruptionBudget
+	if pdb != nil {
+		if !pointer.BoolPtrDerefOr(pdb.Enabled, true) {
+			return nil, nil, false
+		}
+		if pdb.MinAvailable != nil || pdb.MaxUnavailable != nil {
+			return pdb.MinAvailable, pdb.MaxUnavailable, true
+		}
+	}
+	if request.SingleNode || getReplicas(request) <= 1 {
+		return nil, nil, false
+	}
+	defaultMinAvailable := intstr.FromInt(1)
+	return &defaultMinAvailable, nil, true
+}
 
-			foundWebhookConf.Webhooks = newWebhookConf.Webhooks
-		}).
+// getReplicas returns the configured number of validator replicas,
+// or the default if it is not set in the SSP CR. One replica is used by default
+// on single node clusters. If the validator is autoscaled, the minimal number
+// of replicas is returned.
+func getReplicas(request *common.Request) int32 {
+	if autoscalingConfig := request.Instance.Spec.TemplateValidator.Autoscaling; autoscalingConfig != nil {
+		return pointer.Int32PtrDerefOr(autoscalingConfig.MinReplicas, 1)
+	}
+	if replicas := request.Instance.Spec.TemplateValidator.Replicas; replicas != nil {
+		return *replicas
+	}
+	if request.SingleNode {
+		return 1
+	}
+	return defaultTemplateValidatorReplicas
+}
+
+// getTopologySpreadConstraints returns the configured topology spread constraints.
+// If none are configured and more than one replica is requested,
+// the validator pods are spread across nodes when possible.
+func getTopologySpreadConstraints(request *common.Request, replicas int32) []v1.TopologySpreadConstraint {
+	if constraints := request.Instance.Spec.TemplateValidator.TopologySpreadConstraints; len(constraints) > 0 {
+		return constraints
+	}
+	if replicas <= 1 {
+		return nil
+	}
+	return []v1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       v1.LabelHostname,
+		WhenUnsatisfiable: v1.ScheduleAnyway,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: commonLabels(),
+		},
+	}}
+}
+
+// getPlacement returns the validator placement, falling back
+// to the infra placement if the validator does not define one.
+func getPlacement(request *common.Request) *lifecycleapi.NodePlacement {
+	if placement := request.Instance.Spec.TemplateValidator.Placement; placement != nil {
+		return placement
+	}
+	return request.Instance.Spec.Infra.NodePlacement
+}
+
+func reconcileValidatingWebhook(request *common.Request) (common.ResourceStatus, error) {
+	caBundle, err := getManagedCABundle(request)
+	if err != nil {
+		return common.ResourceStatus{}, err
+	}
+	webhookConf := newValidatingWebhook(request.Namespace)
+	if caBundle != nil {
+		delete(webhookConf.Annotations, injectCABundleAnnotation)
+		// The CA bundle changes when the CA is renewed, without a change of the webhook
+		request.VersionCache.RemoveObj(webhookConf)
+	}
+	for i := range webhookConf.Webhooks {
+		webhook := &webhookConf.Webhooks[i]
+		webhook.ClientConfig.CABundle = caBundle
+		if failurePolicy := request.Instance.Spec.TemplateValidator.WebhookFailurePolicy; failurePolicy != nil {
+			webhook.FailurePolicy = failurePolicy
+		}
+		webhook.TimeoutSeconds = request.Instance.Spec.TemplateValidator.WebhookTimeoutSeconds
+		webhook.NamespaceSelector = webhookNamespaceSelector(&request.Instance.Spec)
+		webhook.ObjectSelector = request.Instance.Spec.TemplateValidator.WebhookObjectSelector
+	}
+	return common.CreateOrUpdate(request).
+		ClusterResource(webhookConf).
+		WithAppLabels(operandName, operandComponent).
 		Reconcile()
 }
 
-func copyFoundCaBundles(newWebhooks []admission.ValidatingWebhook, foundWebhooks []admission.ValidatingWebhook) {
-	for i := range newWebhooks {
-		newWebhook := &newWebhooks[i]
-		for j := range foundWebhooks {
-			foundWebhook := &foundWebhooks[j]
-			if newWebhook.Name == foundWebhook.Name {
-				newWebhook.ClientConfig.CABundle = foundWebhook.ClientConfig.CABundle
-				break
-			}
+// webhookNamespaceSelector returns the selector of the namespaces validated by the webhook.
+// It matches the namespaces in spec.namespaces, if any, and the webhook namespace selector.
+// The kube-system namespace is always excluded, so the cluster can recover when the validator is down.
+func webhookNamespaceSelector(spec *ssp.SSPSpec) *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{}
+	if spec.TemplateValidator.WebhookNamespaceSelector != nil {
+		selector = spec.TemplateValidator.WebhookNamespaceSelector.DeepCopy()
+	}
+	if len(spec.Namespaces) > 0 {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      namespaceNameLabel,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   spec.Namespaces,
+		})
+	}
+	selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+		Key:      namespaceNameLabel,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{metav1.NamespaceSystem},
+	})
+	return selector
+}
+
+// reconcileNamespaceNameLabels sets the namespace name label on kube-system and on the namespaces
+// in spec.namespaces, so the webhook namespace selector matches them. Kubernetes sets the label
+// on all namespaces only since version 1.21. Namespaces that do not exist yet are labeled once they are created.
+func reconcileNamespaceNameLabels(request *common.Request) error {
+	names := append([]string{metav1.NamespaceSystem}, request.Instance.Spec.Namespaces...)
+	for _, name := range names {
+		namespace := &v1.Namespace{}
+		err := request.Client.Get(request.Context, client.ObjectKey{Name: name}, namespace)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if namespace.Labels[namespaceNameLabel] == name {
+			continue
+		}
+
+		patch := client.MergeFrom(namespace.DeepCopy())
+		if namespace.Labels == nil {
+			namespace.Labels = map[string]string{}
+		}
+		namespace.Labels[namespaceNameLabel] = name
+		err = request.Client.Patch(request.Context, namespace, patch)
+		if err != nil {
+			request.Logger.Error(err, fmt.Sprintf("Error labeling namespace \"%s\": %s", name, err))
+			return err
 		}
 	}
+	return nil
 }
@@ -2,20 +2,32 @@ package template_validator
 
 import (
 	"context"
+	"crypto/x509"
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	ocpv1 "github.com/openshift/api/config/v1"
 	admission "k8s.io/api/admissionregistration/v1"
 	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
 	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/cert"
 	"k8s.io/utils/pointer"
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 	. "kubevirt.io/ssp-operator/internal/test-utils"
+	fake "kubevirt.io/ssp-operator/internal/test-utils/fake-client"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -25,6 +37,9 @@ import (
 
 var log = logf.Log.WithName("validator_operand")
 
+// tlsValidatorImage is a validator version that is passed the TLS flags
+const tlsValidatorImage = "quay.io/kubevirt/kubevirt-template-validator:v0.10.0"
+
 var _ = Describe("Template validator operand", func() {
 	const (
 		namespace       = "kubevirt"
@@ -68,7 +83,7 @@ var _ = Describe("Template validator operand", func() {
 				},
 			},
 			Logger:       log,
-			VersionCache: common.VersionCache{},
+			VersionCache: common.NewVersionCache(),
 		}
 	})
 
@@ -80,7 +95,7 @@ var _ = Describe("Template validator operand", func() {
 		ExpectResourceExists(newServiceAccount(namespace), request)
 		ExpectResourceExists(newClusterRoleBinding(namespace), request)
 		ExpectResourceExists(newService(namespace), request)
-		ExpectResourceExists(newDeployment(namespace, replicas, "test-img"), request)
+		ExpectResourceExists(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity), request)
 		ExpectResourceExists(newValidatingWebhook(namespace), request)
 	})
 
@@ -105,6 +120,123 @@ var _ = Describe("Template validator operand", func() {
 		Expect(updatedWebhook.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte(testCaBundle)))
 	})
 
+	It("should use Fail webhook failure policy by default", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newValidatingWebhook(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		webhook := &admission.ValidatingWebhookConfiguration{}
+		Expect(request.Client.Get(request.Context, key, webhook)).ToNot(HaveOccurred())
+		Expect(*webhook.Webhooks[0].FailurePolicy).To(Equal(admission.Fail))
+		Expect(webhook.Webhooks[0].TimeoutSeconds).To(BeNil())
+	})
+
+	It("should set configured webhook failure policy and timeout", func() {
+		ignore := admission.Ignore
+		request.Instance.Spec.TemplateValidator.WebhookFailurePolicy = &ignore
+		request.Instance.Spec.TemplateValidator.WebhookTimeoutSeconds = pointer.Int32Ptr(5)
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newValidatingWebhook(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		webhook := &admission.ValidatingWebhookConfiguration{}
+		Expect(request.Client.Get(request.Context, key, webhook)).ToNot(HaveOccurred())
+		Expect(*webhook.Webhooks[0].FailurePolicy).To(Equal(admission.Ignore))
+		Expect(*webhook.Webhooks[0].TimeoutSeconds).To(Equal(int32(5)))
+	})
+
+	It("should restrict webhook to configured namespaces", func() {
+		request.Instance.Spec.Namespaces = []string{"team-a", "team-b"}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newValidatingWebhook(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		webhook := &admission.ValidatingWebhookConfiguration{}
+		Expect(request.Client.Get(request.Context, key, webhook)).ToNot(HaveOccurred())
+		Expect(webhook.Webhooks[0].NamespaceSelector).To(Equal(&meta.LabelSelector{
+			MatchExpressions: []meta.LabelSelectorRequirement{{
+				Key:      "kubernetes.io/metadata.name",
+				Operator: meta.LabelSelectorOpIn,
+				Values:   []string{"team-a", "team-b"},
+			}, {
+				Key:      "kubernetes.io/metadata.name",
+				Operator: meta.LabelSelectorOpNotIn,
+				Values:   []string{"kube-system"},
+			}},
+		}))
+	})
+
+	It("should exclude kube-system from the webhook", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newValidatingWebhook(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		webhook := &admission.ValidatingWebhookConfiguration{}
+		Expect(request.Client.Get(request.Context, key, webhook)).ToNot(HaveOccurred())
+		Expect(webhook.Webhooks[0].NamespaceSelector).To(Equal(&meta.LabelSelector{
+			MatchExpressions: []meta.LabelSelectorRequirement{{
+				Key:      "kubernetes.io/metadata.name",
+				Operator: meta.LabelSelectorOpNotIn,
+				Values:   []string{"kube-system"},
+			}},
+		}))
+		Expect(webhook.Webhooks[0].ObjectSelector).To(BeNil())
+	})
+
+	It("should set namespace name label on namespaces matched by the webhook", func() {
+		for _, name := range []string{"kube-system", "team-a"} {
+			Expect(request.Client.Create(request.Context, &core.Namespace{
+				ObjectMeta: meta.ObjectMeta{Name: name},
+			})).To(Succeed())
+		}
+		request.Instance.Spec.Namespaces = []string{"team-a", "team-b"}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, name := range []string{"kube-system", "team-a"} {
+			namespace := &core.Namespace{}
+			Expect(request.Client.Get(request.Context, client.ObjectKey{Name: name}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("kubernetes.io/metadata.name", name))
+		}
+	})
+
+	It("should set configured webhook selectors", func() {
+		request.Instance.Spec.TemplateValidator.WebhookNamespaceSelector = &meta.LabelSelector{
+			MatchLabels: map[string]string{"validate-vms": "true"},
+		}
+		request.Instance.Spec.TemplateValidator.WebhookObjectSelector = &meta.LabelSelector{
+			MatchExpressions: []meta.LabelSelectorRequirement{{
+				Key:      "skip-validation",
+				Operator: meta.LabelSelectorOpDoesNotExist,
+			}},
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newValidatingWebhook(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		webhook := &admission.ValidatingWebhookConfiguration{}
+		Expect(request.Client.Get(request.Context, key, webhook)).ToNot(HaveOccurred())
+		Expect(webhook.Webhooks[0].NamespaceSelector).To(Equal(&meta.LabelSelector{
+			MatchLabels: map[string]string{"validate-vms": "true"},
+			MatchExpressions: []meta.LabelSelectorRequirement{{
+				Key:      "kubernetes.io/metadata.name",
+				Operator: meta.LabelSelectorOpNotIn,
+				Values:   []string{"kube-system"},
+			}},
+		}))
+		Expect(webhook.Webhooks[0].ObjectSelector).To(Equal(request.Instance.Spec.TemplateValidator.WebhookObjectSelector))
+		Expect(request.Instance.Spec.TemplateValidator.WebhookNamespaceSelector.MatchExpressions).To(BeEmpty())
+	})
+
 	It("should not update service cluster IP", func() {
 		_, err := operand.Reconcile(&request)
 		Expect(err).ToNot(HaveOccurred())
@@ -126,19 +258,1049 @@ var _ = Describe("Template validator operand", func() {
 		Expect(updatedService.Spec.ClusterIP).To(Equal(testClusterIp))
 	})
 
-	It("should remove cluster resources on cleanup", func() {
+	It("should add configured service metadata", func() {
+		request.Instance.Spec.TemplateValidator.ServiceMetadata = &ssp.ServiceMetadata{
+			Labels: map[string]string{
+				"mesh":                        "enabled",
+				common.AppKubernetesNameLabel: "custom-name",
+			},
+			Annotations: map[string]string{
+				"metallb.universe.tf/address-pool": "internal",
+			},
+		}
+
 		_, err := operand.Reconcile(&request)
 		Expect(err).ToNot(HaveOccurred())
 
-		ExpectResourceExists(newClusterRole(), request)
-		ExpectResourceExists(newClusterRoleBinding(namespace), request)
-		ExpectResourceExists(newValidatingWebhook(namespace), request)
+		key, err := client.ObjectKeyFromObject(newService(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		service := &core.Service{}
+		Expect(request.Client.Get(request.Context, key, service)).ToNot(HaveOccurred())
+		Expect(service.Labels).To(HaveKeyWithValue("mesh", "enabled"))
+		Expect(service.Labels).To(HaveKeyWithValue(common.AppKubernetesNameLabel, operandName))
+		Expect(service.Annotations).To(HaveKeyWithValue("metallb.universe.tf/address-pool", "internal"))
+		Expect(service.Annotations).To(HaveKeyWithValue(servingCertSecretAnnotation, secretName))
+	})
+
+	It("should keep service annotations added by other controllers", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newService(namespace))
+		Expect(err).ToNot(HaveOccurred())
+		service := &core.Service{}
+		Expect(request.Client.Get(request.Context, key, service)).ToNot(HaveOccurred())
+		service.Annotations["sidecar.istio.io/inject"] = "true"
+		Expect(request.Client.Update(request.Context, service)).ToNot(HaveOccurred())
+
+		request.Instance.Spec.TemplateValidator.ServiceMetadata = &ssp.ServiceMetadata{
+			Annotations: map[string]string{"metallb.universe.tf/address-pool": "internal"},
+		}
+		_, err = operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		updatedService := &core.Service{}
+		Expect(request.Client.Get(request.Context, key, updatedService)).ToNot(HaveOccurred())
+		Expect(updatedService.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "true"))
+		Expect(updatedService.Annotations).To(HaveKeyWithValue("metallb.universe.tf/address-pool", "internal"))
+	})
+
+	It("should keep deployment metadata added by users", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+		deployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, deployment)).ToNot(HaveOccurred())
+		deployment.Labels["user-label"] = "user-value"
+		deployment.Labels["name"] = "changed"
+		deployment.Annotations = map[string]string{"user-annotation": "user-value"}
+		deployment.Spec.Template.Labels["user-pod-label"] = "user-value"
+		deployment.Spec.Template.Annotations = map[string]string{"user-pod-annotation": "user-value"}
+		Expect(request.Client.Update(request.Context, deployment)).ToNot(HaveOccurred())
+
+		_, err = operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		updatedDeployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, updatedDeployment)).ToNot(HaveOccurred())
+		Expect(updatedDeployment.Labels).To(HaveKeyWithValue("user-label", "user-value"))
+		Expect(updatedDeployment.Labels).To(HaveKeyWithValue("name", virtTemplateValidator))
+		Expect(updatedDeployment.Annotations).To(HaveKeyWithValue("user-annotation", "user-value"))
+		Expect(updatedDeployment.Spec.Template.Labels).To(HaveKeyWithValue("user-pod-label", "user-value"))
+		Expect(updatedDeployment.Spec.Template.Labels).To(HaveKeyWithValue(kubevirtIo, virtTemplateValidator))
+		Expect(updatedDeployment.Spec.Template.Annotations).To(HaveKeyWithValue("user-pod-annotation", "user-value"))
+	})
+
+	It("should use default replicas if not set", func() {
+		request.Instance.Spec.TemplateValidator.Replicas = nil
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, deployment)).ToNot(HaveOccurred())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(defaultTemplateValidatorReplicas)))
+	})
+
+	It("should use one replica by default on single node cluster", func() {
+		request.Instance.Spec.TemplateValidator.Replicas = nil
+		request.SingleNode = true
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, deployment)).ToNot(HaveOccurred())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+	})
+
+	It("should set placement on deployment", func() {
+		placement := &lifecycleapi.NodePlacement{
+			NodeSelector: map[string]string{
+				"testKey": "testValue",
+			},
+			Tolerations: []core.Toleration{{
+				Key:      "testKey",
+				Operator: core.TolerationOpExists,
+				Effect:   core.TaintEffectNoExecute,
+			}},
+		}
+		request.Instance.Spec.TemplateValidator.Placement = placement
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, deployment)).ToNot(HaveOccurred())
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(placement.NodeSelector))
+		Expect(podSpec.Tolerations).To(Equal(placement.Tolerations))
+		Expect(podSpec.Affinity).To(BeNil())
+	})
+
+	It("should use infra placement if operand placement is not set", func() {
+		placement := &lifecycleapi.NodePlacement{
+			NodeSelector: map[string]string{
+				"testKey": "testValue",
+			},
+		}
+		request.Instance.Spec.Infra.NodePlacement = placement
+		request.Instance.Spec.Workloads.NodePlacement = &lifecycleapi.NodePlacement{
+			NodeSelector: map[string]string{
+				"otherKey": "otherValue",
+			},
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.NodeSelector).To(Equal(placement.NodeSelector))
+	})
+
+	It("should override security context on deployment", func() {
+		podSecurityContext := &core.PodSecurityContext{
+			RunAsNonRoot: pointer.BoolPtr(true),
+			SeccompProfile: &core.SeccompProfile{
+				Type: core.SeccompProfileTypeRuntimeDefault,
+			},
+		}
+		request.Instance.Spec.TemplateValidator.PodSecurityContext = podSecurityContext
+		request.Instance.Spec.TemplateValidator.SecurityContext = &core.SecurityContext{
+			RunAsUser:                pointer.Int64Ptr(1000),
+			AllowPrivilegeEscalation: pointer.BoolPtr(false),
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, deployment)).ToNot(HaveOccurred())
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.SecurityContext).To(Equal(podSecurityContext))
+		Expect(podSpec.Containers[0].SecurityContext).To(Equal(&core.SecurityContext{
+			RunAsUser:                pointer.Int64Ptr(1000),
+			ReadOnlyRootFilesystem:   pointer.BoolPtr(true),
+			AllowPrivilegeEscalation: pointer.BoolPtr(false),
+		}))
+	})
+
+	It("should set resource requirements on deployment", func() {
+		resources := &core.ResourceRequirements{
+			Requests: core.ResourceList{
+				core.ResourceCPU:    resource.MustParse("100m"),
+				core.ResourceMemory: resource.MustParse("150Mi"),
+			},
+			Limits: core.ResourceList{
+				core.ResourceMemory: resource.MustParse("300Mi"),
+			},
+		}
+		request.Instance.Spec.TemplateValidator.Resources = resources
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, deployment)).ToNot(HaveOccurred())
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			Expect(container.Resources.Requests).To(HaveLen(2))
+			Expect(container.Resources.Requests.Cpu().Equal(resources.Requests[core.ResourceCPU])).To(BeTrue())
+			Expect(container.Resources.Limits.Memory().Equal(resources.Limits[core.ResourceMemory])).To(BeTrue())
+		}
+	})
+
+	It("should set priority class name", func() {
+		const priorityClassName = "system-cluster-critical"
+		request.Instance.Spec.TemplateValidator.PriorityClassName = priorityClassName
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.PriorityClassName).To(Equal(priorityClassName))
+	})
+
+	It("should set runtime class name", func() {
+		const runtimeClassName = "kata"
+		request.Instance.Spec.RuntimeClassName = pointer.StringPtr(runtimeClassName)
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.RuntimeClassName).To(Equal(pointer.StringPtr(runtimeClassName)))
+	})
+
+	It("should spread validator pods across nodes by default", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.TopologySpreadConstraints).To(HaveLen(1))
+		constraint := found.Spec.Template.Spec.TopologySpreadConstraints[0]
+		Expect(constraint.TopologyKey).To(Equal(core.LabelHostname))
+		Expect(constraint.WhenUnsatisfiable).To(Equal(core.ScheduleAnyway))
+		Expect(constraint.LabelSelector.MatchLabels).To(Equal(commonLabels()))
+	})
+
+	It("should not set default topology spread constraints for a single replica", func() {
+		request.Instance.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(1)
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+	})
+
+	It("should set configured topology spread constraints", func() {
+		constraints := []core.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: core.DoNotSchedule,
+			LabelSelector: &meta.LabelSelector{
+				MatchLabels: commonLabels(),
+			},
+		}}
+		request.Instance.Spec.TemplateValidator.TopologySpreadConstraints = constraints
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.TopologySpreadConstraints).To(Equal(constraints))
+	})
+
+	It("should set configured rolling update", func() {
+		maxUnavailable := intstr.FromInt(1)
+		maxSurge := intstr.FromInt(0)
+		request.Instance.Spec.TemplateValidator.RollingUpdate = &apps.RollingUpdateDeployment{
+			MaxUnavailable: &maxUnavailable,
+			MaxSurge:       &maxSurge,
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Strategy.Type).To(Equal(apps.RollingUpdateDeploymentStrategyType))
+		Expect(found.Spec.Strategy.RollingUpdate).To(Equal(request.Instance.Spec.TemplateValidator.RollingUpdate))
+	})
+
+	Context("autoscaling", func() {
+		getAutoscaler := func() *autoscaling.HorizontalPodAutoscaler {
+			key, err := client.ObjectKeyFromObject(newHorizontalPodAutoscaler(namespace, nil, 0, nil))
+			Expect(err).ToNot(HaveOccurred())
+			found := &autoscaling.HorizontalPodAutoscaler{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			return found
+		}
+
+		It("should not create autoscaler by default", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceNotExists(newHorizontalPodAutoscaler(namespace, nil, 0, nil), request)
+		})
+
+		It("should create autoscaler with default CPU target", func() {
+			request.Instance.Spec.TemplateValidator.Autoscaling = &ssp.Autoscaling{
+				MinReplicas: pointer.Int32Ptr(2),
+				MaxReplicas: 5,
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			hpa := getAutoscaler()
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+			Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(DeploymentName))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Resource.Name).To(Equal(core.ResourceCPU))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(defaultTargetCPUUtilization)))
+		})
+
+		It("should use custom metrics", func() {
+			averageValue := resource.MustParse("50")
+			customMetric := autoscaling.MetricSpec{
+				Type: autoscaling.PodsMetricSourceType,
+				Pods: &autoscaling.PodsMetricSource{
+					Metric: autoscaling.MetricIdentifier{Name: "admission_requests_per_second"},
+					Target: autoscaling.MetricTarget{
+						Type:         autoscaling.AverageValueMetricType,
+						AverageValue: &averageValue,
+					},
+				},
+			}
+			request.Instance.Spec.TemplateValidator.Autoscaling = &ssp.Autoscaling{
+				MaxReplicas: 5,
+				Metrics:     []autoscaling.MetricSpec{customMetric},
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(getAutoscaler().Spec.Metrics).To(Equal([]autoscaling.MetricSpec{customMetric}))
+		})
+
+		It("should not override replicas set by the autoscaler", func() {
+			request.Instance.Spec.TemplateValidator.Autoscaling = &ssp.Autoscaling{
+				MaxReplicas: 5,
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+			Expect(err).ToNot(HaveOccurred())
+			updateDeployment(key, &request, func(deployment *apps.Deployment) {
+				deployment.Spec.Replicas = pointer.Int32Ptr(4)
+			})
+
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &apps.Deployment{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			Expect(*found.Spec.Replicas).To(Equal(int32(4)))
+		})
+
+		It("should not apply replicas when the deployment changes", func() {
+			request.Instance.Spec.TemplateValidator.Autoscaling = &ssp.Autoscaling{
+				MaxReplicas: 5,
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+			Expect(err).ToNot(HaveOccurred())
+			found := &apps.Deployment{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			Expect(found.Spec.Replicas).To(BeNil())
+
+			updateDeployment(key, &request, func(deployment *apps.Deployment) {
+				deployment.Spec.Replicas = pointer.Int32Ptr(4)
+			})
+
+			request.Instance.Spec.TemplateValidator.LogVerbosity = pointer.Int32Ptr(defaultLogVerbosity + 1)
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement(fmt.Sprintf("-v=%d", defaultLogVerbosity+1)))
+			Expect(*found.Spec.Replicas).To(Equal(int32(4)))
+		})
+
+		It("should remove autoscaler when autoscaling is removed", func() {
+			request.Instance.Spec.TemplateValidator.Autoscaling = &ssp.Autoscaling{
+				MaxReplicas: 5,
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceExists(newHorizontalPodAutoscaler(namespace, nil, 0, nil), request)
+
+			request.Instance.Spec.TemplateValidator.Autoscaling = nil
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceNotExists(newHorizontalPodAutoscaler(namespace, nil, 0, nil), request)
+		})
+	})
+
+	Context("version", func() {
+		const imageID = "quay.io/kubevirt/kubevirt-template-validator@sha256:0123456789abcdef"
+
+		newPod := func(name string, imageID string) *core.Pod {
+			return &core.Pod{
+				ObjectMeta: meta.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    commonLabels(),
+				},
+				Status: core.PodStatus{
+					Phase: core.PodRunning,
+					ContainerStatuses: []core.ContainerStatus{{
+						Name:    webhookContainerName,
+						Image:   getTemplateValidatorImage(),
+						ImageID: imageID,
+					}},
+				},
+			}
+		}
+
+		It("should report image ID of running pods", func() {
+			Expect(request.Client.Create(request.Context, newPod("validator-1", "docker-pullable://"+imageID))).To(Succeed())
+			Expect(request.Client.Create(request.Context, newPod("validator-2", imageID))).To(Succeed())
+
+			Expect(operand.Version(&request)).To(Equal(imageID))
+		})
+
+		It("should report configured image if no pod is running", func() {
+			pod := newPod("validator-1", imageID)
+			pod.Status.Phase = core.PodPending
+			Expect(request.Client.Create(request.Context, pod)).To(Succeed())
+
+			Expect(operand.Version(&request)).To(Equal(getTemplateValidatorImage()))
+		})
+
+		It("should report configured image if pods run different images", func() {
+			Expect(request.Client.Create(request.Context, newPod("validator-1", imageID))).To(Succeed())
+			Expect(request.Client.Create(request.Context, newPod("validator-2", "quay.io/kubevirt/kubevirt-template-validator@sha256:fedcba9876543210"))).To(Succeed())
+
+			Expect(operand.Version(&request)).To(Equal(getTemplateValidatorImage()))
+		})
+	})
+
+	Context("pod disruption budget", func() {
+		getPodDisruptionBudget := func() *policy.PodDisruptionBudget {
+			key, err := client.ObjectKeyFromObject(newPodDisruptionBudget(namespace, nil, nil))
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &policy.PodDisruptionBudget{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			return found
+		}
+
+		It("should create default pod disruption budget", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			pdb := getPodDisruptionBudget()
+			Expect(pdb.Spec.MinAvailable).To(Equal(&intstr.IntOrString{Type: intstr.Int, IntVal: 1}))
+			Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(commonLabels()))
+		})
+
+		It("should not create default pod disruption budget for a single replica", func() {
+			request.Instance.Spec.TemplateValidator.Replicas = pointer.Int32Ptr(1)
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceNotExists(newPodDisruptionBudget(namespace, nil, nil), request)
+		})
+
+		It("should not create default pod disruption budget on single node cluster", func() {
+			request.SingleNode = true
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceNotExists(newPodDisruptionBudget(namespace, nil, nil), request)
+		})
+
+		It("should create configured pod disruption budget on single node cluster", func() {
+			request.SingleNode = true
+			minAvailable := intstr.FromInt(1)
+			request.Instance.Spec.TemplateValidator.PodDisruptionBudget = &ssp.PodDisruptionBudget{
+				MinAvailable: &minAvailable,
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getPodDisruptionBudget().Spec.MinAvailable).To(Equal(&minAvailable))
+		})
+
+		It("should set configured maxUnavailable", func() {
+			maxUnavailable := intstr.FromString("50%")
+			request.Instance.Spec.TemplateValidator.PodDisruptionBudget = &ssp.PodDisruptionBudget{
+				MaxUnavailable: &maxUnavailable,
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			pdb := getPodDisruptionBudget()
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.MaxUnavailable).To(Equal(&maxUnavailable))
+		})
+
+		It("should remove pod disruption budget when disabled", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceExists(newPodDisruptionBudget(namespace, nil, nil), request)
+
+			request.Instance.Spec.TemplateValidator.PodDisruptionBudget = &ssp.PodDisruptionBudget{
+				Enabled: pointer.BoolPtr(false),
+			}
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceNotExists(newPodDisruptionBudget(namespace, nil, nil), request)
+		})
+	})
+
+	It("should override image and set pull secrets", func() {
+		const image = "mirror.example.com/kubevirt/template-validator:test"
+		pullSecrets := []core.LocalObjectReference{{Name: "mirror-pull-secret"}}
+		request.Instance.Spec.TemplateValidator.Image = image
+		request.Instance.Spec.TemplateValidator.ImagePullSecrets = pullSecrets
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+		Expect(found.Spec.Template.Spec.ImagePullSecrets).To(Equal(pullSecrets))
+	})
+
+	It("should set configured log verbosity", func() {
+		request.Instance.Spec.TemplateValidator.LogVerbosity = pointer.Int32Ptr(5)
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-v=5"))
+		Expect(found.Spec.Template.Spec.Containers[0].Args).ToNot(ContainElement("-v=2"))
+	})
+
+	Context("proxy", func() {
+		getContainerEnv := func() []core.EnvVar {
+			key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+			Expect(err).ToNot(HaveOccurred())
+
+			found := &apps.Deployment{}
+			Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+			return found.Spec.Template.Spec.Containers[0].Env
+		}
+
+		It("should not set proxy environment by default", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getContainerEnv()).To(BeEmpty())
+		})
+
+		It("should set proxy environment from the cluster proxy", func() {
+			request.ClusterProxy = &ssp.Proxy{
+				HTTPProxy: "http://cluster-proxy.example.com:3128",
+				NoProxy:   ".cluster.local",
+			}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getContainerEnv()).To(ConsistOf(
+				core.EnvVar{Name: "HTTP_PROXY", Value: "http://cluster-proxy.example.com:3128"},
+				core.EnvVar{Name: "http_proxy", Value: "http://cluster-proxy.example.com:3128"},
+				core.EnvVar{Name: "NO_PROXY", Value: ".cluster.local"},
+				core.EnvVar{Name: "no_proxy", Value: ".cluster.local"},
+			))
+		})
+
+		It("should prefer the configured proxy over the cluster proxy", func() {
+			request.ClusterProxy = &ssp.Proxy{HTTPProxy: "http://cluster-proxy.example.com:3128"}
+			request.Instance.Spec.Proxy = &ssp.Proxy{HTTPSProxy: "https://proxy.example.com:3129"}
+
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getContainerEnv()).To(ConsistOf(
+				core.EnvVar{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3129"},
+				core.EnvVar{Name: "https_proxy", Value: "https://proxy.example.com:3129"},
+			))
+		})
+	})
+
+	It("should mount trusted CA bundle", func() {
+		request.Instance.Spec.TrustedCABundle = &ssp.TrustedCABundle{Name: "internal-ca"}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+
+		podSpec := found.Spec.Template.Spec
+		Expect(podSpec.Volumes).To(ContainElement(core.Volume{
+			Name: "trusted-ca-bundle",
+			VolumeSource: core.VolumeSource{
+				ConfigMap: &core.ConfigMapVolumeSource{
+					LocalObjectReference: core.LocalObjectReference{Name: "internal-ca"},
+					Items:                []core.KeyToPath{{Key: ssp.DefaultTrustedCABundleKey, Path: "tls-ca-bundle.pem"}},
+				},
+			},
+		}))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(core.VolumeMount{
+			Name:      "trusted-ca-bundle",
+			MountPath: "/etc/pki/ca-trust/extracted/pem",
+			ReadOnly:  true,
+		}))
+	})
+
+	It("should use intermediate TLS profile by default", func() {
+		request.Instance.Spec.TemplateValidator.Image = tlsValidatorImage
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--tls-min-version=VersionTLS12"))
+		Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement(
+			ContainSubstring("--tls-cipher-suites=TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,")))
+	})
+
+	It("should set custom TLS profile", func() {
+		request.Instance.Spec.TemplateValidator.Image = tlsValidatorImage
+		request.Instance.Spec.TLSSecurityProfile = &ocpv1.TLSSecurityProfile{
+			Type: ocpv1.TLSProfileCustomType,
+			Custom: &ocpv1.CustomTLSProfile{
+				TLSProfileSpec: ocpv1.TLSProfileSpec{
+					Ciphers:       []string{"ECDHE-RSA-AES128-GCM-SHA256", "DHE-RSA-AES256-GCM-SHA384"},
+					MinTLSVersion: ocpv1.VersionTLS12,
+				},
+			},
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--tls-min-version=VersionTLS12"))
+		Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--tls-cipher-suites=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"))
+	})
+
+	It("should not set cipher suites for modern TLS profile", func() {
+		request.Instance.Spec.TemplateValidator.Image = tlsValidatorImage
+		request.Instance.Spec.TLSSecurityProfile = &ocpv1.TLSSecurityProfile{
+			Type:   ocpv1.TLSProfileModernType,
+			Modern: &ocpv1.ModernTLSProfile{},
+		}
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--tls-min-version=VersionTLS13"))
+		Expect(found.Spec.Template.Spec.Containers[0].Args).ToNot(ContainElement(HavePrefix("--tls-cipher-suites=")))
+	})
+
+	It("should not pass TLS flags to older validator images", func() {
+		request.Instance.Spec.TemplateValidator.Image = "quay.io/kubevirt/kubevirt-template-validator:v0.9.0"
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+		Expect(err).ToNot(HaveOccurred())
+
+		found := &apps.Deployment{}
+		Expect(request.Client.Get(request.Context, key, found)).ToNot(HaveOccurred())
+		Expect(found.Spec.Template.Spec.Containers[0].Args).ToNot(ContainElement(HavePrefix("--tls-")))
+	})
+
+	Context("TLS profile of older validator images", func() {
+		deploymentStatus := func() common.ResourceStatus {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
+			Expect(err).ToNot(HaveOccurred())
+			updateDeployment(key, &request, func(deployment *apps.Deployment) {
+				deployment.Status.Replicas = replicas
+				deployment.Status.ReadyReplicas = replicas
+				deployment.Status.AvailableReplicas = replicas
+				deployment.Status.UpdatedReplicas = replicas
+			})
+
+			statuses, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			for _, status := range statuses {
+				if _, ok := status.Resource.(*apps.Deployment); ok {
+					return status
+				}
+			}
+			Fail("missing status of the validator deployment")
+			return common.ResourceStatus{}
+		}
+
+		BeforeEach(func() {
+			request.Instance.Spec.TemplateValidator.Image = "quay.io/kubevirt/kubevirt-template-validator:v0.10.0-rc.1"
+		})
+
+		It("should mark the deployment as degraded if the profile is set", func() {
+			request.Instance.Spec.TLSSecurityProfile = &ocpv1.TLSSecurityProfile{
+				Type:   ocpv1.TLSProfileModernType,
+				Modern: &ocpv1.ModernTLSProfile{},
+			}
+
+			status := deploymentStatus()
+			Expect(status.Degraded).ToNot(BeNil())
+			Expect(*status.Degraded).To(ContainSubstring("TLS security profile is not applied"))
+			Expect(status.NotAvailable).To(BeNil())
+		})
+
+		It("should not mark the deployment as degraded if the profile is not set", func() {
+			status := deploymentStatus()
+			Expect(status.Degraded).To(BeNil())
+		})
+	})
+
+	table.DescribeTable("image version comparison", func(image string, expected bool) {
+		Expect(imageVersionAtLeast(image, tlsFlagsMinVersion)).To(Equal(expected))
+	},
+		table.Entry("same version", "quay.io/kubevirt/kubevirt-template-validator:v0.10.0", true),
+		table.Entry("newer version", "quay.io/kubevirt/kubevirt-template-validator:v1.2.3", true),
+		table.Entry("newer patch version without prefix", "registry:5000/validator:0.10.1", true),
+		table.Entry("pre-release of the version", "quay.io/kubevirt/kubevirt-template-validator:v0.10.0-rc.1", false),
+		table.Entry("pre-release of a newer version", "quay.io/kubevirt/kubevirt-template-validator:v0.11.0-rc.1", true),
+		table.Entry("version with build metadata", "quay.io/kubevirt/kubevirt-template-validator:v0.10.0+build.1", true),
+		table.Entry("older version", "quay.io/kubevirt/kubevirt-template-validator:v0.9.0", false),
+		table.Entry("tag without version", "quay.io/kubevirt/kubevirt-template-validator:latest", false),
+		table.Entry("image without tag", "registry:5000/validator", false),
+		table.Entry("image with digest only", "quay.io/kubevirt/kubevirt-template-validator@sha256:"+
+			"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", false),
+	)
+
+	It("should remove rules ConfigMap of previous versions", func() {
+		Expect(request.Client.Create(request.Context, newRulesConfigMap(namespace))).To(Succeed())
+
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+		ExpectResourceNotExists(newRulesConfigMap(namespace), request)
+	})
+
+	Context("managed certificates", func() {
+		var currentTime time.Time
+
+		BeforeEach(func() {
+			currentTime = time.Now()
+			now = func() time.Time { return currentTime }
+			request.Instance.Spec.CertConfig = &ssp.CertConfig{}
+			// The validator serves the certificate from the secret
+			probeServingCertificate = func(string, *x509.CertPool, string) (*x509.Certificate, error) {
+				secret, err := getSecret(&request, secretName)
+				if err != nil {
+					return nil, err
+				}
+				servingCert, _, err := parseKeyPair(secret)
+				return servingCert, err
+			}
+		})
+
+		AfterEach(func() {
+			now = time.Now
+			probeServingCertificate = defaultProbeServingCertificate
+		})
+
+		// progressingMessages returns the progressing messages of the certificate secrets
+		progressingMessages := func(statuses []common.ResourceStatus) []string {
+			var messages []string
+			for _, status := range statuses {
+				if _, isSecret := status.Resource.(*core.Secret); isSecret && status.Progressing != nil {
+					messages = append(messages, *status.Progressing)
+				}
+			}
+			return messages
+		}
+
+		getSecretCert := func(name string) *x509.Certificate {
+			secret, err := getSecret(&request, name)
+			Expect(err).ToNot(HaveOccurred())
+			certificate, _, err := parseKeyPair(secret)
+			Expect(err).ToNot(HaveOccurred())
+			return certificate
+		}
+
+		getCABundle := func() []*x509.Certificate {
+			secret, err := getSecret(&request, CASecretName)
+			Expect(err).ToNot(HaveOccurred())
+			certs, err := cert.ParseCertsPEM(secret.Data[caBundleKey])
+			Expect(err).ToNot(HaveOccurred())
+			return certs
+		}
+
+		It("should issue certificates trusted by the webhook", func() {
+			statuses, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			caSecret, err := getSecret(&request, CASecretName)
+			Expect(err).ToNot(HaveOccurred())
+			roots := x509.NewCertPool()
+			Expect(roots.AppendCertsFromPEM(caSecret.Data[caBundleKey])).To(BeTrue())
+			_, err = getSecretCert(secretName).Verify(x509.VerifyOptions{
+				DNSName:     ServiceName + "." + namespace + ".svc",
+				Roots:       roots,
+				CurrentTime: currentTime,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			key, err := client.ObjectKeyFromObject(newValidatingWebhook(namespace))
+			Expect(err).ToNot(HaveOccurred())
+			webhook := &admission.ValidatingWebhookConfiguration{}
+			Expect(request.Client.Get(request.Context, key, webhook)).ToNot(HaveOccurred())
+			Expect(webhook.Webhooks[0].ClientConfig.CABundle).To(Equal(caSecret.Data[caBundleKey]))
+			Expect(webhook.Annotations).ToNot(HaveKey(injectCABundleAnnotation))
+
+			key, err = client.ObjectKeyFromObject(newService(namespace))
+			Expect(err).ToNot(HaveOccurred())
+			service := &core.Service{}
+			Expect(request.Client.Get(request.Context, key, service)).ToNot(HaveOccurred())
+			Expect(service.Annotations).ToNot(HaveKey(servingCertSecretAnnotation))
+
+			var requeueAfter time.Duration
+			for _, status := range statuses {
+				if status.RequeueAfter > 0 {
+					requeueAfter = status.RequeueAfter
+				}
+			}
+			// Certificate validity has a precision of seconds
+			Expect(requeueAfter).To(BeNumerically("~", ssp.DefaultCertRotateInterval*4/5, time.Second))
+		})
+
+		It("should renew serving certificate", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			caCert := getSecretCert(CASecretName)
+			servingCert := getSecretCert(secretName)
+
+			currentTime = currentTime.Add(20 * time.Hour)
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(getSecretCert(CASecretName).Equal(caCert)).To(BeTrue())
+			renewedCert := getSecretCert(secretName)
+			Expect(renewedCert.Equal(servingCert)).To(BeFalse())
+			Expect(renewedCert.NotBefore).To(BeTemporally("~", currentTime, time.Second))
+		})
+
+		It("should renew CA and keep previous CA in the bundle", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			caCert := getSecretCert(CASecretName)
+
+			currentTime = currentTime.Add(ssp.DefaultCARotateInterval - ssp.DefaultCAOverlapInterval)
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			renewedCA := getSecretCert(CASecretName)
+			Expect(renewedCA.Equal(caCert)).To(BeFalse())
+			Expect(getSecretCert(secretName).CheckSignatureFrom(renewedCA)).To(Succeed())
+
+			bundle := getCABundle()
+			Expect(bundle).To(HaveLen(2))
+			Expect(bundle[0].Equal(renewedCA)).To(BeTrue())
+			Expect(bundle[1].Equal(caCert)).To(BeTrue())
+
+			// The previous CA is removed from the bundle once it expires
+			currentTime = currentTime.Add(ssp.DefaultCAOverlapInterval)
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getCABundle()).To(HaveLen(1))
+		})
+
+		It("should issue serving certificate by renewed CA after the webhook trusts it", func() {
+			request.Instance.Spec.CertConfig = &ssp.CertConfig{
+				CARotateInterval:   &meta.Duration{Duration: 48 * time.Hour},
+				CAOverlapInterval:  &meta.Duration{Duration: 24 * time.Hour},
+				CertRotateInterval: &meta.Duration{Duration: 24 * time.Hour},
+			}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			currentTime = currentTime.Add(20 * time.Hour)
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			servingCert := getSecretCert(secretName)
+
+			currentTime = currentTime.Add(4 * time.Hour)
+			statuses, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			renewedCA := getSecretCert(CASecretName)
+			Expect(getSecretCert(secretName).Equal(servingCert)).To(BeTrue())
+			Expect(progressingMessages(statuses)).To(ContainElement(ContainSubstring("trust the renewed CA")))
+
+			trusted, err := webhookTrustsCA(&request, renewedCA)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trusted).To(BeTrue())
+
+			statuses, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(getSecretCert(secretName).CheckSignatureFrom(renewedCA)).To(Succeed())
+			Expect(progressingMessages(statuses)).To(BeEmpty())
+		})
+
+		It("should be progressing until the validator serves the renewed certificate", func() {
+			probeServingCertificate = func(string, *x509.CertPool, string) (*x509.Certificate, error) {
+				return nil, fmt.Errorf("connection refused")
+			}
+			statuses, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(progressingMessages(statuses)).To(ContainElement(ContainSubstring("connection refused")))
+
+			currentTime = currentTime.Add(servingCertProbeWindow)
+			statuses, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(progressingMessages(statuses)).To(BeEmpty())
+		})
+
+		It("should use configured rotate interval", func() {
+			request.Instance.Spec.CertConfig.CertRotateInterval = &meta.Duration{Duration: time.Hour}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			servingCert := getSecretCert(secretName)
+			Expect(servingCert.NotAfter.Sub(servingCert.NotBefore)).To(Equal(time.Hour))
+		})
+
+		It("should use rotate interval from KubeVirt if certConfig does not set it", func() {
+			request.KubeVirt = &common.KubeVirtConfig{CertRotateInterval: 2 * time.Hour}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			servingCert := getSecretCert(secretName)
+			Expect(servingCert.NotAfter.Sub(servingCert.NotBefore)).To(Equal(2 * time.Hour))
+		})
+
+		It("should prefer rotate interval from certConfig over KubeVirt", func() {
+			request.Instance.Spec.CertConfig.CertRotateInterval = &meta.Duration{Duration: time.Hour}
+			request.KubeVirt = &common.KubeVirtConfig{CertRotateInterval: 2 * time.Hour}
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			servingCert := getSecretCert(secretName)
+			Expect(servingCert.NotAfter.Sub(servingCert.NotBefore)).To(Equal(time.Hour))
+		})
+
+		It("should remove managed certificates when certConfig is removed", func() {
+			_, err := operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+			ExpectResourceExists(newCASecret(namespace, nil), request)
+
+			request.Instance.Spec.CertConfig = nil
+			_, err = operand.Reconcile(&request)
+			Expect(err).ToNot(HaveOccurred())
+
+			ExpectResourceNotExists(newCASecret(namespace, nil), request)
+			ExpectResourceNotExists(newServingSecret(namespace, nil), request)
+		})
+	})
+
+	It("should be enabled by default", func() {
+		Expect(operand.Enabled(&request)).To(BeTrue())
+	})
+
+	It("should be disabled when enabled is false", func() {
+		request.Instance.Spec.TemplateValidator.Enabled = pointer.BoolPtr(false)
+		Expect(operand.Enabled(&request)).To(BeFalse())
+	})
+
+	It("should remove all resources on cleanup", func() {
+		_, err := operand.Reconcile(&request)
+		Expect(err).ToNot(HaveOccurred())
+
+		resources := []controllerutil.Object{
+			newValidatingWebhook(namespace),
+			newDeployment(namespace, replicas, "test-img", defaultLogVerbosity),
+			newService(namespace),
+			newServiceAccount(namespace),
+			newClusterRole(),
+			newClusterRoleBinding(namespace),
+		}
+		for _, resource := range resources {
+			ExpectResourceExists(resource, request)
+		}
 
 		Expect(operand.Cleanup(&request)).ToNot(HaveOccurred())
 
-		ExpectResourceNotExists(newClusterRole(), request)
-		ExpectResourceNotExists(newClusterRoleBinding(namespace), request)
-		ExpectResourceNotExists(newValidatingWebhook(namespace), request)
+		for _, resource := range resources {
+			ExpectResourceNotExists(resource, request)
+		}
 	})
 
 	It("should report status", func() {
@@ -146,7 +1308,7 @@ var _ = Describe("Template validator operand", func() {
 		Expect(err).ToNot(HaveOccurred())
 
 		// Set status for deployment
-		key, _ := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img"))
+		key, _ := client.ObjectKeyFromObject(newDeployment(namespace, replicas, "test-img", defaultLogVerbosity))
 		updateDeployment(key, &request, func(deployment *apps.Deployment) {
 			deployment.Status.Replicas = replicas
 			deployment.Status.ReadyReplicas = 0
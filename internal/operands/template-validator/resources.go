@@ -2,14 +2,20 @@ package template_validator
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/blang/semver"
+	ocpv1 "github.com/openshift/api/config/v1"
 	admission "k8s.io/api/admissionregistration/v1"
 	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
 	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
 	rbac "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	ssp "kubevirt.io/ssp-operator/api/v1beta1"
 	"kubevirt.io/ssp-operator/internal/common"
 )
 
@@ -18,18 +24,33 @@ import (
 //        repository, and import it as a go module
 
 const (
-	containerPort          = 8443
-	kubevirtIo             = "kubevirt.io"
-	secretName             = "virt-template-validator-certs"
-	virtTemplateValidator  = "virt-template-validator"
-	ClusterRoleName        = "template:view"
-	ClusterRoleBindingName = "template-validator"
-	WebhookName            = virtTemplateValidator
-	ServiceAccountName     = "template-validator"
-	ServiceName            = virtTemplateValidator
-	DeploymentName         = virtTemplateValidator
+	containerPort           = 8443
+	kubevirtIo              = "kubevirt.io"
+	secretName              = "virt-template-validator-certs"
+	virtTemplateValidator   = "virt-template-validator"
+	ClusterRoleName         = "template:view"
+	ClusterRoleBindingName  = "template-validator"
+	WebhookName             = virtTemplateValidator
+	ServiceAccountName      = "template-validator"
+	ServiceName             = virtTemplateValidator
+	DeploymentName          = virtTemplateValidator
+	PodDisruptionBudgetName = virtTemplateValidator
+	AutoscalerName          = virtTemplateValidator
+	RulesConfigMapName      = "virt-template-validator-rules"
+
+	servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	injectCABundleAnnotation    = "service.beta.openshift.io/inject-cabundle"
+
+	webhookContainerName = "webhook"
+
+	// namespaceNameLabel is set by Kubernetes on every namespace to its name, since version 1.21.
+	// The operator sets it on the namespaces matched by the webhook on older versions.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
 )
 
+// tlsFlagsMinVersion is the first validator version passed the --tls-min-version and --tls-cipher-suites flags
+var tlsFlagsMinVersion = semver.MustParse("0.10.0")
+
 func commonLabels() map[string]string {
 	return map[string]string{
 		kubevirtIo: virtTemplateValidator,
@@ -40,6 +61,14 @@ func getTemplateValidatorImage() string {
 	return common.EnvOrDefault(common.TemplateValidatorImageKey, defaultTemplateValidatorImage)
 }
 
+// Image returns the validator image deployed for the spec
+func Image(spec *ssp.SSPSpec) string {
+	if spec.TemplateValidator.Image != "" {
+		return spec.TemplateValidator.Image
+	}
+	return getTemplateValidatorImage()
+}
+
 func newClusterRole() *rbac.ClusterRole {
 	return &rbac.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -87,6 +116,30 @@ func newClusterRoleBinding(namespace string) *rbac.ClusterRoleBinding {
 	}
 }
 
+func newCASecret(namespace string, data map[string][]byte) *core.Secret {
+	return &core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CASecretName,
+			Namespace: namespace,
+			Labels:    commonLabels(),
+		},
+		Type: core.SecretTypeTLS,
+		Data: data,
+	}
+}
+
+func newServingSecret(namespace string, data map[string][]byte) *core.Secret {
+	return &core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+			Labels:    commonLabels(),
+		},
+		Type: core.SecretTypeTLS,
+		Data: data,
+	}
+}
+
 func newService(namespace string) *core.Service {
 	return &core.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -94,7 +147,7 @@ func newService(namespace string) *core.Service {
 			Namespace: namespace,
 			Labels:    commonLabels(),
 			Annotations: map[string]string{
-				"service.beta.openshift.io/serving-cert-secret-name": secretName,
+				servingCertSecretAnnotation: secretName,
 			},
 		},
 		Spec: core.ServiceSpec{
@@ -108,7 +161,7 @@ func newService(namespace string) *core.Service {
 	}
 }
 
-func newDeployment(namespace string, replicas int32, image string) *apps.Deployment {
+func newDeployment(namespace string, replicas int32, image string, logVerbosity int32) *apps.Deployment {
 	const volumeName = "tls"
 	const certMountPath = "/etc/webhook/certs"
 	trueVal := true
@@ -134,11 +187,11 @@ func newDeployment(namespace string, replicas int32, image string) *apps.Deploym
 				Spec: core.PodSpec{
 					ServiceAccountName: ServiceAccountName,
 					Containers: []core.Container{{
-						Name:            "webhook",
+						Name:            webhookContainerName,
 						Image:           image,
 						ImagePullPolicy: core.PullAlways,
 						Args: []string{
-							"-v=2",
+							fmt.Sprintf("-v=%d", logVerbosity),
 							fmt.Sprintf("--port=%d", containerPort),
 							fmt.Sprintf("--cert-dir=%s", certMountPath),
 						},
@@ -170,6 +223,85 @@ func newDeployment(namespace string, replicas int32, image string) *apps.Deploym
 	}
 }
 
+func newHorizontalPodAutoscaler(namespace string, minReplicas *int32, maxReplicas int32, metrics []autoscaling.MetricSpec) *autoscaling.HorizontalPodAutoscaler {
+	return &autoscaling.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AutoscalerName,
+			Namespace: namespace,
+			Labels:    commonLabels(),
+		},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{
+				APIVersion: apps.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       DeploymentName,
+			},
+			MinReplicas: minReplicas,
+			MaxReplicas: maxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+func newPodDisruptionBudget(namespace string, minAvailable, maxUnavailable *intstr.IntOrString) *policy.PodDisruptionBudget {
+	return &policy.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PodDisruptionBudgetName,
+			Namespace: namespace,
+			Labels:    commonLabels(),
+		},
+		Spec: policy.PodDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: commonLabels(),
+			},
+		},
+	}
+}
+
+func newRulesConfigMap(namespace string) *core.ConfigMap {
+	return &core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RulesConfigMapName,
+			Namespace: namespace,
+			Labels:    commonLabels(),
+		},
+	}
+}
+
+// tlsArgs returns the container arguments configuring the TLS server of the validator.
+// Older validator versions, and images without a version tag, fail on unknown flags, so they get no arguments.
+func tlsArgs(image string, profile *ocpv1.TLSSecurityProfile) []string {
+	if !imageVersionAtLeast(image, tlsFlagsMinVersion) {
+		return nil
+	}
+	spec := common.GetTLSProfileSpec(profile)
+	args := []string{fmt.Sprintf("--tls-min-version=%s", spec.MinTLSVersion)}
+	if ciphers := common.CipherSuitesIANANames(spec.Ciphers); len(ciphers) > 0 {
+		args = append(args, fmt.Sprintf("--tls-cipher-suites=%s", strings.Join(ciphers, ",")))
+	}
+	return args
+}
+
+// imageVersionAtLeast returns true if the tag of the image is a semantic version, e.g. v0.10.0,
+// that is at least the minimum version. A pre-release, e.g. v0.10.0-rc.1, is older than its release.
+// Image references without a version tag return false.
+func imageVersionAtLeast(image string, minimum semver.Version) bool {
+	if strings.Contains(image, "@") {
+		image = image[:strings.Index(image, "@")]
+	}
+	tagIndex := strings.LastIndex(image, ":")
+	if tagIndex < 0 || strings.Contains(image[tagIndex:], "/") {
+		return false
+	}
+	version, err := semver.Parse(strings.TrimPrefix(image[tagIndex+1:], "v"))
+	if err != nil {
+		return false
+	}
+	return version.GTE(minimum)
+}
+
 func newValidatingWebhook(namespace string) *admission.ValidatingWebhookConfiguration {
 	path := "/virtualmachine-template-validate"
 	fail := admission.Fail
@@ -179,7 +311,7 @@ func newValidatingWebhook(namespace string) *admission.ValidatingWebhookConfigur
 		ObjectMeta: metav1.ObjectMeta{
 			Name: WebhookName,
 			Annotations: map[string]string{
-				"service.beta.openshift.io/inject-cabundle": "true",
+				injectCABundleAnnotation: "true",
 			},
 		},
 		Webhooks: []admission.ValidatingWebhook{{
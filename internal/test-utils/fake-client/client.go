@@ -0,0 +1,173 @@
+package fake_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// NewFakeClientWithScheme returns a fake client that supports server-side apply patches.
+//
+// Apply is emulated using three-way merge patches, similar to client-side apply:
+// fields removed from the applied configuration since the previous apply are removed,
+// and fields that were never applied are kept. Dry-run applies and deletes do not modify objects.
+func NewFakeClientWithScheme(scheme *runtime.Scheme, objs ...runtime.Object) client.Client {
+	return &applyClient{
+		Client:      fake.NewFakeClientWithScheme(scheme, objs...),
+		scheme:      scheme,
+		lastApplied: map[string][]byte{},
+	}
+}
+
+type applyClient struct {
+	client.Client
+	scheme *runtime.Scheme
+
+	lock        sync.Mutex
+	lastApplied map[string][]byte
+}
+
+var _ client.Client = &applyClient{}
+
+func (c *applyClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(opts)
+	dryRun := isDryRun(patchOptions.DryRun)
+
+	applied, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	objKey := client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+	appliedKey := fmt.Sprintf("%s/%s", gvk.String(), objKey.String())
+
+	current := newEmptyObject(obj)
+	err = c.Client.Get(ctx, objKey, current)
+	if errors.IsNotFound(err) {
+		if dryRun {
+			return nil
+		}
+		err = c.Client.Create(ctx, obj)
+		if err != nil {
+			return err
+		}
+		c.lastApplied[appliedKey] = applied
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	previous, ok := c.lastApplied[appliedKey]
+	if !ok {
+		previous = []byte("{}")
+	}
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	merged, err := mergeApplied(obj, previous, applied, currentJSON)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		return json.Unmarshal(merged, obj)
+	}
+	updated := newEmptyObject(obj)
+	err = json.Unmarshal(merged, updated)
+	if err != nil {
+		return err
+	}
+	err = c.Client.Update(ctx, updated)
+	if err != nil {
+		return err
+	}
+	c.lastApplied[appliedKey] = applied
+	return c.Client.Get(ctx, objKey, obj)
+}
+
+func (c *applyClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	deleteOptions := &client.DeleteOptions{}
+	deleteOptions.ApplyOptions(opts)
+	if !isDryRun(deleteOptions.DryRun) {
+		return c.Client.Delete(ctx, obj, opts...)
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}, newEmptyObject(obj))
+}
+
+func isDryRun(dryRun []string) bool {
+	for _, value := range dryRun {
+		if value == metav1.DryRunAll {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeApplied merges the applied configuration to the current object.
+// Typed objects use strategic merge patches, so lists with a merge key
+// are merged by key. Unstructured objects use JSON merge patches.
+func mergeApplied(obj runtime.Object, previous, applied, current []byte) ([]byte, error) {
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		removalPatch, err := jsonpatch.CreateMergePatch(previous, applied)
+		if err != nil {
+			return nil, err
+		}
+		merged, err := jsonpatch.MergePatch(current, removalPatch)
+		if err != nil {
+			return nil, err
+		}
+		return jsonpatch.MergePatch(merged, applied)
+	}
+
+	patchMeta, err := strategicpatch.NewPatchMetaFromStruct(obj)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := strategicpatch.CreateThreeWayMergePatch(previous, applied, current, patchMeta, true)
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.StrategicMergePatchUsingLookupPatchMeta(current, patch, patchMeta)
+}
+
+func newEmptyObject(obj runtime.Object) runtime.Object {
+	empty := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	empty.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	return empty
+}
@@ -0,0 +1,55 @@
+package topology
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=list
+
+// The vendored OpenShift API does not contain the topology fields,
+// so the Infrastructure CR is read as an unstructured object.
+var infrastructureGVK = schema.GroupVersionKind{
+	Group:   "config.openshift.io",
+	Version: "v1",
+	Kind:    "Infrastructure",
+}
+
+// SingleReplicaTopology is the infrastructure topology of single node OpenShift clusters
+const SingleReplicaTopology = "SingleReplica"
+
+// IsSingleNodeCluster returns true if workloads of the cluster run on a single node.
+// It uses the infrastructure topology on OpenShift, and the number of nodes otherwise.
+func IsSingleNodeCluster(ctx context.Context, reader client.Reader) (bool, error) {
+	infrastructure := &unstructured.Unstructured{}
+	infrastructure.SetGroupVersionKind(infrastructureGVK)
+	err := reader.Get(ctx, client.ObjectKey{Name: "cluster"}, infrastructure)
+	// The Infrastructure CRD does not exist outside of OpenShift
+	if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return false, err
+	}
+	if err == nil {
+		topology, _, err := unstructured.NestedString(infrastructure.Object, "status", "infrastructureTopology")
+		if err != nil {
+			return false, err
+		}
+		if topology != "" {
+			return topology == SingleReplicaTopology, nil
+		}
+	}
+
+	nodes := &unstructured.UnstructuredList{}
+	nodes.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "NodeList"})
+	// Listing two nodes is enough to know if there is more than one
+	err = reader.List(ctx, nodes, client.Limit(2))
+	if err != nil {
+		return false, err
+	}
+	return len(nodes.Items) == 1, nil
+}
@@ -17,22 +17,35 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"strings"
+	"time"
 
+	ocpv1 "github.com/openshift/api/config/v1"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/leaderelection"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	sspv1beta1 "kubevirt.io/ssp-operator/api/v1beta1"
+	sspv1beta2 "kubevirt.io/ssp-operator/api/v1beta2"
 	"kubevirt.io/ssp-operator/controllers"
+	"kubevirt.io/ssp-operator/internal/common"
+	common_templates "kubevirt.io/ssp-operator/internal/operands/common-templates"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -50,15 +63,41 @@ const (
 	olmTLSCrt = "apiserver.crt"
 	olmTLSKey = "apiserver.key"
 
+	// File containing the namespace of the operator pod
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+	// Service account of the operator, if it is not set in the environment
+	defaultOperatorServiceAccount = "ssp-operator"
+
 	// Default cert file names operator-sdk expects to have
 	sdkTLSCrt = "tls.crt"
 	sdkTLSKey = "tls.key"
+
+	// The same resync period controller-runtime uses by default
+	defaultResyncPeriod = 10 * time.Hour
+
+	// The same retry delays the default controller rate limiter uses
+	defaultBackoffBaseDelay = 5 * time.Millisecond
+	defaultBackoffMaxDelay  = 1000 * time.Second
+	defaultBackoffJitter    = 0
+
+	defaultDegradedFailureThreshold = 3
+
+	defaultAuditMaxEntries = 500
+
+	defaultMetricsBindRetryDelay = 1 * time.Second
+
+	// The same leader election durations controller-runtime uses by default
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(sspv1beta1.AddToScheme(scheme))
+	utilruntime.Must(sspv1beta2.AddToScheme(scheme))
 	utilruntime.Must(controllers.InitScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
@@ -67,47 +106,257 @@ func main() {
 	var metricsAddr string
 	var readyProbeAddr string
 	var enableLeaderElection bool
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
+	var maxConcurrentReconciles int
+	var resyncPeriod time.Duration
+	var backoffBaseDelay time.Duration
+	var backoffMaxDelay time.Duration
+	var backoffJitter float64
+	var degradedFailureThreshold int
+	var auditSinkKind string
+	var auditMaxEntries int
+	var requireImageDigests bool
+	var allowedImageRegistries string
+	var protectTemplates bool
+	var metricsCertDir string
+	var tlsMinVersion string
+	var tlsCipherSuites string
+	var metricsBindRetries int
+	var metricsBindRetryDelay time.Duration
+	var pprofAddr string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.IntVar(&metricsBindRetries, "metrics-bind-retries", 0,
+		"The number of times binding the metric endpoint is retried before the operator exits. "+
+			"By default the operator exits if the first attempt fails.")
+	flag.DurationVar(&metricsBindRetryDelay, "metrics-bind-retry-delay", defaultMetricsBindRetryDelay,
+		"The delay before the first retry of binding the metric endpoint. It doubles with every retry.")
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"The directory with the "+sdkTLSCrt+" and "+sdkTLSKey+" files the metrics endpoint is served with over HTTPS. "+
+			"The metrics are served over HTTP if empty.")
+	flag.StringVar(&tlsMinVersion, "tls-min-version", string(ocpv1.VersionTLS12),
+		"The minimum TLS version of the metrics endpoint: VersionTLS10, VersionTLS11, VersionTLS12 or VersionTLS13. "+
+			"The tlsSecurityProfile of the SSP resource takes precedence.")
+	flag.StringVar(&tlsCipherSuites, "tls-cipher-suites", "",
+		"A comma-separated list of IANA names of the cipher suites of the metrics endpoint. "+
+			"The ciphers of the Intermediate TLS profile are used if empty. Insecure cipher suites are rejected. "+
+			"The tlsSecurityProfile of the SSP resource takes precedence.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "",
+		"The localhost address the pprof endpoint binds to, e.g. 127.0.0.1:6060. "+
+			"The profiles are served at /debug/pprof/. It is disabled if empty.")
 	flag.StringVar(&readyProbeAddr, "ready-probe-addr", ":9440", "The address the readiness probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", defaultLeaseDuration,
+		"The duration that non-leader operator pods wait after the last observed renewal of the leadership, "+
+			"before they try to become the leader.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", defaultRenewDeadline,
+		"The duration that the leader retries renewing the leadership before it gives it up. "+
+			"It must be shorter than the lease duration.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", defaultRetryPeriod,
+		"The duration the operator pods wait between attempts to acquire or renew the leadership. "+
+			"It must be shorter than the renew deadline.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The maximum number of SSP resources reconciled concurrently. "+
+			"It also limits the number of templates and other independent resources an operand reconciles concurrently.")
+	flag.DurationVar(&resyncPeriod, "resync-period", defaultResyncPeriod,
+		"The minimum period after which all watched resources are reconciled again, "+
+			"even if they did not change.")
+	flag.DurationVar(&backoffBaseDelay, "backoff-base-delay", defaultBackoffBaseDelay,
+		"The delay before the first retry of a failed reconciliation. It doubles with every failure.")
+	flag.DurationVar(&backoffMaxDelay, "backoff-max-delay", defaultBackoffMaxDelay,
+		"The maximum delay between retries of a failed reconciliation.")
+	flag.Float64Var(&backoffJitter, "backoff-jitter", defaultBackoffJitter,
+		"The maximum random fraction that is added to the retry delay, e.g. 0.1 adds up to 10%.")
+	flag.IntVar(&degradedFailureThreshold, "degraded-failure-threshold", defaultDegradedFailureThreshold,
+		"The number of consecutive failed reconciliations of an operand, after which it is reported as degraded. "+
+			"Earlier failures are only reported in the Progressing condition.")
+	flag.StringVar(&auditSinkKind, "audit-sink", "",
+		"Where the changes the operator does to objects are recorded: "+
+			"\"log\", \"events\" on the SSP resource, or a \"configmap\" in its namespace. They are not recorded if empty.")
+	flag.IntVar(&auditMaxEntries, "audit-max-entries", defaultAuditMaxEntries,
+		"The number of latest changes kept in the audit ConfigMap.")
+	flag.BoolVar(&requireImageDigests, "require-image-digests", false,
+		"Reject image overrides in the SSP resource that are not referenced by a digest.")
+	flag.StringVar(&allowedImageRegistries, "allowed-image-registries", "",
+		"A comma-separated list of registries or repository prefixes, e.g. quay.io/kubevirt, "+
+			"image overrides in the SSP resource must be pulled from. Any registry is allowed if empty.")
+	flag.BoolVar(&protectTemplates, "protect-common-templates", false,
+		"Reject modification and deletion of the common templates, unless they have the "+
+			sspv1beta1.AllowTemplateModificationAnnotation+" annotation.")
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+	// The log level of the operator can be changed in the SSP CR
+	logLevel := uberzap.NewAtomicLevelAt(zapcore.Level(-controllers.DefaultOperatorLogVerbosity))
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true), zap.Level(&logLevel)))
+
+	if resyncPeriod <= 0 {
+		setupLog.Error(fmt.Errorf("resync period must be positive: %v", resyncPeriod), "Invalid flag value")
+		os.Exit(1)
+	}
+	if err := validateLeaderElectionDurations(leaseDuration, renewDeadline, retryPeriod); err != nil {
+		setupLog.Error(err, "Invalid flag value")
+		os.Exit(1)
+	}
+	if backoffBaseDelay <= 0 || backoffMaxDelay < backoffBaseDelay {
+		setupLog.Error(fmt.Errorf("backoff delays must be positive and the base delay must not exceed the max delay: %v, %v",
+			backoffBaseDelay, backoffMaxDelay), "Invalid flag value")
+		os.Exit(1)
+	}
+	if backoffJitter < 0 {
+		setupLog.Error(fmt.Errorf("backoff jitter must not be negative: %v", backoffJitter), "Invalid flag value")
+		os.Exit(1)
+	}
+	if degradedFailureThreshold < 1 {
+		setupLog.Error(fmt.Errorf("degraded failure threshold must be at least 1: %v", degradedFailureThreshold), "Invalid flag value")
+		os.Exit(1)
+	}
+	if auditMaxEntries < 1 {
+		setupLog.Error(fmt.Errorf("audit max entries must be at least 1: %v", auditMaxEntries), "Invalid flag value")
+		os.Exit(1)
+	}
+	if metricsBindRetries < 0 || metricsBindRetryDelay <= 0 {
+		setupLog.Error(fmt.Errorf("metrics bind retries must not be negative and the retry delay must be positive: %v, %v",
+			metricsBindRetries, metricsBindRetryDelay), "Invalid flag value")
+		os.Exit(1)
+	}
+	metricsTLS, err := newMetricsTLSSettings(tlsMinVersion, splitList(tlsCipherSuites))
+	if err != nil {
+		setupLog.Error(err, "Invalid flag value")
+		os.Exit(1)
+	}
 
-	err := copyCertificates()
+	useOLMCertificates, err := copyCertificates()
 	if err != nil {
 		setupLog.Error(err, "Error copying certificates")
 		os.Exit(1)
 	}
 
+	operatorCondition, err := getOperatorCondition()
+	if err != nil {
+		setupLog.Error(err, "Error reading the OperatorCondition name")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
+		MetricsBindAddress:     "0", // The metrics are served by metricsServer
 		HealthProbeBindAddress: readyProbeAddr,
 		Port:                   9443,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "734f7229.kubevirt.io",
+		LeaseDuration:          &leaseDuration,
+		RenewDeadline:          &renewDeadline,
+		RetryPeriod:            &retryPeriod,
+		SyncPeriod:             &resyncPeriod,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	recorder := mgr.GetEventRecorderFor("ssp-operator")
+	var auditSink controllers.AuditSink
+	if auditSinkKind != "" {
+		auditSink, err = controllers.NewAuditSink(auditSinkKind, mgr.GetClient(), mgr.GetScheme(), recorder,
+			ctrl.Log.WithName("audit"), auditMaxEntries)
+		if err != nil {
+			setupLog.Error(err, "Invalid flag value")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.SSPReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("SSP"),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("SSP"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: recorder,
+
+		MaxConcurrentReconciles:  maxConcurrentReconciles,
+		DegradedFailureThreshold: degradedFailureThreshold,
+		AuditSink:                auditSink,
+		RateLimiter:              controllers.NewRateLimiter(backoffBaseDelay, backoffMaxDelay, backoffJitter),
+		SetLogVerbosity: func(verbosity int32) {
+			logLevel.SetLevel(zapcore.Level(-verbosity))
+		},
+		SetTLSSecurityProfile: func(profile *ocpv1.TLSSecurityProfile) {
+			if err := metricsTLS.setFromProfile(profile); err != nil {
+				setupLog.Error(err, "Error applying the TLS security profile to the metrics server")
+			}
+		},
+		OperatorCondition: operatorCondition,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SSP")
 		os.Exit(1)
 	}
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		sspv1beta1.SetImagePolicy(sspv1beta1.ImagePolicy{
+			RequireDigest:     requireImageDigests,
+			AllowedRegistries: splitList(allowedImageRegistries),
+		})
 		if err = (&sspv1beta1.SSP{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "SSP")
 			os.Exit(1)
 		}
+
+		operatorNamespace := ""
+		if protectTemplates {
+			operatorNamespace, err = getOperatorNamespace()
+			if err != nil {
+				setupLog.Error(err, "Error reading the operator namespace")
+				os.Exit(1)
+			}
+		}
+		mgr.GetWebhookServer().Register(common_templates.ProtectionWebhookPath,
+			common_templates.NewProtectionWebhook(operatorNamespace,
+				common.EnvOrDefault(common.OperatorServiceAccountKey, defaultOperatorServiceAccount), protectTemplates))
+	}
+	if useOLMCertificates {
+		// The webhook server reloads the copied certificates when they change
+		if err = mgr.Add(&olmCertificatesWatcher{olmDir: olmTLSDir, sdkDir: sdkTLSDir}); err != nil {
+			setupLog.Error(err, "unable to watch OLM certificates")
+			os.Exit(1)
+		}
+	}
+	var metricsCertificates *certificateWatcher
+	if metricsCertDir != "" {
+		metricsCertificates, err = newCertificateWatcher(metricsCertDir)
+		if err != nil {
+			setupLog.Error(err, "unable to load metrics server certificate")
+			os.Exit(1)
+		}
+		if err = mgr.Add(metricsCertificates); err != nil {
+			setupLog.Error(err, "unable to watch metrics server certificate")
+			os.Exit(1)
+		}
+	}
+	// The metrics address is bound before the manager starts, so the operator fails early
+	metricsListener, err := listenMetrics(metricsAddr, metricsBindRetries, metricsBindRetryDelay)
+	if err != nil {
+		setupLog.Error(err, "unable to start metrics server")
+		os.Exit(1)
+	}
+	err = mgr.Add(&metricsServer{
+		listener:     metricsListener,
+		certificates: metricsCertificates,
+		tlsSettings:  metricsTLS,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to add metrics server")
+		os.Exit(1)
+	}
+	if pprofAddr != "" {
+		pprofListener, err := listenPprof(pprofAddr)
+		if err != nil {
+			setupLog.Error(err, "unable to start pprof server")
+			os.Exit(1)
+		}
+		if err = mgr.Add(&pprofServer{listener: pprofListener}); err != nil {
+			setupLog.Error(err, "unable to add pprof server")
+			os.Exit(1)
+		}
 	}
 	err = mgr.AddReadyzCheck("ready", healthz.Ping)
 	if err != nil {
@@ -124,7 +373,55 @@ func main() {
 	}
 }
 
-func copyCertificates() error {
+// validateLeaderElectionDurations returns an error if the leader election client would reject the durations.
+// The renew deadline is compared with the jittered retry period, as in the leader election client.
+func validateLeaderElectionDurations(leaseDuration, renewDeadline, retryPeriod time.Duration) error {
+	if retryPeriod <= 0 || renewDeadline <= time.Duration(leaderelection.JitterFactor*float64(retryPeriod)) ||
+		leaseDuration <= renewDeadline {
+		return fmt.Errorf("leader election durations must be positive, the renew deadline must exceed "+
+			"%v times the retry period and the lease duration must exceed the renew deadline: %v, %v, %v",
+			leaderelection.JitterFactor, leaseDuration, renewDeadline, retryPeriod)
+	}
+	return nil
+}
+
+// getOperatorCondition returns the key of the OperatorCondition created by OLM,
+// or nil if the operator is not deployed by OLM
+func getOperatorCondition() (*types.NamespacedName, error) {
+	name := os.Getenv(controllers.OperatorConditionNameEnv)
+	if name == "" {
+		return nil, nil
+	}
+	namespace, err := getOperatorNamespace()
+	if err != nil {
+		return nil, err
+	}
+	return &types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// getOperatorNamespace returns the namespace of the operator pod
+func getOperatorNamespace() (string, error) {
+	namespace, err := ioutil.ReadFile(namespaceFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(namespace)), nil
+}
+
+// splitList returns the non-empty items of a comma-separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// copyCertificates copies the certificates generated by OLM to the directory
+// used by the webhook server. It returns true if OLM certificates are used.
+func copyCertificates() (bool, error) {
 	olmDir, olmDirErr := os.Stat(olmTLSDir)
 	_, sdkDirErr := os.Stat(sdkTLSDir)
 
@@ -137,23 +434,81 @@ func copyCertificates() error {
 
 		err := os.MkdirAll(sdkTLSDir, 0755)
 		if err != nil {
-			return fmt.Errorf("failed to create %s: %w", sdkTLSCrt, err)
+			return false, fmt.Errorf("failed to create %s: %w", sdkTLSCrt, err)
 		}
 
-		err = copyFile(path.Join(olmTLSDir, olmTLSCrt), path.Join(sdkTLSDir, sdkTLSCrt))
+		_, err = copyOLMCertificates(olmTLSDir, sdkTLSDir)
 		if err != nil {
-			return fmt.Errorf("failed to copy %s/%s to %s/%s: %w", olmTLSDir, olmTLSCrt, sdkTLSDir, sdkTLSCrt, err)
+			return false, err
 		}
+		return true, nil
+	}
 
-		err = copyFile(path.Join(olmTLSDir, olmTLSKey), path.Join(sdkTLSDir, sdkTLSKey))
+	setupLog.Info("OLM cert directory not found, using default")
+	return false, nil
+}
+
+// copyOLMCertificates copies the OLM certificate and key from olmDir to sdkDir,
+// if they differ from the copies. It returns true if any file was copied.
+func copyOLMCertificates(olmDir, sdkDir string) (bool, error) {
+	copied := false
+	// The key is copied first, so the new certificate is not loaded with the old key
+	for _, file := range [][2]string{{olmTLSKey, sdkTLSKey}, {olmTLSCrt, sdkTLSCrt}} {
+		src := path.Join(olmDir, file[0])
+		dst := path.Join(sdkDir, file[1])
+		equal, err := sameContent(src, dst)
 		if err != nil {
-			return fmt.Errorf("failed to copy %s/%s to %s/%s: %w", olmTLSDir, olmTLSKey, sdkTLSDir, sdkTLSKey, err)
+			return copied, err
+		}
+		if equal {
+			continue
 		}
-	} else {
-		setupLog.Info("OLM cert directory not found, using default")
+		err = copyFile(src, dst)
+		if err != nil {
+			return copied, fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+		}
+		copied = true
 	}
+	return copied, nil
+}
 
-	return nil
+func sameContent(src, dst string) (bool, error) {
+	srcContent, err := ioutil.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+	dstContent, err := ioutil.ReadFile(dst)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(srcContent, dstContent), nil
+}
+
+// olmCertificatesWatcher copies the OLM certificates again when OLM rotates them
+type olmCertificatesWatcher struct {
+	olmDir string
+	sdkDir string
+}
+
+var _ manager.LeaderElectionRunnable = &olmCertificatesWatcher{}
+
+// NeedLeaderElection returns false, because the webhook server runs without leader election
+func (w *olmCertificatesWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *olmCertificatesWatcher) Start(stop <-chan struct{}) error {
+	return watchDirectory(w.olmDir, stop, func() {
+		copied, err := copyOLMCertificates(w.olmDir, w.sdkDir)
+		if err != nil {
+			setupLog.Error(err, "Error copying rotated OLM certificates")
+		} else if copied {
+			setupLog.Info("OLM certificates changed, copied cert files")
+		}
+	})
 }
 
 func copyFile(src, dst string) error {
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Leader election", func() {
+	table.DescribeTable("durations", func(leaseDuration, renewDeadline, retryPeriod time.Duration, valid bool) {
+		err := validateLeaderElectionDurations(leaseDuration, renewDeadline, retryPeriod)
+		if valid {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		table.Entry("defaults", defaultLeaseDuration, defaultRenewDeadline, defaultRetryPeriod, true),
+		table.Entry("zero retry period", 15*time.Second, 10*time.Second, time.Duration(0), false),
+		table.Entry("negative retry period", 15*time.Second, 10*time.Second, -2*time.Second, false),
+		table.Entry("renew deadline shorter than the jittered retry period", 15*time.Second, 2*time.Second, 2*time.Second, false),
+		table.Entry("renew deadline equal to the jittered retry period", 15*time.Second, 2400*time.Millisecond, 2*time.Second, false),
+		table.Entry("renew deadline above the jittered retry period", 15*time.Second, 2500*time.Millisecond, 2*time.Second, true),
+		table.Entry("lease duration equal to the renew deadline", 10*time.Second, 10*time.Second, 2*time.Second, false),
+		table.Entry("lease duration shorter than the renew deadline", 5*time.Second, 10*time.Second, 2*time.Second, false),
+	)
+})
+
+var _ = Describe("OLM certificates", func() {
+	var (
+		olmDir string
+		sdkDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		olmDir, err = ioutil.TempDir("", "olm-certs")
+		Expect(err).ToNot(HaveOccurred())
+		sdkDir, err = ioutil.TempDir("", "sdk-certs")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(olmDir)).To(Succeed())
+		Expect(os.RemoveAll(sdkDir)).To(Succeed())
+	})
+
+	writeOLMCertificates := func(cert, key string) {
+		Expect(ioutil.WriteFile(path.Join(olmDir, olmTLSCrt), []byte(cert), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(olmDir, olmTLSKey), []byte(key), 0600)).To(Succeed())
+	}
+
+	readSDKFile := func(name string) string {
+		content, err := ioutil.ReadFile(path.Join(sdkDir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(content)
+	}
+
+	Context("sameContent", func() {
+		It("should compare the content of the files", func() {
+			src := path.Join(olmDir, "src")
+			dst := path.Join(sdkDir, "dst")
+			Expect(ioutil.WriteFile(src, []byte("content"), 0600)).To(Succeed())
+
+			Expect(ioutil.WriteFile(dst, []byte("content"), 0600)).To(Succeed())
+			Expect(sameContent(src, dst)).To(BeTrue())
+
+			Expect(ioutil.WriteFile(dst, []byte("other content"), 0600)).To(Succeed())
+			Expect(sameContent(src, dst)).To(BeFalse())
+		})
+
+		It("should return false if the copy does not exist", func() {
+			src := path.Join(olmDir, "src")
+			Expect(ioutil.WriteFile(src, []byte("content"), 0600)).To(Succeed())
+			Expect(sameContent(src, path.Join(sdkDir, "missing"))).To(BeFalse())
+		})
+
+		It("should fail if the source does not exist", func() {
+			_, err := sameContent(path.Join(olmDir, "missing"), path.Join(sdkDir, "dst"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("copyOLMCertificates", func() {
+		It("should copy the certificate and the key", func() {
+			writeOLMCertificates("certificate", "key")
+
+			Expect(copyOLMCertificates(olmDir, sdkDir)).To(BeTrue())
+			Expect(readSDKFile(sdkTLSCrt)).To(Equal("certificate"))
+			Expect(readSDKFile(sdkTLSKey)).To(Equal("key"))
+		})
+
+		It("should not copy unchanged files", func() {
+			writeOLMCertificates("certificate", "key")
+			Expect(copyOLMCertificates(olmDir, sdkDir)).To(BeTrue())
+
+			Expect(copyOLMCertificates(olmDir, sdkDir)).To(BeFalse())
+		})
+
+		It("should copy only the changed file", func() {
+			writeOLMCertificates("certificate", "key")
+			Expect(copyOLMCertificates(olmDir, sdkDir)).To(BeTrue())
+			Expect(ioutil.WriteFile(path.Join(sdkDir, sdkTLSKey), []byte("local key"), 0600)).To(Succeed())
+
+			writeOLMCertificates("rotated certificate", "key")
+			Expect(copyOLMCertificates(olmDir, sdkDir)).To(BeTrue())
+			Expect(readSDKFile(sdkTLSCrt)).To(Equal("rotated certificate"))
+			Expect(readSDKFile(sdkTLSKey)).To(Equal("key"))
+		})
+
+		It("should copy the key before the certificate", func() {
+			writeOLMCertificates("certificate", "key")
+			// The key cannot be written, so the copy fails at the key
+			Expect(os.Mkdir(path.Join(sdkDir, sdkTLSKey), 0755)).To(Succeed())
+
+			copied, err := copyOLMCertificates(olmDir, sdkDir)
+			Expect(err).To(HaveOccurred())
+			Expect(copied).To(BeFalse())
+			Expect(path.Join(sdkDir, sdkTLSCrt)).ToNot(BeAnExistingFile())
+		})
+	})
+
+	It("should copy rotated certificates until it is stopped", func() {
+		writeOLMCertificates("certificate", "key")
+		Expect(copyOLMCertificates(olmDir, sdkDir)).To(BeTrue())
+
+		watcher := &olmCertificatesWatcher{olmDir: olmDir, sdkDir: sdkDir}
+		Expect(watcher.NeedLeaderElection()).To(BeFalse())
+		stop := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- watcher.Start(stop)
+		}()
+
+		// The watch is added asynchronously, so the files are written until the copy is seen
+		Eventually(func() string {
+			writeOLMCertificates("rotated certificate", "rotated key")
+			return readSDKFile(sdkTLSCrt)
+		}).Should(Equal("rotated certificate"))
+		Eventually(func() string {
+			return readSDKFile(sdkTLSKey)
+		}).Should(Equal("rotated key"))
+
+		close(stop)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
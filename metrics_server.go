@@ -0,0 +1,212 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	ocpv1 "github.com/openshift/api/config/v1"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"kubevirt.io/ssp-operator/internal/common"
+)
+
+const (
+	metricsPath = "/metrics"
+
+	// serverShutdownTimeout is shorter than the graceful shutdown timeout of the manager
+	serverShutdownTimeout = 10 * time.Second
+)
+
+// metricsTLSSettings are the TLS version and cipher suites of the metrics server.
+// The settings from the flags are used, until the SSP CR sets a TLS security profile.
+type metricsTLSSettings struct {
+	lock sync.RWMutex
+
+	defaultMinVersion   uint16
+	defaultCipherSuites []uint16
+
+	minVersion   uint16
+	cipherSuites []uint16
+}
+
+// newMetricsTLSSettings parses the TLS version and the IANA names of the cipher suites.
+// The ciphers of the Intermediate profile are used if no cipher suites are passed.
+func newMetricsTLSSettings(minVersion string, cipherSuites []string) (*metricsTLSSettings, error) {
+	version, err := common.TLSVersion(ocpv1.TLSProtocolVersion(minVersion))
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherSuites) == 0 {
+		cipherSuites = common.CipherSuitesIANANames(common.GetTLSProfileSpec(nil).Ciphers)
+	}
+	ids, err := common.CipherSuiteIDs(cipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsTLSSettings{
+		defaultMinVersion:   version,
+		defaultCipherSuites: ids,
+		minVersion:          version,
+		cipherSuites:        ids,
+	}, nil
+}
+
+// setFromProfile uses the settings of the TLS security profile,
+// or the settings from the flags if the profile is nil
+func (s *metricsTLSSettings) setFromProfile(profile *ocpv1.TLSSecurityProfile) error {
+	minVersion, cipherSuites := s.defaultMinVersion, s.defaultCipherSuites
+	if profile != nil {
+		spec := common.GetTLSProfileSpec(profile)
+		var err error
+		minVersion, err = common.TLSVersion(spec.MinTLSVersion)
+		if err != nil {
+			return err
+		}
+		cipherSuites, err = common.CipherSuiteIDs(common.CipherSuitesIANANames(spec.Ciphers))
+		if err != nil {
+			return err
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.minVersion = minVersion
+	s.cipherSuites = cipherSuites
+	return nil
+}
+
+// tlsConfig returns a TLS config with the current settings, it is called for every connection
+func (s *metricsTLSSettings) tlsConfig(certificates *certificateWatcher) *tls.Config {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return &tls.Config{
+		GetCertificate: certificates.GetCertificate,
+		MinVersion:     s.minVersion,
+		CipherSuites:   s.cipherSuites,
+	}
+}
+
+// listenMetrics binds the metrics address. If it fails, binding is retried
+// up to the number of retries, with the delay doubled after every attempt.
+func listenMetrics(addr string, retries int, delay time.Duration) (net.Listener, error) {
+	var listener net.Listener
+	var lastErr error
+	backoff := wait.Backoff{
+		Duration: delay,
+		Factor:   2,
+		Steps:    retries + 1,
+	}
+	err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		listener, lastErr = net.Listen("tcp", addr)
+		if lastErr != nil {
+			setupLog.Error(lastErr, "Error binding metrics address", "addr", addr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind metrics address %s: %w", addr, lastErr)
+	}
+	return listener, nil
+}
+
+// metricsServer serves the metrics of the controller-runtime registry.
+// The metrics are served over TLS with the watched certificate, or over HTTP if certificates is nil.
+type metricsServer struct {
+	listener     net.Listener
+	certificates *certificateWatcher
+	tlsSettings  *metricsTLSSettings
+}
+
+var _ manager.LeaderElectionRunnable = &metricsServer{}
+
+// NeedLeaderElection returns false, because metrics are served also by operator pods that are not the leader
+func (m *metricsServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the metrics until the stop channel is closed, then it waits for active requests to finish
+func (m *metricsServer) Start(stop <-chan struct{}) error {
+	server := m.newServer()
+	return serveUntilStopped("metrics server", server, func() error {
+		return m.serve(server)
+	}, stop)
+}
+
+// serveUntilStopped runs serve until it fails or the stop channel is closed, then it shuts down the server
+func serveUntilStopped(name string, server *http.Server, serve func() error, stop <-chan struct{}) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serve()
+	}()
+
+	select {
+	case err := <-errs:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	case <-stop:
+	}
+
+	setupLog.Info("Shutting down " + name)
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down %s: %w", name, err)
+	}
+	return nil
+}
+
+func (m *metricsServer) newServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	}))
+	server := &http.Server{
+		Handler: mux,
+	}
+	if m.certificates != nil {
+		server.TLSConfig = &tls.Config{
+			GetCertificate: m.certificates.GetCertificate,
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return m.tlsSettings.tlsConfig(m.certificates), nil
+			},
+		}
+	}
+	return server
+}
+
+func (m *metricsServer) serve(server *http.Server) error {
+	if m.certificates == nil {
+		setupLog.Info("Serving metrics over HTTP", "addr", m.listener.Addr().String())
+		return server.Serve(m.listener)
+	}
+	setupLog.Info("Serving metrics over HTTPS", "addr", m.listener.Addr().String())
+	// The certificate is returned by the TLS config
+	return server.ServeTLS(m.listener, "", "")
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	ocpv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("Metrics server", func() {
+	intermediateCipherSuites := func() []uint16 {
+		settings, err := newMetricsTLSSettings(string(ocpv1.VersionTLS12), nil)
+		Expect(err).ToNot(HaveOccurred())
+		return settings.cipherSuites
+	}
+
+	table.DescribeTable("TLS settings from flags", func(minVersion string, cipherSuites []string, expectedVersion uint16, expectedCipherSuites []uint16) {
+		settings, err := newMetricsTLSSettings(minVersion, cipherSuites)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.minVersion).To(Equal(expectedVersion))
+		Expect(settings.cipherSuites).To(Equal(expectedCipherSuites))
+	},
+		table.Entry("with cipher suites", string(ocpv1.VersionTLS11),
+			[]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"},
+			uint16(tls.VersionTLS11), []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA}),
+		table.Entry("without cipher suites", string(ocpv1.VersionTLS13), nil,
+			uint16(tls.VersionTLS13), []uint16{
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+			}),
+	)
+
+	table.DescribeTable("invalid TLS flags", func(minVersion string, cipherSuites []string, expectedErr string) {
+		_, err := newMetricsTLSSettings(minVersion, cipherSuites)
+		Expect(err).To(MatchError(expectedErr))
+	},
+		table.Entry("unknown TLS version", "TLSv1.2", nil, "unknown TLS version: TLSv1.2"),
+		table.Entry("OpenSSL cipher name", string(ocpv1.VersionTLS12), []string{"ECDHE-RSA-AES128-GCM-SHA256"},
+			"unknown cipher suite: ECDHE-RSA-AES128-GCM-SHA256"),
+		table.Entry("insecure cipher suite", string(ocpv1.VersionTLS12), []string{"TLS_RSA_WITH_3DES_EDE_CBC_SHA"},
+			"insecure cipher suite: TLS_RSA_WITH_3DES_EDE_CBC_SHA"),
+	)
+
+	table.DescribeTable("TLS settings from profile", func(profile *ocpv1.TLSSecurityProfile, expectedVersion uint16, expectedCipherSuites func() []uint16) {
+		settings, err := newMetricsTLSSettings(string(ocpv1.VersionTLS10), []string{"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.setFromProfile(&ocpv1.TLSSecurityProfile{Type: ocpv1.TLSProfileModernType})).To(Succeed())
+
+		Expect(settings.setFromProfile(profile)).To(Succeed())
+		config := settings.tlsConfig(nil)
+		Expect(config.MinVersion).To(Equal(expectedVersion))
+		Expect(config.CipherSuites).To(Equal(expectedCipherSuites()))
+	},
+		table.Entry("nil profile restores the flags", nil, uint16(tls.VersionTLS10), func() []uint16 {
+			return []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA}
+		}),
+		table.Entry("intermediate profile", &ocpv1.TLSSecurityProfile{Type: ocpv1.TLSProfileIntermediateType},
+			uint16(tls.VersionTLS12), intermediateCipherSuites),
+		table.Entry("modern profile", &ocpv1.TLSSecurityProfile{Type: ocpv1.TLSProfileModernType},
+			uint16(tls.VersionTLS13), func() []uint16 { return []uint16{} }),
+		table.Entry("custom profile", &ocpv1.TLSSecurityProfile{
+			Type: ocpv1.TLSProfileCustomType,
+			Custom: &ocpv1.CustomTLSProfile{TLSProfileSpec: ocpv1.TLSProfileSpec{
+				Ciphers:       []string{"ECDHE-RSA-AES256-GCM-SHA384"},
+				MinTLSVersion: ocpv1.VersionTLS11,
+			}},
+		}, uint16(tls.VersionTLS11), func() []uint16 {
+			return []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+		}),
+	)
+
+	It("should keep the settings if the profile is invalid", func() {
+		settings, err := newMetricsTLSSettings(string(ocpv1.VersionTLS12), nil)
+		Expect(err).ToNot(HaveOccurred())
+		err = settings.setFromProfile(&ocpv1.TLSSecurityProfile{
+			Type: ocpv1.TLSProfileCustomType,
+			Custom: &ocpv1.CustomTLSProfile{TLSProfileSpec: ocpv1.TLSProfileSpec{
+				MinTLSVersion: "TLSv1.2",
+			}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(settings.minVersion).To(Equal(uint16(tls.VersionTLS12)))
+	})
+
+	table.DescribeTable("binding the metrics address", func(retries int, releaseAfter time.Duration, success bool) {
+		// The address is occupied until releaseAfter, or until the end of the test if it is zero
+		occupied, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer occupied.Close()
+		if releaseAfter > 0 {
+			timer := time.AfterFunc(releaseAfter, func() {
+				occupied.Close()
+			})
+			defer timer.Stop()
+		}
+
+		listener, err := listenMetrics(occupied.Addr().String(), retries, 20*time.Millisecond)
+		if !success {
+			Expect(err).To(MatchError(ContainSubstring("failed to bind metrics address " + occupied.Addr().String())))
+			return
+		}
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listener.Close()).To(Succeed())
+	},
+		table.Entry("fails without retries", 0, time.Duration(0), false),
+		table.Entry("fails when the retries run out", 2, time.Duration(0), false),
+		table.Entry("succeeds when the address is released before a retry", 3, 10*time.Millisecond, true),
+	)
+
+	It("should bind a free metrics address without retries", func() {
+		listener, err := listenMetrics("127.0.0.1:0", 0, time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listener.Close()).To(Succeed())
+	})
+})
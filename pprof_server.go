@@ -0,0 +1,67 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// pprofServer serves the runtime profiling data of the operator
+type pprofServer struct {
+	listener net.Listener
+}
+
+var _ manager.LeaderElectionRunnable = &pprofServer{}
+
+// listenPprof binds the pprof address, which must be on a loopback interface,
+// so the profiles are not accessible from outside of the pod
+func listenPprof(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("pprof address must be on localhost: %s", addr)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// NeedLeaderElection returns false, so also operator pods that are not the leader can be profiled
+func (p *pprofServer) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *pprofServer) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{
+		Handler: mux,
+	}
+	return serveUntilStopped("pprof server", server, func() error {
+		setupLog.Info("Serving pprof", "addr", p.listener.Addr().String())
+		return server.Serve(p.listener)
+	}, stop)
+}
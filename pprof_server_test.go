@@ -0,0 +1,27 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("pprof server", func() {
+	table.DescribeTable("address", func(addr string, valid bool) {
+		listener, err := listenPprof(addr)
+		if !valid {
+			Expect(err).To(HaveOccurred())
+			return
+		}
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listener.Close()).To(Succeed())
+	},
+		table.Entry("loopback IP", "127.0.0.1:0", true),
+		table.Entry("localhost", "localhost:0", true),
+		table.Entry("all interfaces", ":0", false),
+		table.Entry("unspecified IP", "0.0.0.0:0", false),
+		table.Entry("other IP", "10.0.0.1:6060", false),
+		table.Entry("other host name", "example.com:6060", false),
+		table.Entry("missing port", "127.0.0.1", false),
+	)
+})
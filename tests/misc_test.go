@@ -1,12 +1,28 @@
 package tests
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	admission "k8s.io/api/admissionregistration/v1"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
 
 	sspv1beta1 "kubevirt.io/ssp-operator/api/v1beta1"
+	"kubevirt.io/ssp-operator/controllers"
+	"kubevirt.io/ssp-operator/internal/common"
+	validator "kubevirt.io/ssp-operator/internal/operands/template-validator"
 )
 
 var _ = Describe("Observed generation", func() {
@@ -68,8 +84,340 @@ var _ = Describe("Observed generation", func() {
 		err = WatchChangesUntil(watch, func(updatedSsp *sspv1beta1.SSP) bool {
 			return updatedSsp.DeletionTimestamp != nil &&
 				updatedSsp.Status.Phase == lifecycleapi.PhaseDeleting &&
+				conditionsv1.IsStatusConditionTrue(updatedSsp.Status.Conditions, sspv1beta1.ConditionDeleting) &&
 				updatedSsp.Generation == updatedSsp.Status.ObservedGeneration
 		}, shortTimeout)
 		Expect(err).ToNot(HaveOccurred())
 	})
 })
+
+var _ = Describe("Cleanup", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+		waitUntilDeployed()
+	})
+
+	It("should remove cluster resources when removing CR", func() {
+		Expect(apiClient.Delete(ctx, getSsp())).ToNot(HaveOccurred())
+
+		Eventually(func() bool {
+			err := apiClient.Get(ctx, client.ObjectKey{Name: validator.WebhookName}, &admission.ValidatingWebhookConfiguration{})
+			return errors.IsNotFound(err)
+		}, shortTimeout, time.Second).Should(BeTrue())
+		Eventually(func() bool {
+			err := apiClient.Get(ctx, client.ObjectKey{Name: validator.ClusterRoleName}, &rbac.ClusterRole{})
+			return errors.IsNotFound(err)
+		}, shortTimeout, time.Second).Should(BeTrue())
+	})
+})
+
+var _ = Describe("Disabled operand", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+		waitUntilDeployed()
+	})
+
+	It("should remove template validator resources when it is disabled", func() {
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			foundSsp.Spec.TemplateValidator.Enabled = pointer.BoolPtr(false)
+		})
+
+		Eventually(func() bool {
+			err := apiClient.Get(ctx, client.ObjectKey{Name: validator.WebhookName}, &admission.ValidatingWebhookConfiguration{})
+			return errors.IsNotFound(err)
+		}, shortTimeout, time.Second).Should(BeTrue())
+		Eventually(func() bool {
+			err := apiClient.Get(ctx, client.ObjectKey{Name: validator.DeploymentName, Namespace: strategy.GetNamespace()}, &apps.Deployment{})
+			return errors.IsNotFound(err)
+		}, shortTimeout, time.Second).Should(BeTrue())
+		Eventually(func() bool {
+			for _, operand := range getSsp().Status.Operands {
+				if operand.Name == "template-validator" {
+					return false
+				}
+			}
+			return true
+		}, shortTimeout, time.Second).Should(BeTrue())
+	})
+})
+
+var _ = Describe("Pause", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+		waitUntilDeployed()
+	})
+
+	It("should pause and unpause reconciliation using spec.paused", func() {
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			foundSsp.Spec.Paused = true
+		})
+		Eventually(func() bool {
+			ssp := getSsp()
+			return ssp.Status.Paused &&
+				ssp.Status.Phase == sspv1beta1.PhasePaused &&
+				conditionsv1.IsStatusConditionTrue(ssp.Status.Conditions, sspv1beta1.ConditionPaused)
+		}, shortTimeout, time.Second).Should(BeTrue())
+
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			foundSsp.Spec.Paused = false
+		})
+		Eventually(func() bool {
+			ssp := getSsp()
+			return !ssp.Status.Paused &&
+				conditionsv1.IsStatusConditionFalse(ssp.Status.Conditions, sspv1beta1.ConditionPaused)
+		}, shortTimeout, time.Second).Should(BeTrue())
+	})
+
+	It("should observe spec changes while paused using the annotation", func() {
+		pauseSsp()
+
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			foundSsp.Spec.CommonLabels = map[string]string{"paused-test": "true"}
+		})
+		Eventually(func() bool {
+			ssp := getSsp()
+			return ssp.Status.Paused &&
+				ssp.Status.ObservedGeneration == ssp.Generation &&
+				conditionsv1.IsStatusConditionTrue(ssp.Status.Conditions, sspv1beta1.ConditionPaused)
+		}, shortTimeout, time.Second).Should(BeTrue())
+
+		unpauseSsp()
+	})
+})
+
+var _ = Describe("Operand status", func() {
+	It("should report conditions of each deployed operand", func() {
+		waitUntilDeployed()
+
+		operands := getSsp().Status.Operands
+		Expect(operands).ToNot(BeEmpty())
+		for _, operand := range operands {
+			Expect(conditionsv1.IsStatusConditionTrue(operand.Conditions, conditionsv1.ConditionAvailable)).To(BeTrue(), operand.Name)
+			Expect(conditionsv1.IsStatusConditionFalse(operand.Conditions, conditionsv1.ConditionProgressing)).To(BeTrue(), operand.Name)
+			Expect(conditionsv1.IsStatusConditionFalse(operand.Conditions, conditionsv1.ConditionDegraded)).To(BeTrue(), operand.Name)
+		}
+	})
+
+	It("should report ready operands and resources", func() {
+		waitUntilDeployed()
+
+		status := getSsp().Status
+		Expect(status.OperandsReady).To(Equal(fmt.Sprintf("%d/%d", len(status.Operands), len(status.Operands))))
+		for _, operand := range status.Operands {
+			var ready, total int
+			_, err := fmt.Sscanf(operand.ResourcesReady, "%d/%d", &ready, &total)
+			Expect(err).ToNot(HaveOccurred(), operand.Name)
+			Expect(ready).To(Equal(total), operand.Name)
+		}
+	})
+
+	It("should report the validator image as its version", func() {
+		waitUntilDeployed()
+
+		deployment := &apps.Deployment{}
+		Expect(apiClient.Get(ctx, client.ObjectKey{
+			Namespace: strategy.GetNamespace(),
+			Name:      validator.DeploymentName,
+		}, deployment)).To(Succeed())
+
+		for _, operand := range getSsp().Status.Operands {
+			if operand.Name == "template-validator" {
+				Expect(operand.Version).To(Equal(deployment.Spec.Template.Spec.Containers[0].Image))
+				return
+			}
+		}
+		Fail("template-validator operand status not found")
+	})
+})
+
+var _ = Describe("Related objects", func() {
+	It("should list the validator deployment", func() {
+		waitUntilDeployed()
+
+		Expect(getSsp().Status.RelatedObjects).To(ContainElement(core.ObjectReference{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+			Namespace:  strategy.GetNamespace(),
+			Name:       validator.DeploymentName,
+		}))
+	})
+})
+
+var _ = Describe("Events", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+		waitUntilDeployed()
+	})
+
+	It("should record event when webhook configuration is updated", func() {
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			foundSsp.Spec.TemplateValidator.WebhookTimeoutSeconds = pointer.Int32Ptr(7)
+		})
+
+		Eventually(func() bool {
+			ssp := getSsp()
+			events := &core.EventList{}
+			Expect(apiClient.List(ctx, events, client.InNamespace(ssp.Namespace))).ToNot(HaveOccurred())
+			for _, event := range events.Items {
+				if event.InvolvedObject.UID == ssp.UID && event.Reason == controllers.WebhookConfigUpdatedReason {
+					return true
+				}
+			}
+			return false
+		}, shortTimeout, time.Second).Should(BeTrue())
+	})
+})
+
+var _ = Describe("Dry-run", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+		waitUntilDeployed()
+	})
+
+	It("should list changes in ConfigMap without modifying resources", func() {
+		waitUntilDeployed()
+
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			if foundSsp.Annotations == nil {
+				foundSsp.Annotations = map[string]string{}
+			}
+			foundSsp.Annotations[sspv1beta1.DryRunAnnotation] = "true"
+			foundSsp.Spec.TemplateValidator.WebhookTimeoutSeconds = pointer.Int32Ptr(9)
+		})
+
+		sspObj := getSsp()
+		configMap := &core.ConfigMap{}
+		Eventually(func() (string, error) {
+			err := apiClient.Get(ctx, client.ObjectKey{
+				Namespace: sspObj.Namespace,
+				Name:      controllers.DryRunConfigMapName(sspObj),
+			}, configMap)
+			return configMap.Data[controllers.DryRunGenerationKey], err
+		}, shortTimeout, time.Second).Should(Equal(fmt.Sprintf("%d", sspObj.Generation)))
+		Expect(configMap.Data[controllers.DryRunChangesKey]).To(ContainSubstring("update ValidatingWebhookConfiguration " + validator.WebhookName))
+
+		webhookConfig := &admission.ValidatingWebhookConfiguration{}
+		Expect(apiClient.Get(ctx, client.ObjectKey{Name: validator.WebhookName}, webhookConfig)).To(Succeed())
+		for _, webhook := range webhookConfig.Webhooks {
+			Expect(webhook.TimeoutSeconds).ToNot(Equal(pointer.Int32Ptr(9)))
+		}
+	})
+})
+
+var _ = Describe("Rollback", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+		waitUntilDeployed()
+	})
+
+	It("should restore the last deployed spec after a failed update", func() {
+		waitUntilDeployed()
+		deployedSpec := getSsp().Spec
+
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			foundSsp.Spec.CommonTemplates.Version = "v0.0.0-missing"
+		})
+		Eventually(func() lifecycleapi.Phase {
+			return getSsp().Status.Phase
+		}, shortTimeout, time.Second).Should(Equal(lifecycleapi.PhaseError))
+
+		updateSsp(func(foundSsp *sspv1beta1.SSP) {
+			if foundSsp.Annotations == nil {
+				foundSsp.Annotations = map[string]string{}
+			}
+			foundSsp.Annotations[sspv1beta1.RollbackAnnotation] = "true"
+		})
+		Eventually(func() bool {
+			foundSsp := getSsp()
+			_, annotated := foundSsp.Annotations[sspv1beta1.RollbackAnnotation]
+			return !annotated && foundSsp.Spec.CommonTemplates.Version == deployedSpec.CommonTemplates.Version
+		}, shortTimeout, time.Second).Should(BeTrue())
+		waitUntilDeployed()
+	})
+})
+
+var _ = Describe("Field manager migration", func() {
+	const staleLabel = "test.kubevirt.io/stale"
+
+	It("should remove fields set by the previous operator version, after moving them to the apply field manager", func() {
+		waitUntilDeployed()
+
+		key := client.ObjectKey{Name: validator.ClusterRoleName}
+		role := &rbac.ClusterRole{}
+		Expect(apiClient.Get(ctx, key, role)).ToNot(HaveOccurred())
+
+		var appliedFields map[string]interface{}
+		for _, entry := range role.ManagedFields {
+			if entry.Manager == common.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+				Expect(json.Unmarshal(entry.FieldsV1.Raw, &appliedFields)).To(Succeed())
+			}
+		}
+		Expect(appliedFields).ToNot(BeNil(), "the cluster role is not applied by the operator")
+
+		// Simulate a resource updated by the previous operator version,
+		// which also set a label that the current version does not set
+		metadataFields := appliedFields["f:metadata"].(map[string]interface{})
+		labelFields, _ := metadataFields["f:labels"].(map[string]interface{})
+		if labelFields == nil {
+			labelFields = map[string]interface{}{}
+			metadataFields["f:labels"] = labelFields
+		}
+		labelFields["f:"+staleLabel] = map[string]interface{}{}
+		legacyFields, err := json.Marshal(appliedFields)
+		Expect(err).ToNot(HaveOccurred())
+
+		now := metav1.Now()
+		role.Labels[staleLabel] = "true"
+		role.ManagedFields = []metav1.ManagedFieldsEntry{{
+			Manager:    common.LegacyFieldManager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: rbac.SchemeGroupVersion.String(),
+			Time:       &now,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: legacyFields},
+		}}
+		Expect(apiClient.Update(ctx, role, client.FieldOwner(common.LegacyFieldManager))).ToNot(HaveOccurred())
+
+		Eventually(func() bool {
+			updated := &rbac.ClusterRole{}
+			Expect(apiClient.Get(ctx, key, updated)).ToNot(HaveOccurred())
+			if _, ok := updated.Labels[staleLabel]; ok {
+				return false
+			}
+			applied := false
+			for _, entry := range updated.ManagedFields {
+				if entry.Manager == common.LegacyFieldManager {
+					return false
+				}
+				if entry.Manager == common.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+					applied = true
+				}
+			}
+			return applied
+		}, shortTimeout, time.Second).Should(BeTrue())
+	})
+})
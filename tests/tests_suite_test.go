@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	sspv1beta1 "kubevirt.io/ssp-operator/api/v1beta1"
+	sspv1beta2 "kubevirt.io/ssp-operator/api/v1beta2"
 	"kubevirt.io/ssp-operator/internal/common"
 )
 
@@ -308,6 +309,7 @@ func expectSuccessOrNotFound(err error) {
 
 func setupApiClient() {
 	Expect(sspv1beta1.AddToScheme(scheme.Scheme)).ToNot(HaveOccurred())
+	Expect(sspv1beta2.AddToScheme(scheme.Scheme)).ToNot(HaveOccurred())
 	Expect(promv1.AddToScheme(scheme.Scheme)).ToNot(HaveOccurred())
 	Expect(templatev1.Install(scheme.Scheme)).ToNot(HaveOccurred())
 	Expect(secv1.Install(scheme.Scheme)).ToNot(HaveOccurred())
@@ -2,14 +2,19 @@ package tests
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	lifecycleapi "kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/api"
+
 	ssp "kubevirt.io/ssp-operator/api/v1beta1"
+	sspv1beta2 "kubevirt.io/ssp-operator/api/v1beta2"
 )
 
 var _ = Describe("Validation webhook", func() {
@@ -89,5 +94,118 @@ var _ = Describe("Validation webhook", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("commonTemplates.namespace cannot be changed."))
 		})
+
+		It("should fail to update with invalid placement", func() {
+			foundSsp := getSsp()
+			foundSsp.Spec.TemplateValidator.Placement = &lifecycleapi.NodePlacement{
+				NodeSelector: map[string]string{"invalid key": "value"},
+			}
+			err := apiClient.Update(ctx, foundSsp)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.placement is invalid"))
+		})
+	})
+
+	Context("dry-run", func() {
+		It("should fail to create a second SSP CR", func() {
+			foundSsp := getSsp()
+			ssp2 := foundSsp.DeepCopy()
+			ssp2.ObjectMeta = v1.ObjectMeta{
+				Name:      "test-ssp2",
+				Namespace: foundSsp.GetNamespace(),
+			}
+
+			err := apiClient.Create(ctx, ssp2, client.DryRunAll)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("creation failed, an SSP CR already exists"))
+		})
+
+		It("should fail to update with invalid placement", func() {
+			foundSsp := getSsp()
+			foundSsp.Spec.TemplateValidator.Placement = &lifecycleapi.NodePlacement{
+				NodeSelector: map[string]string{"invalid key": "value"},
+			}
+			err := apiClient.Update(ctx, foundSsp, client.DryRunAll)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("templateValidator.placement is invalid"))
+		})
+
+		It("should not change SSP CR on valid update", func() {
+			foundSsp := getSsp()
+			updated := foundSsp.DeepCopy()
+			updated.Spec.OperatorLogVerbosity = pointer.Int32Ptr(5)
+			Expect(apiClient.Update(ctx, updated, client.DryRunAll)).To(Succeed())
+
+			Consistently(func() string {
+				return getSsp().ResourceVersion
+			}, 10*time.Second, time.Second).Should(Equal(foundSsp.ResourceVersion))
+		})
+	})
+})
+
+var _ = Describe("Defaulting webhook", func() {
+	BeforeEach(func() {
+		strategy.SkipSspUpdateTestsIfNeeded()
+		waitUntilDeployed()
+	})
+
+	AfterEach(func() {
+		strategy.RevertToOriginalSspCr()
+	})
+
+	It("should set defaults of unset fields", func() {
+		updateSsp(func(foundSsp *ssp.SSP) {
+			foundSsp.Spec.OperatorLogVerbosity = nil
+			foundSsp.Spec.TemplateValidator.LogVerbosity = nil
+			foundSsp.Spec.CommonTemplates.EnableCommonBootImageImport = nil
+		})
+
+		foundSsp := getSsp()
+		Expect(foundSsp.Spec.OperatorLogVerbosity).To(Equal(pointer.Int32Ptr(ssp.DefaultOperatorLogVerbosity)))
+		Expect(foundSsp.Spec.TemplateValidator.LogVerbosity).To(Equal(pointer.Int32Ptr(ssp.DefaultTemplateValidatorLogVerbosity)))
+		Expect(foundSsp.Spec.CommonTemplates.EnableCommonBootImageImport).To(Equal(pointer.BoolPtr(true)))
+	})
+})
+
+var _ = Describe("Conversion webhook", func() {
+	BeforeEach(func() {
+		waitUntilDeployed()
+	})
+
+	It("should read SSP CR as v1beta2", func() {
+		foundSsp := getSsp()
+		converted := &sspv1beta2.SSP{}
+		Expect(apiClient.Get(ctx, client.ObjectKey{Name: foundSsp.Name, Namespace: foundSsp.Namespace}, converted)).To(Succeed())
+		Expect(converted.Spec.Templates.Namespace).To(Equal(foundSsp.Spec.CommonTemplates.Namespace))
+		Expect(converted.Spec.TemplateValidator).To(Equal(foundSsp.Spec.TemplateValidator))
+		Expect(converted.Annotations).To(Equal(foundSsp.Annotations))
+	})
+
+	Context("update", func() {
+		BeforeEach(func() {
+			strategy.SkipSspUpdateTestsIfNeeded()
+		})
+
+		AfterEach(func() {
+			strategy.RevertToOriginalSspCr()
+		})
+
+		It("should update SSP CR using v1beta2", func() {
+			foundSsp := getSsp()
+			Eventually(func() error {
+				converted := &sspv1beta2.SSP{}
+				err := apiClient.Get(ctx, client.ObjectKey{Name: foundSsp.Name, Namespace: foundSsp.Namespace}, converted)
+				if err != nil {
+					return err
+				}
+				converted.Spec.OperatorLogVerbosity = pointer.Int32Ptr(3)
+				return apiClient.Update(ctx, converted)
+			}, timeout, time.Second).Should(Succeed())
+
+			updatedSsp := getSsp()
+			Expect(updatedSsp.Spec.OperatorLogVerbosity).To(Equal(pointer.Int32Ptr(3)))
+			Expect(updatedSsp.Spec.CommonTemplates).To(Equal(foundSsp.Spec.CommonTemplates))
+			Expect(updatedSsp.Annotations).To(Equal(foundSsp.Annotations))
+		})
 	})
 })